@@ -3,13 +3,18 @@ package dependencies
 import (
 	"cow_sso/api/handlers"
 	authHandler "cow_sso/api/handlers/auth"
+	groupHandler "cow_sso/api/handlers/group"
+	roleHandler "cow_sso/api/handlers/role"
 	userHandler "cow_sso/api/handlers/user"
 	"cow_sso/api/server"
 	"cow_sso/middleware"
 	"cow_sso/pkg/integration/keycloak"
+	"cow_sso/pkg/integration/offboarding"
 	"cow_sso/pkg/integration/restful"
 	"cow_sso/pkg/integration/team"
 	authService "cow_sso/pkg/service/auth"
+	groupService "cow_sso/pkg/service/group"
+	roleService "cow_sso/pkg/service/role"
 	userService "cow_sso/pkg/service/user"
 
 	"go.uber.org/dig"
@@ -20,18 +25,25 @@ type Dependencies struct{}
 func BuildDependencies() *dig.Container {
 	Container := dig.New()
 	_ = Container.Provide(middleware.NewMetricMiddleWare)
+	_ = Container.Provide(middleware.NewAuthMiddleWare)
+	_ = Container.Provide(middleware.NewRateLimitMiddleWare)
 	_ = Container.Provide(server.New)
 	_ = Container.Provide(server.NewRouter)
 	//handlers
 	_ = Container.Provide(handlers.NewHandlerPing)
 	_ = Container.Provide(userHandler.NewUserHandler)
 	_ = Container.Provide(authHandler.NewAuthHandler)
+	_ = Container.Provide(roleHandler.NewRoleHandler)
+	_ = Container.Provide(groupHandler.NewGroupHandler)
 	//services
 	_ = Container.Provide(userService.NewUserService)
 	_ = Container.Provide(authService.NewAuthService)
+	_ = Container.Provide(roleService.NewRoleService)
+	_ = Container.Provide(groupService.NewGroupService)
 	//repositories
 	_ = Container.Provide(keycloak.NewKeycloakClient)
 	_ = Container.Provide(team.NewTeamClient)
+	_ = Container.Provide(offboarding.NewOffboardingRepository)
 	//platform
 	_ = Container.Provide(restful.NewRestClient)
 	return Container
@@ -3,7 +3,10 @@ package auth
 import (
 	"cow_sso/api/handlers/auth/request"
 	"cow_sso/api/handlers/errors"
+	"cow_sso/api/validation"
+	"cow_sso/middleware"
 	"cow_sso/pkg/service/auth"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -13,7 +16,11 @@ import (
 type IAuthHandler interface {
 	Login(c *gin.Context)
 	Logout(c *gin.Context)
+	Refresh(c *gin.Context)
 	IsValidToken(c *gin.Context)
+	UserInfo(c *gin.Context)
+	ChangePassword(c *gin.Context)
+	ForgotPassword(c *gin.Context)
 }
 
 type authHandler struct {
@@ -68,26 +75,34 @@ func (a *authHandler) Logout(c *gin.Context) {
 	c.JSON(http.StatusOK, "logout")
 }
 
-func (a *authHandler) IsValidToken(c *gin.Context) {
+func (a *authHandler) Refresh(c *gin.Context) {
 	ctx := c.Request.Context()
-	auth := c.GetHeader("Authorization")
-	if auth == "" {
+	var refreshTokenRequest request.RefreshTokenRequest
+	if err := c.BindJSON(&refreshTokenRequest); err != nil {
 		c.JSON(http.StatusBadRequest, errors.ApiErrors{
 			Code:    http.StatusBadRequest,
-			Message: "token is required",
+			Message: "invalid format",
 		})
 		return
 	}
-	token := strings.Split(auth, " ")
-	if len(token) != 2 || token[0] != "Bearer" {
-		c.JSON(http.StatusBadRequest, errors.ApiErrors{
-			Code:    http.StatusBadRequest,
-			Message: "invalid token format",
-		})
+	token, err := a.authService.RefreshToken(ctx, refreshTokenRequest)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		c.JSON(apiErr.Code, apiErr)
 		return
 	}
 
-	isValid, err := a.authService.IsValidToken(ctx, token[1])
+	c.JSON(http.StatusOK, token)
+}
+
+func (a *authHandler) IsValidToken(c *gin.Context) {
+	ctx := c.Request.Context()
+	token, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	tokenResponse, err := a.authService.IntrospectToken(ctx, token)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errors.ApiErrors{
 			Code:    http.StatusInternalServerError,
@@ -96,9 +111,102 @@ func (a *authHandler) IsValidToken(c *gin.Context) {
 		return
 	}
 
-	if isValid {
-		c.JSON(http.StatusOK, true)
+	if tokenResponse.Active {
+		c.JSON(http.StatusOK, tokenResponse)
 	} else {
-		c.JSON(http.StatusUnauthorized, false)
+		c.JSON(http.StatusUnauthorized, tokenResponse)
+	}
+}
+
+func (a *authHandler) UserInfo(c *gin.Context) {
+	ctx := c.Request.Context()
+	token, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	userInfo, err := a.authService.GetUserInfo(ctx, token)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, userInfo)
+}
+
+// ChangePassword changes the password of the caller, who must be authenticated.
+func (a *authHandler) ChangePassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errors.ApiErrors{
+			Code:    http.StatusUnauthorized,
+			Message: "token is required",
+		})
+		return
+	}
+	var changeRequest request.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&changeRequest); err != nil {
+		if fields, ok := validation.Fields(err); ok {
+			c.JSON(http.StatusUnprocessableEntity, errors.Invalid(fields))
+			return
+		}
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "invalid format",
+		})
+		return
+	}
+
+	if err := a.authService.ChangePassword(ctx, principal.Subject, principal.Username, changeRequest); err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error changing password, err: %s", err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, "password changed")
+}
+
+// ForgotPassword answers 202 whether or not the email belongs to anyone, failures included,
+// so it can't be used to find out who has an account. Errors are left in the request errors.
+func (a *authHandler) ForgotPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	var forgotRequest request.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&forgotRequest); err != nil {
+		if fields, ok := validation.Fields(err); ok {
+			c.JSON(http.StatusUnprocessableEntity, errors.Invalid(fields))
+			return
+		}
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "invalid format",
+		})
+		return
+	}
+
+	if err := a.authService.ForgotPassword(ctx, forgotRequest); err != nil {
+		_ = c.Error(err)
+	}
+	c.JSON(http.StatusAccepted, "if the email belongs to an account, a link to reset its password was sent")
+}
+
+// bearerToken reads the token from the Authorization header, answering the request itself when it's missing.
+func bearerToken(c *gin.Context) (string, bool) {
+	auth := c.GetHeader("Authorization")
+	if auth == "" {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "token is required",
+		})
+		return "", false
+	}
+	token := strings.Split(auth, " ")
+	if len(token) != 2 || token[0] != "Bearer" {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "invalid token format",
+		})
+		return "", false
 	}
+	return token[1], true
 }
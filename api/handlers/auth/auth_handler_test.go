@@ -11,7 +11,10 @@ import (
 
 	"cow_sso/api/handlers/auth/request"
 	"cow_sso/api/handlers/auth/response"
+	"cow_sso/api/validation"
+	"cow_sso/middleware"
 	"cow_sso/mocks"
+	"cow_sso/pkg/apperror"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -165,6 +168,90 @@ func Test_Logout(t *testing.T) {
 	}
 }
 
+func Test_Refresh(t *testing.T) {
+	tests := []struct {
+		mocks               authMocks
+		name                string
+		refreshTokenRequest any
+		expCode             int
+	}{
+		{
+			name:                "error in bind json",
+			refreshTokenRequest: "invalid format",
+			mocks: authMocks{
+				authHandler: func(f *mockAuthHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name: "refresh token expired",
+			refreshTokenRequest: request.RefreshTokenRequest{
+				RefreshToken: "refresh_token",
+			},
+			mocks: authMocks{
+				authHandler: func(f *mockAuthHandler) {
+					f.authService.On("RefreshToken", mock.Anything, request.RefreshTokenRequest{
+						RefreshToken: "refresh_token",
+					}).Return(response.AuthResponse{}, apperror.Unauthorized("refresh token is expired or revoked"))
+				},
+			},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			name: "error in refresh",
+			refreshTokenRequest: request.RefreshTokenRequest{
+				RefreshToken: "refresh_token",
+			},
+			mocks: authMocks{
+				authHandler: func(f *mockAuthHandler) {
+					f.authService.On("RefreshToken", mock.Anything, request.RefreshTokenRequest{
+						RefreshToken: "refresh_token",
+					}).Return(response.AuthResponse{}, errors.New("some error"))
+				},
+			},
+			expCode: http.StatusInternalServerError,
+		},
+		{
+			name: "success",
+			refreshTokenRequest: request.RefreshTokenRequest{
+				RefreshToken: "refresh_token",
+			},
+			mocks: authMocks{
+				authHandler: func(f *mockAuthHandler) {
+					f.authService.On("RefreshToken", mock.Anything, request.RefreshTokenRequest{
+						RefreshToken: "refresh_token",
+					}).Return(response.AuthResponse{
+						Token:            "token",
+						ExpiresIn:        3600,
+						RefreshToken:     "new_refresh_token",
+						RefreshExpiresIn: 3600,
+					}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockAuthHandler{
+				&mocks.IAuthService{},
+			}
+			tc.mocks.authHandler(ms)
+			handler := NewAuthHandler(ms.authService)
+			url := "/auth/refresh"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.POST(url, func(ctx *gin.Context) {
+				handler.Refresh(ctx)
+			})
+			res := httptest.NewRecorder()
+			b, _ := json.Marshal(tc.refreshTokenRequest)
+			req := httptest.NewRequest(http.MethodPost, url, io.NopCloser(bytes.NewBuffer(b)))
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
+
 func Test_IsValidToken(t *testing.T) {
 	tests := []struct {
 		mocks       authMocks
@@ -192,7 +279,7 @@ func Test_IsValidToken(t *testing.T) {
 			accessToken: "Bearer token",
 			mocks: authMocks{
 				authHandler: func(f *mockAuthHandler) {
-					f.authService.On("IsValidToken", mock.Anything, "token").Return(false, errors.New("some error"))
+					f.authService.On("IntrospectToken", mock.Anything, "token").Return(response.TokenResponse{}, errors.New("some error"))
 				},
 			},
 			expCode: http.StatusInternalServerError,
@@ -202,7 +289,7 @@ func Test_IsValidToken(t *testing.T) {
 			accessToken: "Bearer token",
 			mocks: authMocks{
 				authHandler: func(f *mockAuthHandler) {
-					f.authService.On("IsValidToken", mock.Anything, "token").Return(false, nil)
+					f.authService.On("IntrospectToken", mock.Anything, "token").Return(response.TokenResponse{}, nil)
 				},
 			},
 			expCode: http.StatusUnauthorized,
@@ -212,7 +299,11 @@ func Test_IsValidToken(t *testing.T) {
 			accessToken: "Bearer token",
 			mocks: authMocks{
 				authHandler: func(f *mockAuthHandler) {
-					f.authService.On("IsValidToken", mock.Anything, "token").Return(true, nil)
+					f.authService.On("IntrospectToken", mock.Anything, "token").Return(response.TokenResponse{
+						Active:   true,
+						Sub:      "abcde8",
+						Username: "diegof",
+					}, nil)
 				},
 			},
 			expCode: http.StatusOK,
@@ -240,3 +331,229 @@ func Test_IsValidToken(t *testing.T) {
 		})
 	}
 }
+
+func Test_UserInfo(t *testing.T) {
+	tests := []struct {
+		mocks       authMocks
+		name        string
+		accessToken string
+		expCode     int
+	}{
+		{
+			name: "token is required",
+			mocks: authMocks{
+				authHandler: func(f *mockAuthHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:        "error token format",
+			accessToken: "Basic token",
+			mocks: authMocks{
+				authHandler: func(f *mockAuthHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:        "invalid token",
+			accessToken: "Bearer token",
+			mocks: authMocks{
+				authHandler: func(f *mockAuthHandler) {
+					f.authService.On("GetUserInfo", mock.Anything, "token").Return(response.UserInfoResponse{}, apperror.Unauthorized("invalid token"))
+				},
+			},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			name:        "error getting user info",
+			accessToken: "Bearer token",
+			mocks: authMocks{
+				authHandler: func(f *mockAuthHandler) {
+					f.authService.On("GetUserInfo", mock.Anything, "token").Return(response.UserInfoResponse{}, errors.New("some error"))
+				},
+			},
+			expCode: http.StatusInternalServerError,
+		},
+		{
+			name:        "success",
+			accessToken: "Bearer token",
+			mocks: authMocks{
+				authHandler: func(f *mockAuthHandler) {
+					f.authService.On("GetUserInfo", mock.Anything, "token").Return(response.UserInfoResponse{
+						RealmRoles: []string{"user"},
+					}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockAuthHandler{
+				&mocks.IAuthService{},
+			}
+			tc.mocks.authHandler(ms)
+			handler := NewAuthHandler(ms.authService)
+			url := "/auth/userinfo"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.GET(url, func(ctx *gin.Context) {
+				handler.UserInfo(ctx)
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			if tc.accessToken != "" {
+				req.Header.Set("Authorization", tc.accessToken)
+			}
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
+
+func Test_ChangePassword(t *testing.T) {
+	validation.Register()
+	changeRequest := request.ChangePasswordRequest{
+		CurrentPassword: "old-password",
+		NewPassword:     "new-password",
+	}
+	tests := []struct {
+		input         any
+		mocks         authMocks
+		name          string
+		authenticated bool
+		expCode       int
+	}{
+		{
+			name:  "not authenticated",
+			input: changeRequest,
+			mocks: authMocks{
+				authHandler: func(f *mockAuthHandler) {},
+			},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "error in bind json",
+			input:         "invalid format",
+			authenticated: true,
+			mocks: authMocks{
+				authHandler: func(f *mockAuthHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:          "new password too short",
+			input:         request.ChangePasswordRequest{CurrentPassword: "old-password", NewPassword: "short"},
+			authenticated: true,
+			mocks: authMocks{
+				authHandler: func(f *mockAuthHandler) {},
+			},
+			expCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:          "wrong current password",
+			input:         changeRequest,
+			authenticated: true,
+			mocks: authMocks{
+				authHandler: func(f *mockAuthHandler) {
+					f.authService.Mock.On("ChangePassword", mock.Anything, "1", "diegof", changeRequest).
+						Return(apperror.New(http.StatusForbidden, "current password is incorrect"))
+				},
+			},
+			expCode: http.StatusForbidden,
+		},
+		{
+			name:          "full flow",
+			input:         changeRequest,
+			authenticated: true,
+			mocks: authMocks{
+				authHandler: func(f *mockAuthHandler) {
+					f.authService.Mock.On("ChangePassword", mock.Anything, "1", "diegof", changeRequest).Return(nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockAuthHandler{
+				&mocks.IAuthService{},
+			}
+			tc.mocks.authHandler(ms)
+			handler := NewAuthHandler(ms.authService)
+			url := "/auth/password/change"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.POST(url, func(ctx *gin.Context) {
+				if tc.authenticated {
+					middleware.SetPrincipal(ctx, middleware.Principal{Subject: "1", Username: "diegof"})
+				}
+				handler.ChangePassword(ctx)
+			})
+			res := httptest.NewRecorder()
+			b, _ := json.Marshal(tc.input)
+			req := httptest.NewRequest(http.MethodPost, url, io.NopCloser(bytes.NewBuffer(b)))
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+			ms.authService.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_ForgotPassword(t *testing.T) {
+	validation.Register()
+	forgotRequest := request.ForgotPasswordRequest{Email: "diego@gmail.com"}
+	tests := []struct {
+		input   any
+		mocks   authMocks
+		name    string
+		expCode int
+	}{
+		{
+			name:  "invalid email",
+			input: request.ForgotPasswordRequest{Email: "diego"},
+			mocks: authMocks{
+				authHandler: func(f *mockAuthHandler) {},
+			},
+			expCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:  "error sending email is hidden",
+			input: forgotRequest,
+			mocks: authMocks{
+				authHandler: func(f *mockAuthHandler) {
+					f.authService.Mock.On("ForgotPassword", mock.Anything, forgotRequest).Return(errors.New("some error"))
+				},
+			},
+			expCode: http.StatusAccepted,
+		},
+		{
+			name:  "full flow",
+			input: forgotRequest,
+			mocks: authMocks{
+				authHandler: func(f *mockAuthHandler) {
+					f.authService.Mock.On("ForgotPassword", mock.Anything, forgotRequest).Return(nil)
+				},
+			},
+			expCode: http.StatusAccepted,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockAuthHandler{
+				&mocks.IAuthService{},
+			}
+			tc.mocks.authHandler(ms)
+			handler := NewAuthHandler(ms.authService)
+			url := "/auth/password/forgot"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.POST(url, func(ctx *gin.Context) {
+				handler.ForgotPassword(ctx)
+			})
+			res := httptest.NewRecorder()
+			b, _ := json.Marshal(tc.input)
+			req := httptest.NewRequest(http.MethodPost, url, io.NopCloser(bytes.NewBuffer(b)))
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+			ms.authService.AssertExpectations(t)
+		})
+	}
+}
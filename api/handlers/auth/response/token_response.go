@@ -0,0 +1,13 @@
+package response
+
+type TokenResponse struct {
+	ClientRoles map[string][]string `json:"client_roles,omitempty"`
+	Sub         string              `json:"sub,omitempty"`
+	Username    string              `json:"username,omitempty"`
+	Email       string              `json:"email,omitempty"`
+	Scope       string              `json:"scope,omitempty"`
+	RealmRoles  []string            `json:"realm_roles,omitempty"`
+	Exp         int64               `json:"exp,omitempty"`
+	Iat         int64               `json:"iat,omitempty"`
+	Active      bool                `json:"active"`
+}
@@ -0,0 +1,9 @@
+package response
+
+import userResponse "cow_sso/api/handlers/user/response"
+
+type UserInfoResponse struct {
+	ClientRoles map[string][]string `json:"client_roles,omitempty"`
+	userResponse.UserResponse
+	RealmRoles []string `json:"realm_roles,omitempty"`
+}
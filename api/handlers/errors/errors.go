@@ -1,6 +1,49 @@
 package errors
 
+import (
+	"errors"
+	"net/http"
+
+	"cow_sso/pkg/apperror"
+)
+
 type ApiErrors struct {
-	Message string `json:"message"`
-	Code    int    `json:"code"`
+	Details any          `json:"details,omitempty"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+	Code    int          `json:"code"`
+}
+
+// FieldError names a request field and the validation rule it broke, with the rule's parameter when it has one.
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+}
+
+// FromError builds the api error for err, falling back to code when err doesn't carry a status.
+// Conflicts on a field list the field that collided, any other details are passed on as they are.
+func FromError(err error, code int) ApiErrors {
+	apiErr := ApiErrors{
+		Code:    apperror.Code(err, code),
+		Message: err.Error(),
+	}
+	var appErr *apperror.AppError
+	if errors.As(err, &appErr) && appErr.Details != nil {
+		if field, ok := appErr.Details.(string); ok && appErr.Code == http.StatusConflict {
+			apiErr.Fields = []FieldError{{Field: field, Rule: "unique"}}
+		} else {
+			apiErr.Details = appErr.Details
+		}
+	}
+	return apiErr
+}
+
+// Invalid builds the 422 answered when the request body breaks its validation rules.
+func Invalid(fields []FieldError) ApiErrors {
+	return ApiErrors{
+		Code:    http.StatusUnprocessableEntity,
+		Message: "invalid fields",
+		Fields:  fields,
+	}
 }
@@ -0,0 +1,258 @@
+package group
+
+import (
+	"fmt"
+	"net/http"
+
+	"cow_sso/api/handlers/errors"
+	"cow_sso/api/handlers/group/request"
+	"cow_sso/pkg/service/group"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IGroupHandler interface {
+	GetAll(c *gin.Context)
+	GetByID(c *gin.Context)
+	Create(c *gin.Context)
+	CreateChild(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+	GetMembers(c *gin.Context)
+	GetByUser(c *gin.Context)
+	AddMember(c *gin.Context)
+	RemoveMember(c *gin.Context)
+}
+
+type groupHandler struct {
+	groupService group.IGroupService
+}
+
+func NewGroupHandler(groupService group.IGroupService) IGroupHandler {
+	return &groupHandler{
+		groupService: groupService,
+	}
+}
+
+func (gh *groupHandler) GetAll(c *gin.Context) {
+	ctx := c.Request.Context()
+	groups, err := gh.groupService.GetAll(ctx, c.Query("search"))
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error getting groups, err: %s", err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, groups)
+}
+
+func (gh *groupHandler) GetByID(c *gin.Context) {
+	ctx := c.Request.Context()
+	groupID, exists := c.Params.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "group's id is required",
+		})
+		return
+	}
+
+	group, err := gh.groupService.GetByID(ctx, groupID)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error getting group %s, err: %s", groupID, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, group)
+}
+
+func (gh *groupHandler) Create(c *gin.Context) {
+	gh.create(c, "")
+}
+
+func (gh *groupHandler) CreateChild(c *gin.Context) {
+	parentID, exists := c.Params.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "parent group's id is required",
+		})
+		return
+	}
+	gh.create(c, parentID)
+}
+
+func (gh *groupHandler) create(c *gin.Context, parentID string) {
+	ctx := c.Request.Context()
+	var groupRequest request.GroupRequest
+	if err := c.BindJSON(&groupRequest); err != nil {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "invalid format",
+		})
+		return
+	}
+
+	group, err := gh.groupService.Create(ctx, parentID, groupRequest)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error creating group %s, err: %s", groupRequest.Name, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, group)
+}
+
+func (gh *groupHandler) Update(c *gin.Context) {
+	ctx := c.Request.Context()
+	groupID, exists := c.Params.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "group's id is required",
+		})
+		return
+	}
+
+	var groupRequest request.GroupRequest
+	if err := c.BindJSON(&groupRequest); err != nil {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "invalid format",
+		})
+		return
+	}
+
+	group, err := gh.groupService.Update(ctx, groupID, groupRequest)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error updating group %s, err: %s", groupID, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, group)
+}
+
+func (gh *groupHandler) Delete(c *gin.Context) {
+	ctx := c.Request.Context()
+	groupID, exists := c.Params.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "group's id is required",
+		})
+		return
+	}
+
+	name, err := gh.groupService.Delete(ctx, groupID)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error deleting group %s, err: %s", groupID, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, fmt.Sprintf("group %s deleted", name))
+}
+
+func (gh *groupHandler) GetMembers(c *gin.Context) {
+	ctx := c.Request.Context()
+	groupID, exists := c.Params.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "group's id is required",
+		})
+		return
+	}
+
+	var membersQuery request.MembersQuery
+	if err := c.ShouldBindQuery(&membersQuery); err != nil {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "invalid query params",
+		})
+		return
+	}
+
+	members, err := gh.groupService.GetMembers(ctx, groupID, membersQuery)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error getting members of group %s, err: %s", groupID, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+func (gh *groupHandler) GetByUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	nickName, exists := c.Params.Get("code")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "user's nick name is required",
+		})
+		return
+	}
+
+	groups, err := gh.groupService.GetByUser(ctx, nickName)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error getting groups of user %s, err: %s", nickName, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, groups)
+}
+
+func (gh *groupHandler) AddMember(c *gin.Context) {
+	groupID, nickName, ok := memberParams(c)
+	if !ok {
+		return
+	}
+
+	if err := gh.groupService.AddMember(c.Request.Context(), groupID, nickName); err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error adding user %s to group %s, err: %s", nickName, groupID, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, fmt.Sprintf("user %s added to group %s", nickName, groupID))
+}
+
+func (gh *groupHandler) RemoveMember(c *gin.Context) {
+	groupID, nickName, ok := memberParams(c)
+	if !ok {
+		return
+	}
+
+	if err := gh.groupService.RemoveMember(c.Request.Context(), groupID, nickName); err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error removing user %s from group %s, err: %s", nickName, groupID, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, fmt.Sprintf("user %s removed from group %s", nickName, groupID))
+}
+
+// memberParams reads the group id and the member's nick name, answering 400 when one is missing.
+func memberParams(c *gin.Context) (string, string, bool) {
+	groupID, exists := c.Params.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "group's id is required",
+		})
+		return "", "", false
+	}
+	nickName, exists := c.Params.Get("code")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "user's nick name is required",
+		})
+		return "", "", false
+	}
+	return groupID, nickName, true
+}
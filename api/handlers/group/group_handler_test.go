@@ -0,0 +1,578 @@
+package group
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cow_sso/api/handlers/group/request"
+	"cow_sso/api/handlers/group/response"
+	userResponse "cow_sso/api/handlers/user/response"
+	"cow_sso/mocks"
+	"cow_sso/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockGroupHandler struct {
+	groupService *mocks.IGroupService
+}
+
+type groupMocks struct {
+	groupHandler func(f *mockGroupHandler)
+}
+
+func Test_GetAll(t *testing.T) {
+	tests := []struct {
+		mocks   groupMocks
+		name    string
+		query   string
+		expCode int
+	}{
+		{
+			name: "error getting groups",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("GetAll", mock.Anything, "").Return(nil, errors.New("some error"))
+				},
+			},
+			expCode: http.StatusInternalServerError,
+		},
+		{
+			name:  "full flow",
+			query: "?search=sal",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("GetAll", mock.Anything, "sal").Return([]response.GroupResponse{{ID: "g1", Name: "sales"}}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockGroupHandler{
+				&mocks.IGroupService{},
+			}
+			tc.mocks.groupHandler(ms)
+			handler := NewGroupHandler(ms.groupService)
+			url := "/groups"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.GET(url, func(ctx *gin.Context) {
+				handler.GetAll(ctx)
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, url+tc.query, nil)
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
+
+func Test_GetByID(t *testing.T) {
+	tests := []struct {
+		mocks   groupMocks
+		name    string
+		groupID string
+		expCode int
+	}{
+		{
+			name: "group id isnt present",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:    "group not found",
+			groupID: "g1",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("GetByID", mock.Anything, "g1").Return(response.GroupResponse{}, apperror.New(http.StatusNotFound, "group g1 doesn't exist"))
+				},
+			},
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:    "full flow",
+			groupID: "g1",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("GetByID", mock.Anything, "g1").Return(response.GroupResponse{ID: "g1", Name: "sales"}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockGroupHandler{
+				&mocks.IGroupService{},
+			}
+			tc.mocks.groupHandler(ms)
+			handler := NewGroupHandler(ms.groupService)
+			url := "/groups"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.GET(url, func(ctx *gin.Context) {
+				if tc.groupID != "" {
+					ctx.AddParam("id", tc.groupID)
+				}
+				handler.GetByID(ctx)
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
+
+func Test_Create(t *testing.T) {
+	tests := []struct {
+		input    interface{}
+		mocks    groupMocks
+		name     string
+		parentID string
+		child    bool
+		expCode  int
+	}{
+		{
+			name:  "error on input",
+			input: "ABC",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:  "group already exists",
+			input: request.GroupRequest{Name: "sales"},
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("Create", mock.Anything, "", request.GroupRequest{Name: "sales"}).Return(response.GroupResponse{}, apperror.New(http.StatusConflict, "group sales already exists"))
+				},
+			},
+			expCode: http.StatusConflict,
+		},
+		{
+			name:  "full flow",
+			input: request.GroupRequest{Name: "sales"},
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("Create", mock.Anything, "", request.GroupRequest{Name: "sales"}).Return(response.GroupResponse{ID: "g1", Name: "sales"}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:  "parent id isnt present",
+			child: true,
+			input: request.GroupRequest{Name: "north"},
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:     "subgroup",
+			child:    true,
+			parentID: "g1",
+			input:    request.GroupRequest{Name: "north"},
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("Create", mock.Anything, "g1", request.GroupRequest{Name: "north"}).Return(response.GroupResponse{ID: "g2", Name: "north"}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockGroupHandler{
+				&mocks.IGroupService{},
+			}
+			tc.mocks.groupHandler(ms)
+			handler := NewGroupHandler(ms.groupService)
+			url := "/groups"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.POST(url, func(ctx *gin.Context) {
+				if tc.parentID != "" {
+					ctx.AddParam("id", tc.parentID)
+				}
+				if tc.child {
+					handler.CreateChild(ctx)
+				} else {
+					handler.Create(ctx)
+				}
+			})
+			res := httptest.NewRecorder()
+			b, _ := json.Marshal(tc.input)
+			req := httptest.NewRequest(http.MethodPost, url, io.NopCloser(bytes.NewBuffer(b)))
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
+
+func Test_Update(t *testing.T) {
+	tests := []struct {
+		input   interface{}
+		mocks   groupMocks
+		name    string
+		groupID string
+		expCode int
+	}{
+		{
+			name: "group id isnt present",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:    "error on input",
+			groupID: "g1",
+			input:   "ABC",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:    "error updating group",
+			groupID: "g1",
+			input:   request.GroupRequest{Name: "marketing"},
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("Update", mock.Anything, "g1", request.GroupRequest{Name: "marketing"}).Return(response.GroupResponse{}, errors.New("some error"))
+				},
+			},
+			expCode: http.StatusInternalServerError,
+		},
+		{
+			name:    "full flow",
+			groupID: "g1",
+			input:   request.GroupRequest{Name: "marketing"},
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("Update", mock.Anything, "g1", request.GroupRequest{Name: "marketing"}).Return(response.GroupResponse{ID: "g1", Name: "marketing"}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockGroupHandler{
+				&mocks.IGroupService{},
+			}
+			tc.mocks.groupHandler(ms)
+			handler := NewGroupHandler(ms.groupService)
+			url := "/groups"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.PUT(url, func(ctx *gin.Context) {
+				if tc.groupID != "" {
+					ctx.AddParam("id", tc.groupID)
+				}
+				handler.Update(ctx)
+			})
+			res := httptest.NewRecorder()
+			b, _ := json.Marshal(tc.input)
+			req := httptest.NewRequest(http.MethodPut, url, io.NopCloser(bytes.NewBuffer(b)))
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
+
+func Test_Delete(t *testing.T) {
+	tests := []struct {
+		mocks   groupMocks
+		name    string
+		groupID string
+		expCode int
+	}{
+		{
+			name: "group id isnt present",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:    "group not found",
+			groupID: "g1",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("Delete", mock.Anything, "g1").Return("", apperror.New(http.StatusNotFound, "group g1 doesn't exist"))
+				},
+			},
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:    "full flow",
+			groupID: "g1",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("Delete", mock.Anything, "g1").Return("sales", nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockGroupHandler{
+				&mocks.IGroupService{},
+			}
+			tc.mocks.groupHandler(ms)
+			handler := NewGroupHandler(ms.groupService)
+			url := "/groups"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.DELETE(url, func(ctx *gin.Context) {
+				if tc.groupID != "" {
+					ctx.AddParam("id", tc.groupID)
+				}
+				handler.Delete(ctx)
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodDelete, url, nil)
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
+
+func Test_GetMembers(t *testing.T) {
+	tests := []struct {
+		mocks   groupMocks
+		name    string
+		groupID string
+		query   string
+		expCode int
+	}{
+		{
+			name: "group id isnt present",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:    "invalid query params",
+			groupID: "g1",
+			query:   "?max=abc",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:    "error getting members",
+			groupID: "g1",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("GetMembers", mock.Anything, "g1", request.MembersQuery{}).Return(nil, errors.New("some error"))
+				},
+			},
+			expCode: http.StatusInternalServerError,
+		},
+		{
+			name:    "full flow",
+			groupID: "g1",
+			query:   "?first=10&max=10",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("GetMembers", mock.Anything, "g1", request.MembersQuery{First: 10, Max: 10}).Return([]userResponse.UserResponse{{NickName: "diegof"}}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockGroupHandler{
+				&mocks.IGroupService{},
+			}
+			tc.mocks.groupHandler(ms)
+			handler := NewGroupHandler(ms.groupService)
+			url := "/groups/members"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.GET(url, func(ctx *gin.Context) {
+				if tc.groupID != "" {
+					ctx.AddParam("id", tc.groupID)
+				}
+				handler.GetMembers(ctx)
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, url+tc.query, nil)
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
+
+func Test_GetByUser(t *testing.T) {
+	tests := []struct {
+		mocks    groupMocks
+		name     string
+		nickName string
+		expCode  int
+	}{
+		{
+			name: "nick name isnt present",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:     "error getting groups",
+			nickName: "diegof",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("GetByUser", mock.Anything, "diegof").Return(nil, errors.New("some error"))
+				},
+			},
+			expCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "full flow",
+			nickName: "diegof",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("GetByUser", mock.Anything, "diegof").Return([]response.GroupResponse{{ID: "g1", Name: "sales"}}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockGroupHandler{
+				&mocks.IGroupService{},
+			}
+			tc.mocks.groupHandler(ms)
+			handler := NewGroupHandler(ms.groupService)
+			url := "/users/groups"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.GET(url, func(ctx *gin.Context) {
+				if tc.nickName != "" {
+					ctx.AddParam("code", tc.nickName)
+				}
+				handler.GetByUser(ctx)
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
+
+func Test_Membership(t *testing.T) {
+	tests := []struct {
+		mocks    groupMocks
+		name     string
+		groupID  string
+		nickName string
+		expCode  int
+		remove   bool
+	}{
+		{
+			name: "group id isnt present",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:    "nick name isnt present",
+			groupID: "g1",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:     "error adding member",
+			groupID:  "g1",
+			nickName: "diegof",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("AddMember", mock.Anything, "g1", "diegof").Return(apperror.New(http.StatusNotFound, "group g1 doesn't exist"))
+				},
+			},
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:     "add member",
+			groupID:  "g1",
+			nickName: "diegof",
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("AddMember", mock.Anything, "g1", "diegof").Return(nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:     "error removing member",
+			groupID:  "g1",
+			nickName: "diegof",
+			remove:   true,
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("RemoveMember", mock.Anything, "g1", "diegof").Return(errors.New("some error"))
+				},
+			},
+			expCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "remove member",
+			groupID:  "g1",
+			nickName: "diegof",
+			remove:   true,
+			mocks: groupMocks{
+				groupHandler: func(f *mockGroupHandler) {
+					f.groupService.Mock.On("RemoveMember", mock.Anything, "g1", "diegof").Return(nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockGroupHandler{
+				&mocks.IGroupService{},
+			}
+			tc.mocks.groupHandler(ms)
+			handler := NewGroupHandler(ms.groupService)
+			url := "/groups/members"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.Any(url, func(ctx *gin.Context) {
+				if tc.groupID != "" {
+					ctx.AddParam("id", tc.groupID)
+				}
+				if tc.nickName != "" {
+					ctx.AddParam("code", tc.nickName)
+				}
+				if tc.remove {
+					handler.RemoveMember(ctx)
+				} else {
+					handler.AddMember(ctx)
+				}
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, url, nil)
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
@@ -0,0 +1,6 @@
+package request
+
+type GroupRequest struct {
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Name       string            `json:"name"`
+}
@@ -0,0 +1,6 @@
+package request
+
+type MembersQuery struct {
+	First int `form:"first"`
+	Max   int `form:"max"`
+}
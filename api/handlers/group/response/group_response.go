@@ -0,0 +1,9 @@
+package response
+
+type GroupResponse struct {
+	Attributes map[string]string `json:"attributes,omitempty"`
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Path       string            `json:"path"`
+	SubGroups  []GroupResponse   `json:"sub_groups,omitempty"`
+}
@@ -0,0 +1,5 @@
+package request
+
+type RoleRequest struct {
+	Roles []string `json:"roles"`
+}
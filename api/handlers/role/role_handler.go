@@ -0,0 +1,121 @@
+package role
+
+import (
+	"fmt"
+	"net/http"
+
+	"cow_sso/api/handlers/errors"
+	"cow_sso/api/handlers/role/request"
+	"cow_sso/pkg/service/role"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IRoleHandler interface {
+	GetAll(c *gin.Context)
+	GetByUser(c *gin.Context)
+	Grant(c *gin.Context)
+	Revoke(c *gin.Context)
+}
+
+type roleHandler struct {
+	roleService role.IRoleService
+}
+
+func NewRoleHandler(roleService role.IRoleService) IRoleHandler {
+	return &roleHandler{
+		roleService: roleService,
+	}
+}
+
+func (rh *roleHandler) GetAll(c *gin.Context) {
+	ctx := c.Request.Context()
+	roles, err := rh.roleService.GetAll(ctx)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error getting roles, err: %s", err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+func (rh *roleHandler) GetByUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	nickName, exists := c.Params.Get("code")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "user's nick name is required",
+		})
+		return
+	}
+
+	roles, err := rh.roleService.GetByUser(ctx, nickName)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error getting roles of user %s, err: %s", nickName, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+func (rh *roleHandler) Grant(c *gin.Context) {
+	ctx := c.Request.Context()
+	nickName, exists := c.Params.Get("code")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "user's nick name is required",
+		})
+		return
+	}
+
+	var roleRequest request.RoleRequest
+	if err := c.BindJSON(&roleRequest); err != nil {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "invalid format",
+		})
+		return
+	}
+
+	roles, err := rh.roleService.Grant(ctx, nickName, roleRequest)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error granting roles to user %s, err: %s", nickName, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+func (rh *roleHandler) Revoke(c *gin.Context) {
+	ctx := c.Request.Context()
+	nickName, exists := c.Params.Get("code")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "user's nick name is required",
+		})
+		return
+	}
+	roleName, exists := c.Params.Get("role")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "role name is required",
+		})
+		return
+	}
+
+	roles, err := rh.roleService.Revoke(ctx, nickName, roleName)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error revoking role %s from user %s, err: %s", roleName, nickName, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
@@ -0,0 +1,274 @@
+package role
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cow_sso/api/handlers/role/request"
+	"cow_sso/api/handlers/role/response"
+	"cow_sso/mocks"
+	"cow_sso/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockRoleHandler struct {
+	roleService *mocks.IRoleService
+}
+
+type roleMocks struct {
+	roleHandler func(f *mockRoleHandler)
+}
+
+func Test_GetAll(t *testing.T) {
+	tests := []struct {
+		mocks   roleMocks
+		name    string
+		expCode int
+	}{
+		{
+			name: "error getting roles",
+			mocks: roleMocks{
+				roleHandler: func(f *mockRoleHandler) {
+					f.roleService.Mock.On("GetAll", mock.Anything).Return(nil, errors.New("some error"))
+				},
+			},
+			expCode: http.StatusInternalServerError,
+		},
+		{
+			name: "full flow",
+			mocks: roleMocks{
+				roleHandler: func(f *mockRoleHandler) {
+					f.roleService.Mock.On("GetAll", mock.Anything).Return([]response.RoleResponse{{ID: "1", Name: "admin"}}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockRoleHandler{
+				&mocks.IRoleService{},
+			}
+			tc.mocks.roleHandler(ms)
+			handler := NewRoleHandler(ms.roleService)
+			url := "/roles"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.GET(url, func(ctx *gin.Context) {
+				handler.GetAll(ctx)
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
+
+func Test_GetByUser(t *testing.T) {
+	tests := []struct {
+		mocks    roleMocks
+		name     string
+		nickName string
+		expCode  int
+	}{
+		{
+			name: "nick name isnt present",
+			mocks: roleMocks{
+				roleHandler: func(f *mockRoleHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:     "error getting roles",
+			nickName: "diegof",
+			mocks: roleMocks{
+				roleHandler: func(f *mockRoleHandler) {
+					f.roleService.Mock.On("GetByUser", mock.Anything, "diegof").Return(nil, errors.New("some error"))
+				},
+			},
+			expCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "full flow",
+			nickName: "diegof",
+			mocks: roleMocks{
+				roleHandler: func(f *mockRoleHandler) {
+					f.roleService.Mock.On("GetByUser", mock.Anything, "diegof").Return([]response.RoleResponse{{ID: "1", Name: "user"}}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockRoleHandler{
+				&mocks.IRoleService{},
+			}
+			tc.mocks.roleHandler(ms)
+			handler := NewRoleHandler(ms.roleService)
+			url := "/users/roles"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.GET(url, func(ctx *gin.Context) {
+				if tc.nickName != "" {
+					ctx.AddParam("code", tc.nickName)
+				}
+				handler.GetByUser(ctx)
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
+
+func Test_Grant(t *testing.T) {
+	tests := []struct {
+		input    interface{}
+		mocks    roleMocks
+		name     string
+		nickName string
+		expCode  int
+	}{
+		{
+			name: "nick name isnt present",
+			mocks: roleMocks{
+				roleHandler: func(f *mockRoleHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:     "error on input",
+			nickName: "diegof",
+			input:    "ABC",
+			mocks: roleMocks{
+				roleHandler: func(f *mockRoleHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown role",
+			nickName: "diegof",
+			input:    request.RoleRequest{Roles: []string{"root"}},
+			mocks: roleMocks{
+				roleHandler: func(f *mockRoleHandler) {
+					f.roleService.Mock.On("Grant", mock.Anything, "diegof", request.RoleRequest{Roles: []string{"root"}}).Return(nil, apperror.New(http.StatusBadRequest, "role root doesn't exist"))
+				},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:     "full flow",
+			nickName: "diegof",
+			input:    request.RoleRequest{Roles: []string{"admin"}},
+			mocks: roleMocks{
+				roleHandler: func(f *mockRoleHandler) {
+					f.roleService.Mock.On("Grant", mock.Anything, "diegof", request.RoleRequest{Roles: []string{"admin"}}).Return([]response.RoleResponse{{ID: "1", Name: "user"}, {ID: "2", Name: "admin"}}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockRoleHandler{
+				&mocks.IRoleService{},
+			}
+			tc.mocks.roleHandler(ms)
+			handler := NewRoleHandler(ms.roleService)
+			url := "/users/roles"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.POST(url, func(ctx *gin.Context) {
+				if tc.nickName != "" {
+					ctx.AddParam("code", tc.nickName)
+				}
+				handler.Grant(ctx)
+			})
+			res := httptest.NewRecorder()
+			b, _ := json.Marshal(tc.input)
+			req := httptest.NewRequest(http.MethodPost, url, io.NopCloser(bytes.NewBuffer(b)))
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
+
+func Test_Revoke(t *testing.T) {
+	tests := []struct {
+		mocks    roleMocks
+		name     string
+		nickName string
+		role     string
+		expCode  int
+	}{
+		{
+			name: "nick name isnt present",
+			mocks: roleMocks{
+				roleHandler: func(f *mockRoleHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:     "role isnt present",
+			nickName: "diegof",
+			mocks: roleMocks{
+				roleHandler: func(f *mockRoleHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:     "role not granted",
+			nickName: "diegof",
+			role:     "admin",
+			mocks: roleMocks{
+				roleHandler: func(f *mockRoleHandler) {
+					f.roleService.Mock.On("Revoke", mock.Anything, "diegof", "admin").Return(nil, apperror.New(http.StatusNotFound, "user diegof doesn't have the role admin"))
+				},
+			},
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:     "full flow",
+			nickName: "diegof",
+			role:     "admin",
+			mocks: roleMocks{
+				roleHandler: func(f *mockRoleHandler) {
+					f.roleService.Mock.On("Revoke", mock.Anything, "diegof", "admin").Return([]response.RoleResponse{{ID: "1", Name: "user"}}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockRoleHandler{
+				&mocks.IRoleService{},
+			}
+			tc.mocks.roleHandler(ms)
+			handler := NewRoleHandler(ms.roleService)
+			url := "/users/roles"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.DELETE(url, func(ctx *gin.Context) {
+				if tc.nickName != "" {
+					ctx.AddParam("code", tc.nickName)
+				}
+				if tc.role != "" {
+					ctx.AddParam("role", tc.role)
+				}
+				handler.Revoke(ctx)
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodDelete, url, nil)
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package user
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"cow_sso/api/handlers/user/request"
+)
+
+const (
+	_csvRoleSeparator   = ";"
+	_csvAttributePrefix = "attributes."
+)
+
+// _csvColumns sets, for every column an import csv may have, the UserRequest field it fills in.
+var _csvColumns = map[string]func(row *request.UserRequest, value string) error{
+	"name":      func(row *request.UserRequest, value string) error { row.Name = value; return nil },
+	"last_name": func(row *request.UserRequest, value string) error { row.LastName = value; return nil },
+	"email":     func(row *request.UserRequest, value string) error { row.Email = value; return nil },
+	"nick_name": func(row *request.UserRequest, value string) error { row.NickName = value; return nil },
+	"password":  func(row *request.UserRequest, value string) error { row.Password = value; return nil },
+	"roles": func(row *request.UserRequest, value string) error {
+		for _, role := range strings.Split(value, _csvRoleSeparator) {
+			if role = strings.TrimSpace(role); role != "" {
+				row.Roles = append(row.Roles, role)
+			}
+		}
+		return nil
+	},
+	"temporary_password": func(row *request.UserRequest, value string) (err error) {
+		row.TemporaryPassword, err = parseCSVBool(value)
+		return err
+	},
+	"send_set_password_email": func(row *request.UserRequest, value string) (err error) {
+		row.SendSetPasswordEmail, err = parseCSVBool(value)
+		return err
+	},
+}
+
+// _csvExportedColumns are the import columns the export writes too, the only ones that can carry
+// its formula escaping. The rest, such as password, are taken as they are.
+var _csvExportedColumns = map[string]bool{"name": true, "last_name": true, "email": true, "nick_name": true, "roles": true}
+
+// bodyTooLarge tells whether err comes from reading past the body limit, and which limit it was.
+func bodyTooLarge(err error) (int64, bool) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return maxBytesErr.Limit, true
+	}
+	return 0, false
+}
+
+// parseJSONRows reads an import sent as a json array of users.
+func parseJSONRows(body io.Reader) ([]request.UserRequest, error) {
+	var rows []request.UserRequest
+	if err := json.NewDecoder(body).Decode(&rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// parseCSVRows reads an import sent as csv. The header names the columns, as in the json body,
+// roles are separated by semicolons and attributes go in attributes.<key> columns.
+func parseCSVRows(body io.Reader) ([]request.UserRequest, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("the csv has no header")
+		}
+		return nil, err
+	}
+	for i, column := range header {
+		header[i] = strings.TrimSpace(column)
+		if _, ok := _csvColumns[header[i]]; !ok && !strings.HasPrefix(header[i], _csvAttributePrefix) {
+			return nil, fmt.Errorf("unknown column %s", header[i])
+		}
+	}
+
+	var rows []request.UserRequest
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var row request.UserRequest
+		for i, column := range header {
+			value := strings.TrimSpace(record[i])
+			if _csvExportedColumns[column] || strings.HasPrefix(column, _csvAttributePrefix) {
+				value = unescapeFormula(value)
+			}
+			if key, ok := strings.CutPrefix(column, _csvAttributePrefix); ok {
+				if value != "" {
+					if row.Attributes == nil {
+						row.Attributes = map[string]string{}
+					}
+					row.Attributes[key] = value
+				}
+				continue
+			}
+			if err := _csvColumns[column](&row, value); err != nil {
+				return nil, fmt.Errorf("line %d, column %s: %w", line, column, err)
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+func parseCSVBool(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// unescapeFormula drops the ' the export puts before values a spreadsheet would run as a formula.
+func unescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
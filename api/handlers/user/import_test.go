@@ -0,0 +1,82 @@
+package user
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"cow_sso/api/handlers/user/request"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseCSVRows(t *testing.T) {
+	tests := []struct {
+		expErr error
+		name   string
+		body   string
+		outPut []request.UserRequest
+	}{
+		{
+			name:   "empty",
+			expErr: errors.New("the csv has no header"),
+		},
+		{
+			name:   "unknown column",
+			body:   "name,age\ndiego,30\n",
+			expErr: errors.New("unknown column age"),
+		},
+		{
+			name:   "invalid boolean",
+			body:   "nick_name,temporary_password\ndiegof,maybe\n",
+			expErr: errors.New(`line 2, column temporary_password: strconv.ParseBool: parsing "maybe": invalid syntax`),
+		},
+		{
+			name: "escaped formulas from an export",
+			body: "nick_name,last_name,password,attributes.phone\n" +
+				"diegof,'o'neil,'-Secret123,'+5491155555555\n",
+			outPut: []request.UserRequest{
+				{
+					NickName:   "diegof",
+					LastName:   "'o'neil",
+					Password:   "'-Secret123",
+					Attributes: map[string]string{"phone": "+5491155555555"},
+				},
+			},
+		},
+		{
+			name: "full flow",
+			body: "name,last_name,email,nick_name,roles,send_set_password_email,attributes.locale\n" +
+				"diego,fernandez,diegof@gmail.com,diegof, user;admin ,true,es\n" +
+				"diego,alvarez,diegoa@gmail.com,diegoa,,,\n",
+			outPut: []request.UserRequest{
+				{
+					Name:                 "diego",
+					LastName:             "fernandez",
+					Email:                "diegof@gmail.com",
+					NickName:             "diegof",
+					Roles:                []string{"user", "admin"},
+					SendSetPasswordEmail: true,
+					Attributes:           map[string]string{"locale": "es"},
+				},
+				{
+					Name:     "diego",
+					LastName: "alvarez",
+					Email:    "diegoa@gmail.com",
+					NickName: "diegoa",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseCSVRows(strings.NewReader(tt.body))
+			if tt.expErr != nil {
+				assert.EqualError(t, err, tt.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.outPut, rows)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"cow_sso/api/handlers/errors"
+	"cow_sso/api/handlers/user/request"
+	"cow_sso/api/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Register signs up whoever calls it, it's public and guarded by the register rate limit instead.
+func (uh *userHandler) Register(c *gin.Context) {
+	ctx := c.Request.Context()
+	var registerRequest request.RegisterRequest
+	if err := c.ShouldBindJSON(&registerRequest); err != nil {
+		if fields, ok := validation.Fields(err); ok {
+			c.JSON(http.StatusUnprocessableEntity, errors.Invalid(fields))
+			return
+		}
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "invalid format",
+		})
+		return
+	}
+
+	registration, err := uh.userService.Register(ctx, registerRequest)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error registering user %s, err: %s", registerRequest.NickName, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusCreated, registration)
+}
+
+// GetRegistrations lists the users waiting for approval, paged like GetAll.
+func (uh *userHandler) GetRegistrations(c *gin.Context) {
+	ctx := c.Request.Context()
+	var userQuery request.UserQuery
+	if err := c.ShouldBindQuery(&userQuery); err != nil {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "invalid query params",
+		})
+		return
+	}
+
+	users, err := uh.userService.GetRegistrations(ctx, userQuery)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error getting registrations, err: %s", err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+
+	if links := pageLinks(c.Request.URL, users); len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+func (uh *userHandler) ApproveRegistration(c *gin.Context) {
+	ctx := c.Request.Context()
+	nickName, exists := c.Params.Get("code")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "user's nick name is required",
+		})
+		return
+	}
+
+	user, err := uh.userService.ApproveRegistration(ctx, nickName)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error approving user %s, err: %s", nickName, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func (uh *userHandler) RejectRegistration(c *gin.Context) {
+	ctx := c.Request.Context()
+	nickName, exists := c.Params.Get("code")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "user's nick name is required",
+		})
+		return
+	}
+
+	if err := uh.userService.RejectRegistration(ctx, nickName); err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error rejecting user %s, err: %s", nickName, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, fmt.Sprintf("user %s rejected", nickName))
+}
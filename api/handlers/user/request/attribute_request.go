@@ -0,0 +1,5 @@
+package request
+
+type AttributeRequest struct {
+	Value string `json:"value" binding:"required"`
+}
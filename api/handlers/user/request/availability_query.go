@@ -0,0 +1,6 @@
+package request
+
+type AvailabilityQuery struct {
+	NickName string `form:"nick_name" binding:"omitempty,min=3,max=30,nickname,notreserved"`
+	Email    string `form:"email" binding:"omitempty,email,max=254"`
+}
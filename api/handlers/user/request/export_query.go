@@ -0,0 +1,9 @@
+package request
+
+type ExportQuery struct {
+	UserFilter
+	// Format is csv, the default, or ndjson.
+	Format string `form:"format"`
+	// Expand names the related data added to every user, taken from the expand param.
+	Expand []string `form:"-"`
+}
@@ -0,0 +1,6 @@
+package request
+
+type ImportQuery struct {
+	// DryRun checks every row without creating any user.
+	DryRun bool `form:"dry_run"`
+}
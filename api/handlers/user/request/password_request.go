@@ -0,0 +1,8 @@
+package request
+
+// PasswordRequest is the password an admin sets for a user, which the user must change on
+// next login when Temporary is set.
+type PasswordRequest struct {
+	Password  string `json:"password" binding:"required,min=8,max=128"`
+	Temporary bool   `json:"temporary,omitempty"`
+}
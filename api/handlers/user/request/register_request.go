@@ -0,0 +1,11 @@
+package request
+
+// RegisterRequest is what people signing themselves up send. It checks the same fields as
+// UserRequest, without the ones only admins decide on, like roles or attributes.
+type RegisterRequest struct {
+	Name     string `json:"name" binding:"required,notblank,max=100"`
+	LastName string `json:"last_name" binding:"required,notblank,max=100"`
+	Email    string `json:"email" binding:"required,email,max=254"`
+	NickName string `json:"nick_name" binding:"required,min=3,max=30,nickname,notreserved"`
+	Password string `json:"password" binding:"required,min=8,max=128"`
+}
@@ -0,0 +1,8 @@
+package request
+
+type UpdateUserRequest struct {
+	Attributes *map[string]string `json:"attributes"`
+	Name       *string            `json:"name" binding:"omitempty,notblank,max=100"`
+	LastName   *string            `json:"last_name" binding:"omitempty,notblank,max=100"`
+	Email      *string            `json:"email" binding:"omitempty,email,max=254"`
+}
@@ -0,0 +1,17 @@
+package request
+
+// UserFilter narrows down the users listed or exported.
+type UserFilter struct {
+	Enabled *bool  `form:"enabled"`
+	Search  string `form:"search"`
+	Email   string `form:"email"`
+	// Attributes filters by attribute value, as space separated key:value pairs.
+	Attributes string `form:"q"`
+}
+
+type UserQuery struct {
+	UserFilter
+	Sort  string `form:"sort"`
+	First int    `form:"first"`
+	Max   int    `form:"max"`
+}
@@ -1,8 +1,17 @@
 package request
 
 type UserRequest struct {
-	Name     string `json:"name"`
-	LastName string `json:"last_name"`
-	Email    string `json:"email"`
-	NickName string `json:"nick_name"`
+	// Attributes must use the keys, and value types, listed under attributes in properties.yml.
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Name       string            `json:"name" binding:"required,notblank,max=100"`
+	LastName   string            `json:"last_name" binding:"required,notblank,max=100"`
+	Email      string            `json:"email" binding:"required,email,max=254"`
+	NickName   string            `json:"nick_name" binding:"required,min=3,max=30,nickname,notreserved"`
+	// Password is the initial password, which the user must change on first login when TemporaryPassword is set.
+	Password string `json:"password,omitempty" binding:"omitempty,min=8,max=128"`
+	// Roles are the realm roles granted to the new user, keycloak.default-roles when empty.
+	Roles             []string `json:"roles,omitempty"`
+	TemporaryPassword bool     `json:"temporary_password,omitempty"`
+	// SendSetPasswordEmail asks keycloak to email the user a link to choose a password and verify the email.
+	SendSetPasswordEmail bool `json:"send_set_password_email,omitempty"`
 }
@@ -0,0 +1,6 @@
+package response
+
+type AttributeResponse struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
@@ -0,0 +1,7 @@
+package response
+
+// AvailabilityResponse tells whether each requested value is still free, leaving out the ones that weren't asked.
+type AvailabilityResponse struct {
+	NickName *bool `json:"nick_name,omitempty"`
+	Email    *bool `json:"email,omitempty"`
+}
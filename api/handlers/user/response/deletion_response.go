@@ -0,0 +1,11 @@
+package response
+
+// DeletionBlocked lists the teams that keep a user from being deleted, along with what the user owes each one.
+type DeletionBlocked struct {
+	Teams []BlockingTeam `json:"teams"`
+}
+
+type BlockingTeam struct {
+	Code string `json:"code"`
+	Debt int    `json:"debt"`
+}
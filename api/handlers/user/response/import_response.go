@@ -0,0 +1,28 @@
+package response
+
+import "cow_sso/api/handlers/errors"
+
+const (
+	ImportCreated          = "created"
+	ImportSkippedDuplicate = "skipped_duplicate"
+	ImportFailed           = "failed"
+)
+
+// ImportResponse reports what happened to every row of an import, in the order the rows were sent.
+// On a dry run created means the row would have been created.
+type ImportResponse struct {
+	Rows    []ImportRow `json:"rows"`
+	Created int         `json:"created"`
+	Skipped int         `json:"skipped_duplicate"`
+	Failed  int         `json:"failed"`
+	DryRun  bool        `json:"dry_run"`
+}
+
+type ImportRow struct {
+	NickName string `json:"nick_name"`
+	Status   string `json:"status"`
+	Reason   string `json:"reason,omitempty"`
+	// Fields lists the validation rules the row broke, when that's why it failed.
+	Fields []errors.FieldError `json:"fields,omitempty"`
+	Row    int                 `json:"row"`
+}
@@ -0,0 +1,20 @@
+package response
+
+import "time"
+
+// OffboardingResponse shows how far the offboarding of a user got, step by step.
+type OffboardingResponse struct {
+	StartedAt time.Time                 `json:"started_at"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+	UserID    string                    `json:"user_id"`
+	NickName  string                    `json:"nick_name"`
+	Status    string                    `json:"status"`
+	Steps     []OffboardingStepResponse `json:"steps"`
+}
+
+type OffboardingStepResponse struct {
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+}
@@ -0,0 +1,12 @@
+package response
+
+// Registration statuses, telling what a new account still waits for before it can sign in.
+const (
+	RegistrationVerifyEmail     = "verify_email"
+	RegistrationPendingApproval = "pending_approval"
+)
+
+type RegistrationResponse struct {
+	NickName string `json:"nick_name"`
+	Status   string `json:"status"`
+}
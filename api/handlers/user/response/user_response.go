@@ -1,9 +1,69 @@
 package response
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
 type UserResponse struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	LastName string `json:"last_name"`
-	Email    string `json:"email"`
-	NickName string `json:"nick_name"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	CreatedAt  *time.Time        `json:"created_at,omitempty"`
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	LastName   string            `json:"last_name"`
+	Email      string            `json:"email"`
+	NickName   string            `json:"nick_name"`
+	// Roles, Groups and Teams are only filled in when asked to expand them. Roles holds the realm
+	// roles granted to the user, Groups the paths of its groups and Teams the codes of its teams.
+	Roles         []string `json:"roles,omitempty"`
+	Groups        []string `json:"groups,omitempty"`
+	Teams         []string `json:"teams,omitempty"`
+	Enabled       bool     `json:"enabled"`
+	EmailVerified bool     `json:"email_verified"`
+}
+
+// NewUserResponse maps a keycloak user the same way wherever users are listed, keeping only the
+// attributes keep allows. The rest are keycloak's, an identity provider's or the service's own,
+// like the registration marker, and aren't for clients.
+func NewUserResponse(user *gocloak.User, keep func(key string) bool) UserResponse {
+	userResponse := UserResponse{
+		ID:            gocloak.PString(user.ID),
+		Name:          gocloak.PString(user.FirstName),
+		LastName:      gocloak.PString(user.LastName),
+		Email:         gocloak.PString(user.Email),
+		NickName:      gocloak.PString(user.Username),
+		Enabled:       gocloak.PBool(user.Enabled),
+		EmailVerified: gocloak.PBool(user.EmailVerified),
+	}
+	if user.CreatedTimestamp != nil {
+		createdAt := time.UnixMilli(*user.CreatedTimestamp).UTC()
+		userResponse.CreatedAt = &createdAt
+	}
+	if user.Attributes != nil {
+		for key, values := range *user.Attributes {
+			if !keep(key) || len(values) == 0 {
+				continue
+			}
+			if userResponse.Attributes == nil {
+				userResponse.Attributes = map[string]string{}
+			}
+			userResponse.Attributes[key] = values[0]
+		}
+	}
+	return userResponse
+}
+
+// ETag identifies the current representation of the user, for optimistic concurrency on updates.
+// Expansions aren't part of the user itself, so they're left out of it.
+func (u UserResponse) ETag() string {
+	u.Roles = nil
+	u.Groups = nil
+	u.Teams = nil
+	b, _ := json.Marshal(u)
+	sum := sha256.Sum256(b)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
 }
@@ -0,0 +1,8 @@
+package response
+
+type UsersResponse struct {
+	Users []UserResponse `json:"users"`
+	Total int            `json:"total"`
+	First int            `json:"first"`
+	Max   int            `json:"max"`
+}
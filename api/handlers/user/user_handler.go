@@ -1,11 +1,19 @@
 package user
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"cow_sso/api/handlers/errors"
 	"cow_sso/api/handlers/user/request"
+	"cow_sso/api/handlers/user/response"
+	"cow_sso/api/validation"
+	"cow_sso/pkg/apperror"
+	"cow_sso/pkg/config"
 	"cow_sso/pkg/service/user"
 
 	"github.com/gin-gonic/gin"
@@ -13,121 +21,469 @@ import (
 
 type IUserHandler interface {
 	GetAll(c *gin.Context)
+	Export(c *gin.Context)
 	GetByNickName(c *gin.Context)
+	GetByID(c *gin.Context)
+	GetByEmail(c *gin.Context)
+	Availability(c *gin.Context)
 	Create(c *gin.Context)
+	Register(c *gin.Context)
+	GetRegistrations(c *gin.Context)
+	ApproveRegistration(c *gin.Context)
+	RejectRegistration(c *gin.Context)
+	Import(c *gin.Context)
+	Update(c *gin.Context)
+	Patch(c *gin.Context)
+	Enable(c *gin.Context)
+	Disable(c *gin.Context)
+	ResetPassword(c *gin.Context)
 	Delete(c *gin.Context)
+	GetOffboarding(c *gin.Context)
+	GetAttribute(c *gin.Context)
+	SetAttribute(c *gin.Context)
+	DeleteAttribute(c *gin.Context)
 }
 
+const (
+	// _defaultImportMaxRows matches the user service's default for import.max-rows.
+	_defaultImportMaxRows = 500
+	// _importRowBytes is the room an import body gets per row it may have, generous for a user with its attributes.
+	_importRowBytes = 8 << 10
+)
+
 type userHandler struct {
-	userService user.IUserService
+	userService    user.IUserService
+	importMaxBytes int64
 }
 
 func NewUserHandler(userService user.IUserService) IUserHandler {
 	return &userHandler{
-		userService: userService,
+		userService:    userService,
+		importMaxBytes: int64(config.Get().UInt("import.max-rows", _defaultImportMaxRows)) * _importRowBytes,
 	}
 }
 
 func (uh *userHandler) GetAll(c *gin.Context) {
 	ctx := c.Request.Context()
-	token := c.Request.Header.Get("Authorization")
-
-	if token == "" {
-		c.JSON(http.StatusUnauthorized, errors.ApiErrors{
-			Code:    http.StatusUnauthorized,
-			Message: "token is required",
+	var userQuery request.UserQuery
+	if err := c.ShouldBindQuery(&userQuery); err != nil {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "invalid query params",
 		})
 		return
 	}
 
-	users, err := uh.userService.GetAll(ctx, token[7:])
+	users, err := uh.userService.GetAll(ctx, userQuery)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errors.ApiErrors{
-			Code:    http.StatusInternalServerError,
-			Message: "error getting users",
+		code := apperror.Code(err, http.StatusInternalServerError)
+		message := "error getting users"
+		if code != http.StatusInternalServerError {
+			message = err.Error()
+		}
+		c.JSON(code, errors.ApiErrors{
+			Code:    code,
+			Message: message,
 		})
 		return
 	}
+
+	if links := pageLinks(c.Request.URL, users); len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
 	c.JSON(http.StatusOK, users)
 }
 
-func (uh *userHandler) GetByNickName(c *gin.Context) {
+// Export streams the users as csv or ndjson. Once the first rows are out the status can't change
+// anymore, so a later failure aborts the connection, and the client never takes a partial export
+// for a complete one.
+func (uh *userHandler) Export(c *gin.Context) {
 	ctx := c.Request.Context()
+	var exportQuery request.ExportQuery
+	if err := c.ShouldBindQuery(&exportQuery); err != nil {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "invalid query params",
+		})
+		return
+	}
+	exportQuery.Expand = expandParam(c)
 
-	token := c.Request.Header.Get("Authorization")
+	w := &exportWriter{ResponseWriter: c.Writer, format: exportQuery.Format}
+	if err := uh.userService.Export(ctx, exportQuery, w); err != nil {
+		if c.Writer.Written() {
+			_ = c.Error(err)
+			panic(http.ErrAbortHandler)
+		}
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error exporting users, err: %s", err.Error())
+		c.JSON(apiErr.Code, apiErr)
+	}
+}
 
-	if token == "" {
-		c.JSON(http.StatusUnauthorized, errors.ApiErrors{
-			Code:    http.StatusUnauthorized,
-			Message: "token is required",
-		})
+// exportWriter sets the export headers right before the first write, so that errors found
+// before then can still be answered as json.
+type exportWriter struct {
+	gin.ResponseWriter
+	format string
+}
+
+func (w *exportWriter) Write(b []byte) (int, error) {
+	w.start()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush is reached without any write when nothing matched an ndjson export.
+func (w *exportWriter) Flush() {
+	w.start()
+	w.ResponseWriter.Flush()
+}
+
+func (w *exportWriter) start() {
+	if w.Written() {
 		return
 	}
+	contentType, extension := "text/csv", "csv"
+	if w.format == "ndjson" {
+		contentType, extension = "application/x-ndjson", "ndjson"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="users.%s"`, extension))
+	w.WriteHeader(http.StatusOK)
+	w.WriteHeaderNow()
+}
 
-	nickName, exists := c.Params.Get("code")
+// pageLinks builds the RFC 5988 links to the pages around the one in users.
+func pageLinks(current *url.URL, users response.UsersResponse) []string {
+	link := func(first int, rel string) string {
+		page := *current
+		query := page.Query()
+		query.Set("first", strconv.Itoa(first))
+		query.Set("max", strconv.Itoa(users.Max))
+		page.RawQuery = query.Encode()
+		return fmt.Sprintf("<%s>; rel=\"%s\"", page.RequestURI(), rel)
+	}
+
+	var links []string
+	if users.First+users.Max < users.Total {
+		links = append(links, link(users.First+users.Max, "next"))
+	}
+	if users.First > 0 {
+		links = append(links, link(max(users.First-users.Max, 0), "prev"))
+	}
+	return links
+}
+
+func (uh *userHandler) GetByNickName(c *gin.Context) {
+	uh.getUser(c, "code", "user's nick name is required", uh.userService.GetByNickName)
+}
+
+func (uh *userHandler) GetByID(c *gin.Context) {
+	uh.getUser(c, "id", "user's id is required", uh.userService.GetByID)
+}
+
+func (uh *userHandler) GetByEmail(c *gin.Context) {
+	uh.getUser(c, "email", "user's email is required", uh.userService.GetByEmail)
+}
+
+// expandParam reads the expand query param, which may be repeated or list names separated by commas.
+func expandParam(c *gin.Context) []string {
+	var expand []string
+	for _, query := range c.QueryArray("expand") {
+		for _, name := range strings.Split(query, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				expand = append(expand, name)
+			}
+		}
+	}
+	return expand
+}
+
+// getUser answers with the user that find returns for the value of the param path parameter.
+func (uh *userHandler) getUser(c *gin.Context, param string, required string, find func(ctx context.Context, value string, expand []string) (response.UserResponse, error)) {
+	ctx := c.Request.Context()
+	value, exists := c.Params.Get(param)
 	if !exists {
 		c.JSON(http.StatusBadRequest, errors.ApiErrors{
 			Code:    http.StatusBadRequest,
-			Message: "user's nick name is required",
+			Message: required,
 		})
 		return
 	}
 
-	user, err := uh.userService.GetByNickName(ctx, token[7:], nickName)
+	user, err := find(ctx, value, expandParam(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errors.ApiErrors{
-			Code:    http.StatusInternalServerError,
-			Message: fmt.Sprintf("error getting user %s, err: %s", nickName, err.Error()),
-		})
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error getting user %s, err: %s", value, err.Error())
+		c.JSON(apiErr.Code, apiErr)
 		return
 	}
+	c.Header("ETag", user.ETag())
 	c.JSON(http.StatusOK, user)
 }
 
+func (uh *userHandler) Availability(c *gin.Context) {
+	ctx := c.Request.Context()
+	var availabilityQuery request.AvailabilityQuery
+	if err := c.ShouldBindQuery(&availabilityQuery); err != nil {
+		if fields, ok := validation.Fields(err); ok {
+			c.JSON(http.StatusUnprocessableEntity, errors.Invalid(fields))
+			return
+		}
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "invalid query params",
+		})
+		return
+	}
+
+	availability, err := uh.userService.Availability(ctx, availabilityQuery)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error checking availability, err: %s", err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, availability)
+}
+
 func (uh *userHandler) Create(c *gin.Context) {
 	ctx := c.Request.Context()
-	token := c.Request.Header.Get("Authorization")
+	var userRequest request.UserRequest
+	if err := c.ShouldBindJSON(&userRequest); err != nil {
+		if fields, ok := validation.Fields(err); ok {
+			c.JSON(http.StatusUnprocessableEntity, errors.Invalid(fields))
+			return
+		}
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "invalid format",
+		})
+		return
+	}
+	err := uh.userService.Create(ctx, userRequest)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error creating user %s, err: %s", userRequest.NickName, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, fmt.Sprintf("user %s created", userRequest.NickName))
+}
 
-	if token == "" {
-		c.JSON(http.StatusUnauthorized, errors.ApiErrors{
-			Code:    http.StatusUnauthorized,
-			Message: "token is required",
+// Import creates the users sent as a json array or, with a text/csv content type, as csv rows.
+// Bodies bigger than import.max-rows rows could take are refused before they're read whole.
+func (uh *userHandler) Import(c *gin.Context) {
+	ctx := c.Request.Context()
+	var query request.ImportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "invalid format",
 		})
 		return
 	}
 
-	var userRequest request.UserRequest
-	if err := c.BindJSON(&userRequest); err != nil {
+	parse := parseJSONRows
+	if c.ContentType() == "text/csv" {
+		parse = parseCSVRows
+	}
+	rows, err := parse(http.MaxBytesReader(c.Writer, c.Request.Body, uh.importMaxBytes))
+	if limit, ok := bodyTooLarge(err); ok {
+		c.JSON(http.StatusRequestEntityTooLarge, errors.ApiErrors{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("an import takes at most %d bytes", limit),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("error reading users to import, err: %s", err.Error()),
+		})
+		return
+	}
+
+	report, err := uh.userService.Import(ctx, rows, query.DryRun)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error importing users, err: %s", err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func (uh *userHandler) Update(c *gin.Context) {
+	uh.update(c, false)
+}
+
+func (uh *userHandler) Patch(c *gin.Context) {
+	uh.update(c, true)
+}
+
+func (uh *userHandler) update(c *gin.Context, partial bool) {
+	ctx := c.Request.Context()
+	nickName, exists := c.Params.Get("code")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "user's nick name is required",
+		})
+		return
+	}
+
+	var updateRequest request.UpdateUserRequest
+	if err := c.ShouldBindJSON(&updateRequest); err != nil {
+		if fields, ok := validation.Fields(err); ok {
+			c.JSON(http.StatusUnprocessableEntity, errors.Invalid(fields))
+			return
+		}
 		c.JSON(http.StatusBadRequest, errors.ApiErrors{
 			Code:    http.StatusBadRequest,
 			Message: "invalid format",
 		})
 		return
 	}
-	err := uh.userService.Create(ctx, token[7:], userRequest)
+
+	user, err := uh.userService.Update(ctx, nickName, updateRequest, partial, c.GetHeader("If-Match"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errors.ApiErrors{
-			Code:    http.StatusInternalServerError,
-			Message: fmt.Sprintf("error creating user %s, err: %s", userRequest.NickName, err.Error()),
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error updating user %s, err: %s", nickName, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.Header("ETag", user.ETag())
+	c.JSON(http.StatusOK, user)
+}
+
+func (uh *userHandler) Enable(c *gin.Context) {
+	uh.setEnabled(c, true)
+}
+
+func (uh *userHandler) Disable(c *gin.Context) {
+	uh.setEnabled(c, false)
+}
+
+func (uh *userHandler) setEnabled(c *gin.Context, enabled bool) {
+	ctx := c.Request.Context()
+	nickName, exists := c.Params.Get("code")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "user's nick name is required",
 		})
 		return
 	}
-	c.JSON(http.StatusOK, fmt.Sprintf("user %s created", userRequest.NickName))
+
+	user, err := uh.userService.SetEnabled(ctx, nickName, enabled)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error updating user %s, err: %s", nickName, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func (uh *userHandler) ResetPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	nickName, exists := c.Params.Get("code")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "user's nick name is required",
+		})
+		return
+	}
+	var passwordRequest request.PasswordRequest
+	if err := c.ShouldBindJSON(&passwordRequest); err != nil {
+		if fields, ok := validation.Fields(err); ok {
+			c.JSON(http.StatusUnprocessableEntity, errors.Invalid(fields))
+			return
+		}
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "invalid format",
+		})
+		return
+	}
+
+	if err := uh.userService.ResetPassword(ctx, nickName, passwordRequest); err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error resetting the password of user %s, err: %s", nickName, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, fmt.Sprintf("password of user %s reset", nickName))
 }
 
 func (uh *userHandler) Delete(c *gin.Context) {
 	ctx := c.Request.Context()
+	nickName, exists := c.Params.Get("code")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "user's nick name is required",
+		})
+		return
+	}
+
+	userName, err := uh.userService.Delete(ctx, nickName)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error deleting user: %s, err: %s", nickName, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, fmt.Sprintf("user %s delete", userName))
+}
+
+func (uh *userHandler) GetOffboarding(c *gin.Context) {
+	ctx := c.Request.Context()
+	nickName, exists := c.Params.Get("code")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "user's nick name is required",
+		})
+		return
+	}
 
-	token := c.Request.Header.Get("Authorization")
+	offboarding, err := uh.userService.GetOffboarding(ctx, nickName)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error getting offboarding of user %s, err: %s", nickName, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, offboarding)
+}
 
-	if token == "" {
-		c.JSON(http.StatusUnauthorized, errors.ApiErrors{
-			Code:    http.StatusUnauthorized,
-			Message: "token is required",
+func (uh *userHandler) GetAttribute(c *gin.Context) {
+	ctx := c.Request.Context()
+	nickName, exists := c.Params.Get("code")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "user's nick name is required",
 		})
 		return
 	}
+	key := c.Param("key")
 
+	attribute, err := uh.userService.GetAttribute(ctx, nickName, key)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error getting attribute %s of user %s, err: %s", key, nickName, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, attribute)
+}
+
+func (uh *userHandler) SetAttribute(c *gin.Context) {
+	ctx := c.Request.Context()
 	nickName, exists := c.Params.Get("code")
 	if !exists {
 		c.JSON(http.StatusBadRequest, errors.ApiErrors{
@@ -136,14 +492,48 @@ func (uh *userHandler) Delete(c *gin.Context) {
 		})
 		return
 	}
+	key := c.Param("key")
 
-	userName, err := uh.userService.Delete(ctx, token[7:], nickName)
+	var attributeRequest request.AttributeRequest
+	if err := c.ShouldBindJSON(&attributeRequest); err != nil {
+		if fields, ok := validation.Fields(err); ok {
+			c.JSON(http.StatusUnprocessableEntity, errors.Invalid(fields))
+			return
+		}
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("error parsing attribute %s, err: %s", key, err.Error()),
+		})
+		return
+	}
+
+	attribute, err := uh.userService.SetAttribute(ctx, nickName, key, attributeRequest.Value)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errors.ApiErrors{
-			Code:    http.StatusInternalServerError,
-			Message: fmt.Sprintf("error deleting user: %s, err: %s", nickName, err.Error()),
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error setting attribute %s of user %s, err: %s", key, nickName, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, attribute)
+}
+
+func (uh *userHandler) DeleteAttribute(c *gin.Context) {
+	ctx := c.Request.Context()
+	nickName, exists := c.Params.Get("code")
+	if !exists {
+		c.JSON(http.StatusBadRequest, errors.ApiErrors{
+			Code:    http.StatusBadRequest,
+			Message: "user's nick name is required",
 		})
 		return
 	}
-	c.JSON(http.StatusOK, fmt.Sprintf("user %s delete", userName))
+	key := c.Param("key")
+
+	if err := uh.userService.DeleteAttribute(ctx, nickName, key); err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		apiErr.Message = fmt.Sprintf("error deleting attribute %s of user %s, err: %s", key, nickName, err.Error())
+		c.JSON(apiErr.Code, apiErr)
+		return
+	}
+	c.JSON(http.StatusOK, fmt.Sprintf("attribute %s of user %s delete", key, nickName))
 }
@@ -7,12 +7,17 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	apiErrors "cow_sso/api/handlers/errors"
 	"cow_sso/api/handlers/user/request"
 	"cow_sso/api/handlers/user/response"
+	"cow_sso/api/validation"
 	"cow_sso/mocks"
+	"cow_sso/pkg/apperror"
 
+	"github.com/Nerzal/gocloak/v13"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -28,37 +33,71 @@ type userMocks struct {
 
 func Test_GetAll(t *testing.T) {
 	tests := []struct {
-		mocks       userMocks
-		name        string
-		token       string
-		expNickName int
+		mocks   userMocks
+		name    string
+		query   string
+		expLink string
+		expCode int
 	}{
 		{
-			name: "error get users",
+			name:  "invalid query params",
+			query: "?first=abc",
 			mocks: userMocks{
 				userHandler: func(f *mockUserHandler) {},
 			},
-			expNickName: http.StatusUnauthorized,
+			expCode: http.StatusBadRequest,
 		},
 		{
-			name:  "error get users",
-			token: "Bearer ABC",
+			name:  "invalid sort",
+			query: "?sort=password",
 			mocks: userMocks{
 				userHandler: func(f *mockUserHandler) {
-					f.userService.Mock.On("GetAll", mock.Anything, "ABC").Return([]response.UserResponse{}, errors.New("error x"))
+					f.userService.Mock.On("GetAll", mock.Anything, request.UserQuery{Sort: "password"}).Return(response.UsersResponse{}, apperror.New(http.StatusBadRequest, "can't sort users by password"))
 				},
 			},
-			expNickName: http.StatusInternalServerError,
+			expCode: http.StatusBadRequest,
 		},
 		{
-			name:  "full flow",
-			token: "Bearer ABC",
+			name: "error get users",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("GetAll", mock.Anything, request.UserQuery{}).Return(response.UsersResponse{}, errors.New("error x"))
+				},
+			},
+			expCode: http.StatusInternalServerError,
+		},
+		{
+			name: "full flow",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("GetAll", mock.Anything, request.UserQuery{}).Return(response.UsersResponse{
+						Users: []response.UserResponse{},
+						Total: 0,
+						Max:   20,
+					}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:  "middle page",
+			query: "?search=diego&first=20&max=20",
 			mocks: userMocks{
 				userHandler: func(f *mockUserHandler) {
-					f.userService.Mock.On("GetAll", mock.Anything, "ABC").Return([]response.UserResponse{}, nil)
+					f.userService.Mock.On("GetAll", mock.Anything, request.UserQuery{
+						UserFilter: request.UserFilter{Search: "diego"},
+						First:      20,
+						Max:        20,
+					}).Return(response.UsersResponse{
+						Users: []response.UserResponse{},
+						Total: 50,
+						First: 20,
+						Max:   20,
+					}, nil)
 				},
 			},
-			expNickName: http.StatusOK,
+			expLink: `</users?first=40&max=20&search=diego>; rel="next", </users?first=0&max=20&search=diego>; rel="prev"`,
+			expCode: http.StatusOK,
 		},
 	}
 	for _, tc := range tests {
@@ -74,12 +113,10 @@ func Test_GetAll(t *testing.T) {
 				handler.GetAll(ctx)
 			})
 			res := httptest.NewRecorder()
-			req := httptest.NewRequest(http.MethodGet, url, nil)
-			if tc.token != "" {
-				req.Header.Set("Authorization", tc.token)
-			}
+			req := httptest.NewRequest(http.MethodGet, url+tc.query, nil)
 			engine.ServeHTTP(res, req)
-			assert.Equal(t, tc.expNickName, res.Code)
+			assert.Equal(t, tc.expCode, res.Code)
+			assert.Equal(t, tc.expLink, res.Header().Get("Link"))
 		})
 	}
 }
@@ -89,19 +126,11 @@ func Test_GetByNickName(t *testing.T) {
 		mocks    userMocks
 		name     string
 		nickName string
-		token    string
+		query    string
 		expCode  int
 	}{
-		{
-			name: "error get users",
-			mocks: userMocks{
-				userHandler: func(f *mockUserHandler) {},
-			},
-			expCode: http.StatusUnauthorized,
-		},
 		{
 			name:     "code not sending",
-			token:    "Bearer ABC",
 			nickName: "",
 			mocks: userMocks{
 				userHandler: func(f *mockUserHandler) {},
@@ -110,23 +139,46 @@ func Test_GetByNickName(t *testing.T) {
 		},
 		{
 			name:     "error getting user by id",
-			token:    "Bearer ABC",
 			nickName: "ABC",
 			mocks: userMocks{
 				userHandler: func(f *mockUserHandler) {
-					f.userService.Mock.On("GetByNickName", mock.Anything, "ABC", "ABC").Return(response.UserResponse{}, errors.New("x"))
+					f.userService.Mock.On("GetByNickName", mock.Anything, "ABC", []string(nil)).Return(response.UserResponse{}, errors.New("x"))
 				},
 			},
 			expCode: http.StatusInternalServerError,
 		},
 		{
 			name:     "full flow",
-			token:    "Bearer ABC",
 			nickName: "ABC",
 			mocks: userMocks{
 				userHandler: func(f *mockUserHandler) {
-					f.userService.Mock.On("GetByNickName", mock.Anything, "ABC", "ABC").Return(response.UserResponse{
+					f.userService.Mock.On("GetByNickName", mock.Anything, "ABC", []string(nil)).Return(response.UserResponse{
+						NickName: "ABC",
+					}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:     "unknown expansion",
+			nickName: "ABC",
+			query:    "?expand=password",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("GetByNickName", mock.Anything, "ABC", []string{"password"}).Return(response.UserResponse{}, apperror.New(http.StatusBadRequest, "can't expand password"))
+				},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:     "expand groups",
+			nickName: "ABC",
+			query:    "?expand=groups,%20&expand=",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("GetByNickName", mock.Anything, "ABC", []string{"groups"}).Return(response.UserResponse{
 						NickName: "ABC",
+						Groups:   []string{"/sales"},
 					}, nil)
 				},
 			},
@@ -149,77 +201,327 @@ func Test_GetByNickName(t *testing.T) {
 				handler.GetByNickName(ctx)
 			})
 			res := httptest.NewRecorder()
-			req := httptest.NewRequest(http.MethodGet, url, nil)
-			if tc.token != "" {
-				req.Header.Set("Authorization", tc.token)
+			req := httptest.NewRequest(http.MethodGet, url+tc.query, nil)
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
+
+func Test_GetByIDAndEmail(t *testing.T) {
+	tests := []struct {
+		mocks   userMocks
+		name    string
+		param   string
+		value   string
+		expCode int
+	}{
+		{
+			name:  "id isnt present",
+			param: "id",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:  "id not found",
+			param: "id",
+			value: "abcde8",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("GetByID", mock.Anything, "abcde8", []string(nil)).Return(response.UserResponse{}, apperror.New(http.StatusNotFound, "user abcde8 doesn't exist"))
+				},
+			},
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:  "by id",
+			param: "id",
+			value: "abcde8",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("GetByID", mock.Anything, "abcde8", []string(nil)).Return(response.UserResponse{ID: "abcde8"}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:  "email isnt present",
+			param: "email",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:  "ambiguous email",
+			param: "email",
+			value: "diego@gmail.com",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("GetByEmail", mock.Anything, "diego@gmail.com", []string(nil)).Return(response.UserResponse{}, apperror.New(http.StatusConflict, "2 users match email diego@gmail.com"))
+				},
+			},
+			expCode: http.StatusConflict,
+		},
+		{
+			name:  "by email",
+			param: "email",
+			value: "diego@gmail.com",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("GetByEmail", mock.Anything, "diego@gmail.com", []string(nil)).Return(response.UserResponse{Email: "diego@gmail.com"}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockUserHandler{
+				&mocks.IUserService{},
 			}
+			tc.mocks.userHandler(ms)
+			handler := NewUserHandler(ms.userService)
+			url := "/users"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.GET(url, func(ctx *gin.Context) {
+				if tc.value != "" {
+					ctx.AddParam(tc.param, tc.value)
+				}
+				if tc.param == "email" {
+					handler.GetByEmail(ctx)
+				} else {
+					handler.GetByID(ctx)
+				}
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, url, nil)
 			engine.ServeHTTP(res, req)
 			assert.Equal(t, tc.expCode, res.Code)
 		})
 	}
 }
 
-func Test_Create(t *testing.T) {
+func Test_Availability(t *testing.T) {
+	validation.Register()
 	tests := []struct {
-		input   interface{}
 		mocks   userMocks
 		name    string
-		token   string
+		query   string
 		expCode int
 	}{
 		{
-			name: "error get users",
+			name:  "invalid query params",
+			query: "?nick_name=Diego F",
 			mocks: userMocks{
 				userHandler: func(f *mockUserHandler) {},
 			},
-			expCode: http.StatusUnauthorized,
+			expCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "nothing to check",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("Availability", mock.Anything, request.AvailabilityQuery{}).Return(response.AvailabilityResponse{}, apperror.New(http.StatusBadRequest, "nick_name or email is required"))
+				},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:  "full flow",
+			query: "?nick_name=diegof&email=diego@gmail.com",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("Availability", mock.Anything, request.AvailabilityQuery{
+						NickName: "diegof",
+						Email:    "diego@gmail.com",
+					}).Return(response.AvailabilityResponse{
+						NickName: gocloak.BoolP(false),
+						Email:    gocloak.BoolP(true),
+					}, nil)
+				},
+			},
+			expCode: http.StatusOK,
 		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockUserHandler{
+				&mocks.IUserService{},
+			}
+			tc.mocks.userHandler(ms)
+			handler := NewUserHandler(ms.userService)
+			url := "/users/availability"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.GET(url, func(ctx *gin.Context) {
+				handler.Availability(ctx)
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, url+strings.ReplaceAll(tc.query, " ", "%20"), nil)
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
+
+func Test_Create(t *testing.T) {
+	validation.Register()
+	tests := []struct {
+		input     interface{}
+		mocks     userMocks
+		name      string
+		expFields []apiErrors.FieldError
+		expCode   int
+	}{
 		{
 			name:  "error on input",
 			input: "ABC",
-			token: "Bearer ABC",
 			mocks: userMocks{
 				userHandler: func(f *mockUserHandler) {},
 			},
 			expCode: http.StatusBadRequest,
 		},
 		{
-			name:  "error creating user",
-			token: "Bearer ABC",
+			name:  "missing fields",
+			input: request.UserRequest{},
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusUnprocessableEntity,
+			expFields: []apiErrors.FieldError{
+				{Field: "name", Rule: "required"},
+				{Field: "last_name", Rule: "required"},
+				{Field: "email", Rule: "required"},
+				{Field: "nick_name", Rule: "required"},
+			},
+		},
+		{
+			name: "invalid fields",
+			input: request.UserRequest{
+				Name:     " ",
+				LastName: "fernandez",
+				Email:    "diego",
+				NickName: "Diego F",
+			},
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusUnprocessableEntity,
+			expFields: []apiErrors.FieldError{
+				{Field: "name", Rule: "notblank"},
+				{Field: "email", Rule: "email"},
+				{Field: "nick_name", Rule: "nickname"},
+			},
+		},
+		{
+			name: "nick name too short",
+			input: request.UserRequest{
+				Name:     "diego",
+				LastName: "fernandez",
+				Email:    "diego@gmail.com",
+				NickName: "df",
+			},
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusUnprocessableEntity,
+			expFields: []apiErrors.FieldError{
+				{Field: "nick_name", Rule: "min", Param: "3"},
+			},
+		},
+		{
+			name: "password too short",
+			input: request.UserRequest{
+				Name:     "diego",
+				LastName: "fernandez",
+				Email:    "diego@gmail.com",
+				NickName: "diegof",
+				Password: "secret",
+			},
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusUnprocessableEntity,
+			expFields: []apiErrors.FieldError{
+				{Field: "password", Rule: "min", Param: "8"},
+			},
+		},
+		{
+			name: "reserved nick name",
+			input: request.UserRequest{
+				Name:     "diego",
+				LastName: "fernandez",
+				Email:    "diego@gmail.com",
+				NickName: "admin",
+			},
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusUnprocessableEntity,
+			expFields: []apiErrors.FieldError{
+				{Field: "nick_name", Rule: "notreserved"},
+			},
+		},
+		{
+			name: "error creating user",
 			input: request.UserRequest{
-				Name:     "a",
-				NickName: "c",
-				Email:    "d",
-				LastName: "e",
+				Name:     "diego",
+				NickName: "diegof",
+				Email:    "diego@gmail.com",
+				LastName: "fernandez",
 			},
 			mocks: userMocks{
 				userHandler: func(f *mockUserHandler) {
-					f.userService.Mock.On("Create", mock.Anything, "ABC", request.UserRequest{
-						Name:     "a",
-						NickName: "c",
-						Email:    "d",
-						LastName: "e",
+					f.userService.Mock.On("Create", mock.Anything, request.UserRequest{
+						Name:     "diego",
+						NickName: "diegof",
+						Email:    "diego@gmail.com",
+						LastName: "fernandez",
 					}).Return(errors.New("error creating user"))
 				},
 			},
 			expCode: http.StatusInternalServerError,
 		},
 		{
-			name:  "full flow",
-			token: "Bearer ABC",
+			name: "duplicated user",
+			input: request.UserRequest{
+				Name:     "diego",
+				NickName: "diegof",
+				Email:    "diego@gmail.com",
+				LastName: "fernandez",
+			},
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("Create", mock.Anything, request.UserRequest{
+						Name:     "diego",
+						NickName: "diegof",
+						Email:    "diego@gmail.com",
+						LastName: "fernandez",
+					}).Return(apperror.Conflict("email", "a user with email diego@gmail.com already exists"))
+				},
+			},
+			expCode: http.StatusConflict,
+			expFields: []apiErrors.FieldError{
+				{Field: "email", Rule: "unique"},
+			},
+		},
+		{
+			name: "full flow",
 			input: request.UserRequest{
-				Name:     "a",
-				NickName: "c",
-				Email:    "d",
-				LastName: "e",
+				Name:     "diego",
+				NickName: "diegof",
+				Email:    "diego@gmail.com",
+				LastName: "fernandez",
 			},
 			mocks: userMocks{
 				userHandler: func(f *mockUserHandler) {
-					f.userService.Mock.On("Create", mock.Anything, "ABC", request.UserRequest{
-						Name:     "a",
-						NickName: "c",
-						Email:    "d",
-						LastName: "e",
+					f.userService.Mock.On("Create", mock.Anything, request.UserRequest{
+						Name:     "diego",
+						NickName: "diegof",
+						Email:    "diego@gmail.com",
+						LastName: "fernandez",
 					}).Return(nil)
 				},
 			},
@@ -241,56 +543,109 @@ func Test_Create(t *testing.T) {
 			res := httptest.NewRecorder()
 			b, _ := json.Marshal(tc.input)
 			req := httptest.NewRequest(http.MethodPost, url, io.NopCloser(bytes.NewBuffer(b)))
-			if tc.token != "" {
-				req.Header.Set("Authorization", tc.token)
-			}
 			engine.ServeHTTP(res, req)
 			assert.Equal(t, tc.expCode, res.Code)
+			if tc.expFields != nil {
+				var apiErr apiErrors.ApiErrors
+				assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &apiErr))
+				assert.Equal(t, tc.expFields, apiErr.Fields)
+			}
 		})
 	}
 }
 
-func Test_Delete(t *testing.T) {
+func Test_Update(t *testing.T) {
+	validation.Register()
 	tests := []struct {
+		input   interface{}
 		mocks   userMocks
 		name    string
+		method  string
+		ifMatch string
 		userID  string
-		token   string
 		expCode int
 	}{
 		{
-			name: "error get users",
+			name:   "nick name isnt present",
+			method: http.MethodPut,
 			mocks: userMocks{
 				userHandler: func(f *mockUserHandler) {},
 			},
-			expCode: http.StatusUnauthorized,
+			expCode: http.StatusBadRequest,
 		},
 		{
-			name:  "nick name isnt present",
-			token: "Bearer ABC",
+			name:   "error on input",
+			method: http.MethodPut,
+			userID: "diegof",
+			input:  "ABC",
 			mocks: userMocks{
 				userHandler: func(f *mockUserHandler) {},
 			},
 			expCode: http.StatusBadRequest,
 		},
 		{
-			name:   "nick name not found",
-			token:  "Bearer ABC",
-			userID: "abc",
+			name:   "invalid email",
+			method: http.MethodPatch,
+			userID: "diegof",
+			input: request.UpdateUserRequest{
+				Email: gocloak.StringP("diego"),
+			},
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "stale etag",
+			method:  http.MethodPatch,
+			userID:  "diegof",
+			ifMatch: `"stale"`,
+			input: request.UpdateUserRequest{
+				Name: gocloak.StringP("diego"),
+			},
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("Update", mock.Anything, "diegof", request.UpdateUserRequest{
+						Name: gocloak.StringP("diego"),
+					}, true, `"stale"`).Return(response.UserResponse{}, apperror.New(http.StatusPreconditionFailed, "user diegof was modified"))
+				},
+			},
+			expCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:   "error updating user",
+			method: http.MethodPut,
+			userID: "diegof",
+			input: request.UpdateUserRequest{
+				Name: gocloak.StringP("diego"),
+			},
 			mocks: userMocks{
 				userHandler: func(f *mockUserHandler) {
-					f.userService.Mock.On("Delete", mock.Anything, "ABC", "abc").Return("", errors.New("nick name not found"))
+					f.userService.Mock.On("Update", mock.Anything, "diegof", request.UpdateUserRequest{
+						Name: gocloak.StringP("diego"),
+					}, false, "").Return(response.UserResponse{}, errors.New("some error"))
 				},
 			},
 			expCode: http.StatusInternalServerError,
 		},
 		{
 			name:   "full flow",
-			token:  "Bearer ABC",
-			userID: "abc",
+			method: http.MethodPut,
+			userID: "diegof",
+			input: request.UpdateUserRequest{
+				Name:     gocloak.StringP("diego"),
+				LastName: gocloak.StringP("fernandez"),
+				Email:    gocloak.StringP("diego@gmail.com"),
+			},
 			mocks: userMocks{
 				userHandler: func(f *mockUserHandler) {
-					f.userService.Mock.On("Delete", mock.Anything, "ABC", "abc").Return("test", nil)
+					f.userService.Mock.On("Update", mock.Anything, "diegof", request.UpdateUserRequest{
+						Name:     gocloak.StringP("diego"),
+						LastName: gocloak.StringP("fernandez"),
+						Email:    gocloak.StringP("diego@gmail.com"),
+					}, false, "").Return(response.UserResponse{
+						NickName: "diegof",
+					}, nil)
 				},
 			},
 			expCode: http.StatusOK,
@@ -303,21 +658,828 @@ func Test_Delete(t *testing.T) {
 			}
 			tc.mocks.userHandler(ms)
 			handler := NewUserHandler(ms.userService)
-			_, engine := gin.CreateTestContext(httptest.NewRecorder())
 			url := "/users"
-			engine.DELETE(url, func(ctx *gin.Context) {
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.Handle(tc.method, url, func(ctx *gin.Context) {
 				if tc.userID != "" {
 					ctx.AddParam("code", tc.userID)
 				}
-				handler.Delete(ctx)
+				if tc.method == http.MethodPatch {
+					handler.Patch(ctx)
+				} else {
+					handler.Update(ctx)
+				}
 			})
 			res := httptest.NewRecorder()
-			req := httptest.NewRequest(http.MethodDelete, url, nil)
-			if tc.token != "" {
-				req.Header.Set("Authorization", tc.token)
+			b, _ := json.Marshal(tc.input)
+			req := httptest.NewRequest(tc.method, url, io.NopCloser(bytes.NewBuffer(b)))
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
 			}
 			engine.ServeHTTP(res, req)
 			assert.Equal(t, tc.expCode, res.Code)
+			if tc.expCode == http.StatusOK {
+				assert.NotEmpty(t, res.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func Test_SetEnabled(t *testing.T) {
+	tests := []struct {
+		mocks   userMocks
+		name    string
+		userID  string
+		expCode int
+		enabled bool
+	}{
+		{
+			name: "nick name isnt present",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:   "error disabling user",
+			userID: "diegof",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("SetEnabled", mock.Anything, "diegof", false).Return(response.UserResponse{}, errors.New("some error"))
+				},
+			},
+			expCode: http.StatusInternalServerError,
+		},
+		{
+			name:   "disable",
+			userID: "diegof",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("SetEnabled", mock.Anything, "diegof", false).Return(response.UserResponse{NickName: "diegof"}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:    "enable",
+			userID:  "diegof",
+			enabled: true,
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("SetEnabled", mock.Anything, "diegof", true).Return(response.UserResponse{NickName: "diegof", Enabled: true}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockUserHandler{
+				&mocks.IUserService{},
+			}
+			tc.mocks.userHandler(ms)
+			handler := NewUserHandler(ms.userService)
+			url := "/users"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.POST(url, func(ctx *gin.Context) {
+				if tc.userID != "" {
+					ctx.AddParam("code", tc.userID)
+				}
+				if tc.enabled {
+					handler.Enable(ctx)
+				} else {
+					handler.Disable(ctx)
+				}
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, url, nil)
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
+
+func Test_ResetPassword(t *testing.T) {
+	validation.Register()
+	passwordRequest := request.PasswordRequest{Password: "new-password", Temporary: true}
+	tests := []struct {
+		input   any
+		mocks   userMocks
+		name    string
+		userID  string
+		expCode int
+	}{
+		{
+			name:  "nick name isnt present",
+			input: passwordRequest,
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:   "error on input",
+			userID: "diegof",
+			input:  "ABC",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:   "password too short",
+			userID: "diegof",
+			input:  request.PasswordRequest{Password: "short"},
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "password rejected",
+			userID: "diegof",
+			input:  passwordRequest,
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("ResetPassword", mock.Anything, "diegof", passwordRequest).
+						Return(apperror.New(http.StatusBadRequest, "password rejected: invalidPasswordMinDigitsMessage"))
+				},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:   "full flow",
+			userID: "diegof",
+			input:  passwordRequest,
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("ResetPassword", mock.Anything, "diegof", passwordRequest).Return(nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockUserHandler{
+				&mocks.IUserService{},
+			}
+			tc.mocks.userHandler(ms)
+			handler := NewUserHandler(ms.userService)
+			url := "/users/password"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.POST(url, func(ctx *gin.Context) {
+				if tc.userID != "" {
+					ctx.AddParam("code", tc.userID)
+				}
+				handler.ResetPassword(ctx)
+			})
+			res := httptest.NewRecorder()
+			b, _ := json.Marshal(tc.input)
+			req := httptest.NewRequest(http.MethodPost, url, io.NopCloser(bytes.NewBuffer(b)))
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+			ms.userService.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Delete(t *testing.T) {
+	tests := []struct {
+		mocks   userMocks
+		name    string
+		userID  string
+		expBody string
+		expCode int
+	}{
+		{
+			name: "nick name isnt present",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:   "nick name not found",
+			userID: "abc",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("Delete", mock.Anything, "abc").Return("", errors.New("nick name not found"))
+				},
+			},
+			expCode: http.StatusInternalServerError,
+		},
+		{
+			name:   "blocked by teams",
+			userID: "abc",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("Delete", mock.Anything, "abc").Return("", &apperror.AppError{
+						Code:    http.StatusConflict,
+						Message: "user abc can't be deleted while owing debt to the teams xl",
+						Details: response.DeletionBlocked{Teams: []response.BlockingTeam{{Code: "xl", Debt: 100}}},
+					})
+				},
+			},
+			expBody: `{"details":{"teams":[{"code":"xl","debt":100}]},"message":"error deleting user: abc, err: user abc can't be deleted while owing debt to the teams xl","code":409}`,
+			expCode: http.StatusConflict,
+		},
+		{
+			name:   "full flow",
+			userID: "abc",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("Delete", mock.Anything, "abc").Return("test", nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockUserHandler{
+				&mocks.IUserService{},
+			}
+			tc.mocks.userHandler(ms)
+			handler := NewUserHandler(ms.userService)
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			url := "/users"
+			engine.DELETE(url, func(ctx *gin.Context) {
+				if tc.userID != "" {
+					ctx.AddParam("code", tc.userID)
+				}
+				handler.Delete(ctx)
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodDelete, url, nil)
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+			if tc.expBody != "" {
+				assert.JSONEq(t, tc.expBody, res.Body.String())
+			}
+		})
+	}
+}
+
+func Test_Attributes(t *testing.T) {
+	validation.Register()
+	tests := []struct {
+		mocks   userMocks
+		name    string
+		method  string
+		userID  string
+		body    string
+		expCode int
+	}{
+		{
+			name:   "nick name isnt present",
+			method: http.MethodGet,
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:   "attribute not set",
+			method: http.MethodGet,
+			userID: "diegof",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("GetAttribute", mock.Anything, "diegof", "locale").Return(response.AttributeResponse{}, apperror.New(http.StatusNotFound, "user diegof has no attribute locale"))
+				},
+			},
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:   "get",
+			method: http.MethodGet,
+			userID: "diegof",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("GetAttribute", mock.Anything, "diegof", "locale").Return(response.AttributeResponse{Key: "locale", Value: "es"}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "set without value",
+			method: http.MethodPut,
+			userID: "diegof",
+			body:   `{}`,
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "set invalid value",
+			method: http.MethodPut,
+			userID: "diegof",
+			body:   `{"value": " "}`,
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("SetAttribute", mock.Anything, "diegof", "locale", " ").Return(response.AttributeResponse{}, apperror.New(http.StatusUnprocessableEntity, "attribute locale must be a string"))
+				},
+			},
+			expCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "set",
+			method: http.MethodPut,
+			userID: "diegof",
+			body:   `{"value": "es"}`,
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("SetAttribute", mock.Anything, "diegof", "locale", "es").Return(response.AttributeResponse{Key: "locale", Value: "es"}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "error deleting",
+			method: http.MethodDelete,
+			userID: "diegof",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("DeleteAttribute", mock.Anything, "diegof", "locale").Return(errors.New("some error"))
+				},
+			},
+			expCode: http.StatusInternalServerError,
+		},
+		{
+			name:   "delete",
+			method: http.MethodDelete,
+			userID: "diegof",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("DeleteAttribute", mock.Anything, "diegof", "locale").Return(nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockUserHandler{
+				&mocks.IUserService{},
+			}
+			tc.mocks.userHandler(ms)
+			handler := NewUserHandler(ms.userService)
+			url := "/users/attributes"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.Handle(tc.method, url, func(ctx *gin.Context) {
+				if tc.userID != "" {
+					ctx.AddParam("code", tc.userID)
+				}
+				ctx.AddParam("key", "locale")
+				switch tc.method {
+				case http.MethodGet:
+					handler.GetAttribute(ctx)
+				case http.MethodPut:
+					handler.SetAttribute(ctx)
+				default:
+					handler.DeleteAttribute(ctx)
+				}
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, url, strings.NewReader(tc.body))
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+			ms.userService.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Import(t *testing.T) {
+	diegof := request.UserRequest{
+		Name:     "diego",
+		LastName: "fernandez",
+		Email:    "diegof@gmail.com",
+		NickName: "diegof",
+	}
+	tests := []struct {
+		mocks       userMocks
+		name        string
+		query       string
+		contentType string
+		body        string
+		expCode     int
+		maxBytes    int64
+	}{
+		{
+			name:        "body too large",
+			contentType: "text/csv",
+			body:        "name,last_name,email,nick_name\ndiego,fernandez,diegof@gmail.com,diegof\n",
+			maxBytes:    32,
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:        "invalid json",
+			contentType: "application/json",
+			body:        `{"nick_name": "diegof"}`,
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:        "invalid csv",
+			contentType: "text/csv",
+			body:        "nick_name,age\ndiegof,30\n",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:        "error importing",
+			contentType: "application/json",
+			body:        `[]`,
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("Import", mock.Anything, []request.UserRequest{}, false).Return(response.ImportResponse{}, apperror.New(http.StatusBadRequest, "there are no users to import"))
+				},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:        "json",
+			contentType: "application/json",
+			body:        `[{"name": "diego", "last_name": "fernandez", "email": "diegof@gmail.com", "nick_name": "diegof"}]`,
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("Import", mock.Anything, []request.UserRequest{diegof}, false).Return(response.ImportResponse{Created: 1}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:        "csv dry run",
+			query:       "?dry_run=true",
+			contentType: "text/csv; charset=utf-8",
+			body:        "name,last_name,email,nick_name\ndiego,fernandez,diegof@gmail.com,diegof\n",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("Import", mock.Anything, []request.UserRequest{diegof}, true).Return(response.ImportResponse{Created: 1, DryRun: true}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockUserHandler{
+				&mocks.IUserService{},
+			}
+			tc.mocks.userHandler(ms)
+			handler := NewUserHandler(ms.userService)
+			if tc.maxBytes > 0 {
+				handler.(*userHandler).importMaxBytes = tc.maxBytes
+			}
+			url := "/users/import"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.POST(url, handler.Import)
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, url+tc.query, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+			ms.userService.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Export(t *testing.T) {
+	tests := []struct {
+		mocks          userMocks
+		name           string
+		query          string
+		expContentType string
+		expBody        string
+		expCode        int
+		expAborted     bool
+	}{
+		{
+			name:  "invalid query",
+			query: "?enabled=maybe",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:  "error before streaming",
+			query: "?format=xml",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("Export", mock.Anything, request.ExportQuery{Format: "xml"}, mock.Anything).Return(apperror.New(http.StatusBadRequest, "can't export users as xml"))
+				},
+			},
+			expContentType: "application/json; charset=utf-8",
+			expCode:        http.StatusBadRequest,
+		},
+		{
+			name:  "error while streaming",
+			query: "?format=ndjson",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("Export", mock.Anything, request.ExportQuery{Format: "ndjson"}, mock.Anything).
+						Run(func(args mock.Arguments) {
+							_, _ = args.Get(2).(io.Writer).Write([]byte("{}\n"))
+						}).
+						Return(errors.New("some error"))
+				},
+			},
+			expAborted: true,
+		},
+		{
+			name:  "csv",
+			query: "?search=diego&expand=roles,teams",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					query := request.ExportQuery{
+						UserFilter: request.UserFilter{Search: "diego"},
+						Expand:     []string{"roles", "teams"},
+					}
+					f.userService.Mock.On("Export", mock.Anything, query, mock.Anything).
+						Run(func(args mock.Arguments) {
+							_, _ = args.Get(2).(io.Writer).Write([]byte("id,nick_name\n"))
+						}).
+						Return(nil)
+				},
+			},
+			expContentType: "text/csv",
+			expBody:        "id,nick_name\n",
+			expCode:        http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockUserHandler{
+				&mocks.IUserService{},
+			}
+			tc.mocks.userHandler(ms)
+			handler := NewUserHandler(ms.userService)
+			url := "/users/export"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.GET(url, handler.Export)
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, url+tc.query, nil)
+			if tc.expAborted {
+				assert.PanicsWithValue(t, http.ErrAbortHandler, func() { engine.ServeHTTP(res, req) })
+				ms.userService.AssertExpectations(t)
+				return
+			}
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+			if tc.expContentType != "" {
+				assert.Equal(t, tc.expContentType, res.Header().Get("Content-Type"))
+			}
+			if tc.expBody != "" {
+				assert.Equal(t, tc.expBody, res.Body.String())
+			}
+			ms.userService.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_GetOffboarding(t *testing.T) {
+	tests := []struct {
+		mocks   userMocks
+		name    string
+		userID  string
+		expCode int
+	}{
+		{
+			name: "nick name isnt present",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:   "never offboarded",
+			userID: "diegof",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("GetOffboarding", mock.Anything, "diegof").Return(response.OffboardingResponse{}, apperror.New(http.StatusNotFound, "user diegof was never offboarded"))
+				},
+			},
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:   "full flow",
+			userID: "diegof",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("GetOffboarding", mock.Anything, "diegof").Return(response.OffboardingResponse{NickName: "diegof", Status: "completed"}, nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockUserHandler{
+				&mocks.IUserService{},
+			}
+			tc.mocks.userHandler(ms)
+			handler := NewUserHandler(ms.userService)
+			url := "/users/offboarding"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.GET(url, func(ctx *gin.Context) {
+				if tc.userID != "" {
+					ctx.AddParam("code", tc.userID)
+				}
+				handler.GetOffboarding(ctx)
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
+
+func Test_Register(t *testing.T) {
+	validation.Register()
+	registerRequest := request.RegisterRequest{
+		Name:     "diego",
+		LastName: "fernandez",
+		Email:    "diego@gmail.com",
+		NickName: "diegof",
+		Password: "secret-password",
+	}
+	tests := []struct {
+		input     interface{}
+		mocks     userMocks
+		name      string
+		expFields []apiErrors.FieldError
+		expCode   int
+	}{
+		{
+			name:  "error on input",
+			input: "ABC",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid fields",
+			input: request.RegisterRequest{
+				Name:     "diego",
+				LastName: "fernandez",
+				Email:    "diego@gmail.com",
+				NickName: "admin",
+				Password: "short",
+			},
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			expCode: http.StatusUnprocessableEntity,
+			expFields: []apiErrors.FieldError{
+				{Field: "nick_name", Rule: "notreserved"},
+				{Field: "password", Rule: "min", Param: "8"},
+			},
+		},
+		{
+			name:  "email domain not allowed",
+			input: registerRequest,
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("Register", mock.Anything, registerRequest).
+						Return(response.RegistrationResponse{}, apperror.New(http.StatusForbidden, "email domain gmail.com can't register"))
+				},
+			},
+			expCode: http.StatusForbidden,
+		},
+		{
+			name:  "full flow",
+			input: registerRequest,
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("Register", mock.Anything, registerRequest).
+						Return(response.RegistrationResponse{NickName: "diegof", Status: response.RegistrationVerifyEmail}, nil)
+				},
+			},
+			expCode: http.StatusCreated,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockUserHandler{
+				&mocks.IUserService{},
+			}
+			tc.mocks.userHandler(ms)
+			handler := NewUserHandler(ms.userService)
+			url := "/auth/register"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.POST(url, func(ctx *gin.Context) {
+				handler.Register(ctx)
+			})
+			res := httptest.NewRecorder()
+			b, _ := json.Marshal(tc.input)
+			req := httptest.NewRequest(http.MethodPost, url, io.NopCloser(bytes.NewBuffer(b)))
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+			if tc.expFields != nil {
+				var apiErr apiErrors.ApiErrors
+				assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &apiErr))
+				assert.Equal(t, tc.expFields, apiErr.Fields)
+			}
+			ms.userService.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Registrations(t *testing.T) {
+	tests := []struct {
+		mocks   userMocks
+		name    string
+		userID  string
+		approve bool
+		expCode int
+	}{
+		{
+			name: "nick name is required",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {},
+			},
+			approve: true,
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:   "user not waiting for approval",
+			userID: "diegof",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("ApproveRegistration", mock.Anything, "diegof").
+						Return(response.UserResponse{}, apperror.New(http.StatusNotFound, "user diegof isn't waiting for approval"))
+				},
+			},
+			approve: true,
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:   "approve",
+			userID: "diegof",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("ApproveRegistration", mock.Anything, "diegof").Return(response.UserResponse{NickName: "diegof", Enabled: true}, nil)
+				},
+			},
+			approve: true,
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "error rejecting",
+			userID: "diegof",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("RejectRegistration", mock.Anything, "diegof").Return(errors.New("some error"))
+				},
+			},
+			expCode: http.StatusInternalServerError,
+		},
+		{
+			name:   "reject",
+			userID: "diegof",
+			mocks: userMocks{
+				userHandler: func(f *mockUserHandler) {
+					f.userService.Mock.On("RejectRegistration", mock.Anything, "diegof").Return(nil)
+				},
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockUserHandler{
+				&mocks.IUserService{},
+			}
+			tc.mocks.userHandler(ms)
+			handler := NewUserHandler(ms.userService)
+			url := "/users/registrations"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.POST(url, func(ctx *gin.Context) {
+				if tc.userID != "" {
+					ctx.AddParam("code", tc.userID)
+				}
+				if tc.approve {
+					handler.ApproveRegistration(ctx)
+				} else {
+					handler.RejectRegistration(ctx)
+				}
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, url, nil)
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+			ms.userService.AssertExpectations(t)
 		})
 	}
 }
@@ -1,7 +1,11 @@
 package server
 
 import (
+	"net/http"
+
+	"cow_sso/api/validation"
 	"cow_sso/middleware"
+	"cow_sso/pkg/config"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,9 +17,36 @@ type server struct {
 func New(
 	metricMiddleWare middleware.IMetricMiddleWare,
 ) *gin.Engine {
-	r := gin.Default()
+	validation.Register()
+	r := gin.New()
+	r.Use(gin.Logger(), gin.CustomRecovery(recovery))
+	if err := r.SetTrustedProxies(trustedProxies()); err != nil {
+		panic(err)
+	}
 	r.GET("/metrics", metricMiddleWare.DefaultMetrics)
 
 	r.Use(metricMiddleWare.PersonalMetrics)
 	return r
 }
+
+// recovery answers panics with a 500, except http.ErrAbortHandler, which is panicked again so
+// net/http drops the connection. Handlers streaming a response use it to fail one already started,
+// so the client sees it cut short instead of a 200 that looks complete.
+func recovery(c *gin.Context, err any) {
+	if err == http.ErrAbortHandler {
+		panic(err)
+	}
+	c.AbortWithStatus(http.StatusInternalServerError)
+}
+
+// trustedProxies reads server.trusted-proxies. Only requests coming from those addresses can set
+// the client ip through X-Forwarded-For, otherwise anyone could dodge the per ip rate limits.
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range config.Get().UList("server.trusted-proxies") {
+		if address, ok := proxy.(string); ok {
+			proxies = append(proxies, address)
+		}
+	}
+	return proxies
+}
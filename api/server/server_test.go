@@ -0,0 +1,97 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"cow_sso/middleware"
+	"cow_sso/mocks"
+	"cow_sso/pkg/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_ClientIP(t *testing.T) {
+	tests := []struct {
+		name     string
+		proxies  []interface{}
+		expIP    string
+		expCodes []int
+	}{
+		{
+			name:     "spoofed X-Forwarded-For is ignored",
+			expIP:    "10.0.0.1",
+			expCodes: []int{200, 200, 200, 200, 200, 429},
+		},
+		{
+			name:     "trusted proxy",
+			proxies:  []interface{}{"10.0.0.1"},
+			expIP:    "1.1.1.6",
+			expCodes: []int{200, 200, 200, 200, 200, 200},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			configured := config.Get().UList("server.trusted-proxies")
+			defer func() { _ = config.Get().Set("server.trusted-proxies", configured) }()
+			_ = config.Get().Set("server.trusted-proxies", tc.proxies)
+
+			metrics := &mocks.IMetricMiddleWare{}
+			metrics.Mock.On("PersonalMetrics", mock.Anything).Run(func(args mock.Arguments) {
+				args.Get(0).(*gin.Context).Next()
+			})
+			engine := New(metrics)
+			var clientIP string
+			engine.POST("/auth/register", middleware.NewRateLimitMiddleWare().Limit("register"), func(c *gin.Context) {
+				clientIP = c.ClientIP()
+				c.Status(http.StatusOK)
+			})
+
+			for i, expCode := range tc.expCodes {
+				res := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodPost, "/auth/register", nil)
+				req.RemoteAddr = "10.0.0.1:1234"
+				req.Header.Set("X-Forwarded-For", "1.1.1."+strconv.Itoa(i+1))
+				engine.ServeHTTP(res, req)
+				assert.Equal(t, expCode, res.Code, "request %d", i)
+			}
+			assert.Equal(t, tc.expIP, clientIP)
+		})
+	}
+}
+
+func Test_recovery(t *testing.T) {
+	metrics := &mocks.IMetricMiddleWare{}
+	metrics.Mock.On("PersonalMetrics", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*gin.Context).Next()
+	})
+	engine := New(metrics)
+	engine.GET("/panic", func(c *gin.Context) {
+		panic("some error")
+	})
+	engine.GET("/abort", func(c *gin.Context) {
+		c.Writer.Header().Set("Content-Type", "text/csv")
+		_, _ = c.Writer.Write([]byte("id,nick_name\n"))
+		c.Writer.Flush()
+		panic(http.ErrAbortHandler)
+	})
+	srv := httptest.NewServer(engine)
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL + "/panic")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	_ = res.Body.Close()
+
+	res, err = http.Get(srv.URL + "/abort")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	_, err = io.ReadAll(res.Body)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_ = res.Body.Close()
+}
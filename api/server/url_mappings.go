@@ -3,26 +3,40 @@ package server
 import (
 	"cow_sso/api/handlers"
 	"cow_sso/api/handlers/auth"
+	"cow_sso/api/handlers/group"
+	"cow_sso/api/handlers/role"
 	"cow_sso/api/handlers/user"
+	"cow_sso/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Router struct {
-	pingHandler handlers.IPingHandler
-	authHandler auth.IAuthHandler
-	userHandler user.IUserHandler
+	pingHandler         handlers.IPingHandler
+	authHandler         auth.IAuthHandler
+	userHandler         user.IUserHandler
+	roleHandler         role.IRoleHandler
+	groupHandler        group.IGroupHandler
+	authMiddleWare      middleware.IAuthMiddleWare
+	rateLimitMiddleWare middleware.IRateLimitMiddleWare
 }
 
 func NewRouter(pingHandler handlers.IPingHandler,
 	authHandler auth.IAuthHandler,
 	userHandler user.IUserHandler,
-
+	roleHandler role.IRoleHandler,
+	groupHandler group.IGroupHandler,
+	authMiddleWare middleware.IAuthMiddleWare,
+	rateLimitMiddleWare middleware.IRateLimitMiddleWare,
 ) *Router {
 	return &Router{
 		pingHandler,
 		authHandler,
 		userHandler,
+		roleHandler,
+		groupHandler,
+		authMiddleWare,
+		rateLimitMiddleWare,
 	}
 }
 
@@ -32,13 +46,57 @@ func (r Router) Resource(gin *gin.Engine) {
 	{
 		auth.POST("/login", r.authHandler.Login)
 		auth.POST("/logout", r.authHandler.Logout)
+		auth.POST("/refresh", r.authHandler.Refresh)
 		auth.POST("/valid-token", r.authHandler.IsValidToken)
+		auth.GET("/userinfo", r.authHandler.UserInfo)
+		auth.POST("/register", r.rateLimitMiddleWare.Limit("register"), r.userHandler.Register)
+		auth.POST("/password/change", r.authMiddleWare.Authenticate, r.rateLimitMiddleWare.Limit("password"), r.authHandler.ChangePassword)
+		auth.POST("/password/forgot", r.rateLimitMiddleWare.Limit("password"), r.authHandler.ForgotPassword)
+	}
+	// public, so signup forms can check a nick name or email before submitting, and rate limited
+	// so it can't be used to go through lists of emails looking for accounts
+	gin.GET("/users/availability", r.rateLimitMiddleWare.Limit("availability"), r.userHandler.Availability)
+	user := gin.Group("/users", r.authMiddleWare.Authenticate)
+	{
+		user.GET("", r.authMiddleWare.Authorize("users.read"), r.userHandler.GetAll)
+		user.GET("/export", r.authMiddleWare.Authorize("users.export"), r.userHandler.Export)
+		user.GET("/:code", r.authMiddleWare.Authorize("users.read"), r.userHandler.GetByNickName)
+		user.GET("/id/:id", r.authMiddleWare.Authorize("users.read"), r.userHandler.GetByID)
+		user.GET("/email/:email", r.authMiddleWare.Authorize("users.read"), r.userHandler.GetByEmail)
+		user.POST("", r.authMiddleWare.Authorize("users.create"), r.userHandler.Create)
+		user.POST("/import", r.authMiddleWare.Authorize("users.create"), r.userHandler.Import)
+		user.GET("/registrations", r.authMiddleWare.Authorize("users.create"), r.userHandler.GetRegistrations)
+		user.POST("/registrations/:code/approve", r.authMiddleWare.Authorize("users.create"), r.userHandler.ApproveRegistration)
+		user.DELETE("/registrations/:code", r.authMiddleWare.Authorize("users.create"), r.userHandler.RejectRegistration)
+		user.PUT("/:code", r.authMiddleWare.Authorize("users.update"), r.userHandler.Update)
+		user.PATCH("/:code", r.authMiddleWare.Authorize("users.update"), r.userHandler.Patch)
+		user.POST("/:code/enable", r.authMiddleWare.Authorize("users.update"), r.userHandler.Enable)
+		user.POST("/:code/disable", r.authMiddleWare.Authorize("users.update"), r.userHandler.Disable)
+		user.POST("/:code/password", r.authMiddleWare.Authorize("users.update"), r.userHandler.ResetPassword)
+		user.DELETE("/:code", r.authMiddleWare.Authorize("users.delete"), r.userHandler.Delete)
+		user.GET("/:code/offboarding", r.authMiddleWare.Authorize("users.delete"), r.userHandler.GetOffboarding)
+		user.GET("/:code/attributes/:key", r.authMiddleWare.Authorize("users.read"), r.userHandler.GetAttribute)
+		user.PUT("/:code/attributes/:key", r.authMiddleWare.Authorize("users.update"), r.userHandler.SetAttribute)
+		user.DELETE("/:code/attributes/:key", r.authMiddleWare.Authorize("users.update"), r.userHandler.DeleteAttribute)
+		user.GET("/:code/roles", r.authMiddleWare.Authorize("roles.read"), r.roleHandler.GetByUser)
+		user.POST("/:code/roles", r.authMiddleWare.Authorize("roles.grant"), r.roleHandler.Grant)
+		user.DELETE("/:code/roles/:role", r.authMiddleWare.Authorize("roles.revoke"), r.roleHandler.Revoke)
+		user.GET("/:code/groups", r.authMiddleWare.Authorize("groups.read"), r.groupHandler.GetByUser)
+	}
+	role := gin.Group("/roles", r.authMiddleWare.Authenticate)
+	{
+		role.GET("", r.authMiddleWare.Authorize("roles.read"), r.roleHandler.GetAll)
 	}
-	user := gin.Group("/users")
+	group := gin.Group("/groups", r.authMiddleWare.Authenticate)
 	{
-		user.GET("", r.userHandler.GetAll)
-		user.GET("/:code", r.userHandler.GetByNickName)
-		user.POST("", r.userHandler.Create)
-		user.DELETE("/:code", r.userHandler.Delete)
+		group.GET("", r.authMiddleWare.Authorize("groups.read"), r.groupHandler.GetAll)
+		group.GET("/:id", r.authMiddleWare.Authorize("groups.read"), r.groupHandler.GetByID)
+		group.POST("", r.authMiddleWare.Authorize("groups.create"), r.groupHandler.Create)
+		group.POST("/:id/children", r.authMiddleWare.Authorize("groups.create"), r.groupHandler.CreateChild)
+		group.PUT("/:id", r.authMiddleWare.Authorize("groups.update"), r.groupHandler.Update)
+		group.DELETE("/:id", r.authMiddleWare.Authorize("groups.delete"), r.groupHandler.Delete)
+		group.GET("/:id/members", r.authMiddleWare.Authorize("groups.read"), r.groupHandler.GetMembers)
+		group.PUT("/:id/members/:code", r.authMiddleWare.Authorize("groups.update"), r.groupHandler.AddMember)
+		group.DELETE("/:id/members/:code", r.authMiddleWare.Authorize("groups.update"), r.groupHandler.RemoveMember)
 	}
 }
@@ -0,0 +1,77 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	apiErrors "cow_sso/api/handlers/errors"
+	"cow_sso/pkg/config"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// _nickNamePattern keeps nick names lowercase, as keycloak stores usernames, and free of spaces.
+var _nickNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+var once sync.Once
+
+// Register adds the custom rules to gin's validator and makes it report fields by their json name.
+// It can be called any number of times.
+func Register() {
+	once.Do(func() {
+		validate, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+		_ = validate.RegisterValidation("notblank", notBlank)
+		_ = validate.RegisterValidation("nickname", nickName)
+		_ = validate.RegisterValidation("notreserved", notReserved)
+	})
+}
+
+// Fields lists the rules err broke, reporting false when err isn't a validation error.
+func Fields(err error) ([]apiErrors.FieldError, bool) {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil, false
+	}
+	fields := make([]apiErrors.FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields = append(fields, apiErrors.FieldError{
+			Field: fieldErr.Field(),
+			Rule:  fieldErr.Tag(),
+			Param: fieldErr.Param(),
+		})
+	}
+	return fields, true
+}
+
+func notBlank(fl validator.FieldLevel) bool {
+	return strings.TrimSpace(fl.Field().String()) != ""
+}
+
+func nickName(fl validator.FieldLevel) bool {
+	return _nickNamePattern.MatchString(fl.Field().String())
+}
+
+// notReserved rejects the names listed under validation.reserved-nick-names, whatever their case.
+func notReserved(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	for _, reserved := range config.Get().UList("validation.reserved-nick-names") {
+		if name, ok := reserved.(string); ok && strings.EqualFold(name, value) {
+			return false
+		}
+	}
+	return true
+}
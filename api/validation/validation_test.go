@@ -0,0 +1,84 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	apiErrors "cow_sso/api/handlers/errors"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+type signUp struct {
+	Name     string `json:"name" binding:"required,notblank"`
+	NickName string `json:"nick_name" binding:"required,min=3,max=30,nickname,notreserved"`
+	Ignored  string `json:"-" binding:"omitempty,email"`
+}
+
+func Test_Fields(t *testing.T) {
+	Register()
+	Register()
+
+	tests := []struct {
+		input     signUp
+		name      string
+		outPut    []apiErrors.FieldError
+		isInvalid bool
+	}{
+		{
+			name:  "valid",
+			input: signUp{Name: "diego", NickName: "diego.f_1-a"},
+		},
+		{
+			name:      "blank name",
+			input:     signUp{Name: "  ", NickName: "diegof"},
+			isInvalid: true,
+			outPut:    []apiErrors.FieldError{{Field: "name", Rule: "notblank"}},
+		},
+		{
+			name:      "nick name with upper case",
+			input:     signUp{Name: "diego", NickName: "DiegoF"},
+			isInvalid: true,
+			outPut:    []apiErrors.FieldError{{Field: "nick_name", Rule: "nickname"}},
+		},
+		{
+			name:      "nick name starting with a dot",
+			input:     signUp{Name: "diego", NickName: ".diegof"},
+			isInvalid: true,
+			outPut:    []apiErrors.FieldError{{Field: "nick_name", Rule: "nickname"}},
+		},
+		{
+			name:      "nick name too long",
+			input:     signUp{Name: "diego", NickName: "diegofernandezdiegofernandezdiegof"},
+			isInvalid: true,
+			outPut:    []apiErrors.FieldError{{Field: "nick_name", Rule: "max", Param: "30"}},
+		},
+		{
+			name:      "reserved nick name",
+			input:     signUp{Name: "diego", NickName: "root"},
+			isInvalid: true,
+			outPut:    []apiErrors.FieldError{{Field: "nick_name", Rule: "notreserved"}},
+		},
+		{
+			name:      "field without json name",
+			input:     signUp{Name: "diego", NickName: "diegof", Ignored: "diego"},
+			isInvalid: true,
+			outPut:    []apiErrors.FieldError{{Field: "Ignored", Rule: "email"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := binding.Validator.ValidateStruct(tt.input)
+			fields, ok := Fields(err)
+			assert.Equal(t, tt.isInvalid, ok)
+			assert.Equal(t, tt.outPut, fields)
+		})
+	}
+}
+
+func Test_FieldsOfOtherErrors(t *testing.T) {
+	fields, ok := Fields(errors.New("some error"))
+	assert.False(t, ok)
+	assert.Nil(t, fields)
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"cow_sso/api/handlers/errors"
+	"cow_sso/pkg/config"
+	"cow_sso/pkg/service/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+const _principalKey = "principal"
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	ClientRoles map[string][]string
+	Subject     string
+	Username    string
+	Token       string
+	RealmRoles  []string
+}
+
+type IAuthMiddleWare interface {
+	Authenticate(c *gin.Context)
+	Authorize(permission string) gin.HandlerFunc
+}
+
+type authMiddleWare struct {
+	authService auth.IAuthService
+	client      string
+}
+
+func NewAuthMiddleWare(authService auth.IAuthService) IAuthMiddleWare {
+	return &authMiddleWare{
+		authService: authService,
+		client:      config.Get().UString("keycloak.client"),
+	}
+}
+
+func (a *authMiddleWare) Authenticate(c *gin.Context) {
+	ctx := c.Request.Context()
+	header := c.GetHeader("Authorization")
+	if header == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errors.ApiErrors{
+			Code:    http.StatusUnauthorized,
+			Message: "token is required",
+		})
+		return
+	}
+
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" || strings.Contains(token, " ") {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errors.ApiErrors{
+			Code:    http.StatusUnauthorized,
+			Message: "invalid token format",
+		})
+		return
+	}
+
+	tokenResponse, err := a.authService.IntrospectToken(ctx, token)
+	if err != nil {
+		apiErr := errors.FromError(err, http.StatusInternalServerError)
+		c.AbortWithStatusJSON(apiErr.Code, apiErr)
+		return
+	}
+	if !tokenResponse.Active {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errors.ApiErrors{
+			Code:    http.StatusUnauthorized,
+			Message: "invalid token",
+		})
+		return
+	}
+
+	SetPrincipal(c, Principal{
+		Subject:     tokenResponse.Sub,
+		Username:    tokenResponse.Username,
+		RealmRoles:  tokenResponse.RealmRoles,
+		ClientRoles: tokenResponse.ClientRoles,
+		Token:       token,
+	})
+	c.Next()
+}
+
+// Authorize lets the request through only when the principal holds one of the realm or
+// client roles configured for permission under authorization in properties.yml.
+func (a *authMiddleWare) Authorize(permission string) gin.HandlerFunc {
+	var roles []string
+	for _, role := range config.Get().UList("authorization." + permission) {
+		if name, ok := role.(string); ok {
+			roles = append(roles, name)
+		}
+	}
+
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, errors.ApiErrors{
+				Code:    http.StatusUnauthorized,
+				Message: "token is required",
+			})
+			return
+		}
+
+		if !principal.HasAnyRole(a.client, roles...) {
+			c.AbortWithStatusJSON(http.StatusForbidden, errors.ApiErrors{
+				Code:    http.StatusForbidden,
+				Message: fmt.Sprintf("%s requires one of the roles: %s", permission, strings.Join(roles, ", ")),
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// HasAnyRole reports whether the principal holds any of roles as a realm role or as a role of client.
+func (p Principal) HasAnyRole(client string, roles ...string) bool {
+	for _, role := range roles {
+		if slices.Contains(p.RealmRoles, role) || slices.Contains(p.ClientRoles[client], role) {
+			return true
+		}
+	}
+	return false
+}
+
+func SetPrincipal(c *gin.Context, principal Principal) {
+	c.Set(_principalKey, principal)
+}
+
+// GetPrincipal returns the caller stored by Authenticate.
+func GetPrincipal(c *gin.Context) (Principal, bool) {
+	value, exists := c.Get(_principalKey)
+	if !exists {
+		return Principal{}, false
+	}
+	principal, ok := value.(Principal)
+	return principal, ok
+}
@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cow_sso/api/handlers/auth/response"
+	"cow_sso/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAuthMiddleWare struct {
+	authService *mocks.IAuthService
+}
+
+type authMocks struct {
+	authMiddleWare func(f *mockAuthMiddleWare)
+}
+
+func Test_Authenticate(t *testing.T) {
+	tests := []struct {
+		mocks         authMocks
+		expPrincipal  *Principal
+		name          string
+		authorization string
+		expCode       int
+	}{
+		{
+			name: "token is required",
+			mocks: authMocks{
+				authMiddleWare: func(f *mockAuthMiddleWare) {},
+			},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "header shorter than the scheme",
+			authorization: "Bear",
+			mocks: authMocks{
+				authMiddleWare: func(f *mockAuthMiddleWare) {},
+			},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "wrong scheme",
+			authorization: "Basic dXNlcjpwYXNz",
+			mocks: authMocks{
+				authMiddleWare: func(f *mockAuthMiddleWare) {},
+			},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "empty token",
+			authorization: "Bearer ",
+			mocks: authMocks{
+				authMiddleWare: func(f *mockAuthMiddleWare) {},
+			},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "error introspecting token",
+			authorization: "Bearer ABC",
+			mocks: authMocks{
+				authMiddleWare: func(f *mockAuthMiddleWare) {
+					f.authService.On("IntrospectToken", mock.Anything, "ABC").Return(response.TokenResponse{}, errors.New("some error"))
+				},
+			},
+			expCode: http.StatusInternalServerError,
+		},
+		{
+			name:          "inactive token",
+			authorization: "Bearer ABC",
+			mocks: authMocks{
+				authMiddleWare: func(f *mockAuthMiddleWare) {
+					f.authService.On("IntrospectToken", mock.Anything, "ABC").Return(response.TokenResponse{}, nil)
+				},
+			},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "full flow",
+			authorization: "bearer ABC",
+			mocks: authMocks{
+				authMiddleWare: func(f *mockAuthMiddleWare) {
+					f.authService.On("IntrospectToken", mock.Anything, "ABC").Return(response.TokenResponse{
+						Active:     true,
+						Sub:        "abcde8",
+						Username:   "diegof",
+						RealmRoles: []string{"user"},
+					}, nil)
+				},
+			},
+			expPrincipal: &Principal{
+				Subject:    "abcde8",
+				Username:   "diegof",
+				RealmRoles: []string{"user"},
+				Token:      "ABC",
+			},
+			expCode: http.StatusOK,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &mockAuthMiddleWare{
+				&mocks.IAuthService{},
+			}
+			tc.mocks.authMiddleWare(ms)
+			middleWare := NewAuthMiddleWare(ms.authService)
+			url := "/users"
+			var principal *Principal
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.GET(url, middleWare.Authenticate, func(ctx *gin.Context) {
+				if p, ok := GetPrincipal(ctx); ok {
+					principal = &p
+				}
+				ctx.Status(http.StatusOK)
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+			assert.Equal(t, tc.expPrincipal, principal)
+		})
+	}
+}
+
+func Test_Authorize(t *testing.T) {
+	tests := []struct {
+		principal  *Principal
+		name       string
+		permission string
+		expCode    int
+	}{
+		{
+			name:       "principal missing",
+			permission: "users.create",
+			expCode:    http.StatusUnauthorized,
+		},
+		{
+			name:       "role missing",
+			permission: "users.create",
+			principal: &Principal{
+				RealmRoles: []string{"user"},
+			},
+			expCode: http.StatusForbidden,
+		},
+		{
+			name:       "permission without configured roles",
+			permission: "users.unknown",
+			principal: &Principal{
+				RealmRoles: []string{"admin"},
+			},
+			expCode: http.StatusForbidden,
+		},
+		{
+			name:       "realm role",
+			permission: "users.create",
+			principal: &Principal{
+				RealmRoles: []string{"user", "admin"},
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:       "client role",
+			permission: "users.read",
+			principal: &Principal{
+				ClientRoles: map[string][]string{
+					"cow": {"user"},
+				},
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:       "role of another client",
+			permission: "users.read",
+			principal: &Principal{
+				ClientRoles: map[string][]string{
+					"account": {"user"},
+				},
+			},
+			expCode: http.StatusForbidden,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			middleWare := NewAuthMiddleWare(&mocks.IAuthService{})
+			url := "/users"
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.GET(url, func(ctx *gin.Context) {
+				if tc.principal != nil {
+					SetPrincipal(ctx, *tc.principal)
+				}
+			}, middleWare.Authorize(tc.permission), func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			})
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			engine.ServeHTTP(res, req)
+			assert.Equal(t, tc.expCode, res.Code)
+		})
+	}
+}
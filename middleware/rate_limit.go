@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"cow_sso/api/handlers/errors"
+	"cow_sso/pkg/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+const _defaultRateLimitWindow = time.Minute
+
+type IRateLimitMiddleWare interface {
+	// Limit lets each client ip make at most rate-limit.<name>.requests requests every
+	// rate-limit.<name>.window to the routes it guards, it limits nothing when requests isn't set.
+	Limit(name string) gin.HandlerFunc
+}
+
+type rateLimitMiddleWare struct {
+	now func() time.Time
+}
+
+func NewRateLimitMiddleWare() IRateLimitMiddleWare {
+	return &rateLimitMiddleWare{now: time.Now}
+}
+
+func (rl *rateLimitMiddleWare) Limit(name string) gin.HandlerFunc {
+	requests := config.Get().UInt(fmt.Sprintf("rate-limit.%s.requests", name))
+	window, err := time.ParseDuration(config.Get().UString(fmt.Sprintf("rate-limit.%s.window", name)))
+	if err != nil || window <= 0 {
+		window = _defaultRateLimitWindow
+	}
+	if requests <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	limiter := &windowLimiter{
+		requests: requests,
+		window:   window,
+		clients:  map[string]*clientWindow{},
+		now:      rl.now,
+	}
+	return func(c *gin.Context) {
+		if wait, ok := limiter.allow(c.ClientIP()); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, errors.ApiErrors{
+				Code:    http.StatusTooManyRequests,
+				Message: fmt.Sprintf("too many requests, try again in %s", wait.Round(time.Second)),
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// windowLimiter counts the requests of every client in fixed windows that start with the client's first request.
+type windowLimiter struct {
+	clients   map[string]*clientWindow
+	now       func() time.Time
+	lastSweep time.Time
+	window    time.Duration
+	requests  int
+	mu        sync.Mutex
+}
+
+type clientWindow struct {
+	start    time.Time
+	requests int
+}
+
+// allow counts a request of client, and when it's over the limit returns how long until the client's window ends.
+func (wl *windowLimiter) allow(client string) (time.Duration, bool) {
+	wl.mu.Lock()
+	defer wl.mu.Unlock()
+	now := wl.now()
+	wl.sweep(now)
+
+	current, ok := wl.clients[client]
+	if !ok || now.Sub(current.start) >= wl.window {
+		current = &clientWindow{start: now}
+		wl.clients[client] = current
+	}
+	if current.requests >= wl.requests {
+		return current.start.Add(wl.window).Sub(now), false
+	}
+	current.requests++
+	return 0, true
+}
+
+// sweep forgets, once per window, the clients whose window is over, so the map doesn't grow with every ip ever seen.
+func (wl *windowLimiter) sweep(now time.Time) {
+	if now.Sub(wl.lastSweep) < wl.window {
+		return
+	}
+	for client, current := range wl.clients {
+		if now.Sub(current.start) >= wl.window {
+			delete(wl.clients, client)
+		}
+	}
+	wl.lastSweep = now
+}
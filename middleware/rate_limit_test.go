@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Limit(t *testing.T) {
+	tests := []struct {
+		name       string
+		limit      string
+		wait       time.Duration
+		requests   []string
+		expCodes   []int
+		retryAfter string
+	}{
+		{
+			name:     "no limit configured",
+			limit:    "unknown",
+			requests: []string{"1.1.1.1", "1.1.1.1", "1.1.1.1", "1.1.1.1", "1.1.1.1", "1.1.1.1"},
+			expCodes: []int{200, 200, 200, 200, 200, 200},
+		},
+		{
+			name:       "over the limit",
+			limit:      "register",
+			requests:   []string{"1.1.1.1", "1.1.1.1", "1.1.1.1", "1.1.1.1", "1.1.1.1", "1.1.1.1", "2.2.2.2"},
+			expCodes:   []int{200, 200, 200, 200, 200, 429, 200},
+			retryAfter: "3600",
+		},
+		{
+			name:     "window over",
+			limit:    "register",
+			wait:     time.Hour,
+			requests: []string{"1.1.1.1", "1.1.1.1", "1.1.1.1", "1.1.1.1", "1.1.1.1", "1.1.1.1"},
+			expCodes: []int{200, 200, 200, 200, 200, 200},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			rateLimit := &rateLimitMiddleWare{now: func() time.Time { return now }}
+			_, engine := gin.CreateTestContext(httptest.NewRecorder())
+			engine.POST("/auth/register", rateLimit.Limit(tc.limit), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			var retryAfter string
+			for i, ip := range tc.requests {
+				if i == len(tc.requests)-1 {
+					now = now.Add(tc.wait)
+				}
+				res := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodPost, "/auth/register", nil)
+				req.RemoteAddr = ip + ":1234"
+				engine.ServeHTTP(res, req)
+				assert.Equal(t, tc.expCodes[i], res.Code, "request %d", i)
+				if res.Code == http.StatusTooManyRequests {
+					retryAfter = res.Header().Get("Retry-After")
+				}
+			}
+			assert.Equal(t, tc.retryAfter, retryAfter)
+		})
+	}
+}
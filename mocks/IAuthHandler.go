@@ -12,6 +12,16 @@ type IAuthHandler struct {
 	mock.Mock
 }
 
+// ChangePassword provides a mock function with given fields: c
+func (_m *IAuthHandler) ChangePassword(c *gin.Context) {
+	_m.Called(c)
+}
+
+// ForgotPassword provides a mock function with given fields: c
+func (_m *IAuthHandler) ForgotPassword(c *gin.Context) {
+	_m.Called(c)
+}
+
 // IsValidToken provides a mock function with given fields: c
 func (_m *IAuthHandler) IsValidToken(c *gin.Context) {
 	_m.Called(c)
@@ -27,6 +37,16 @@ func (_m *IAuthHandler) Logout(c *gin.Context) {
 	_m.Called(c)
 }
 
+// Refresh provides a mock function with given fields: c
+func (_m *IAuthHandler) Refresh(c *gin.Context) {
+	_m.Called(c)
+}
+
+// UserInfo provides a mock function with given fields: c
+func (_m *IAuthHandler) UserInfo(c *gin.Context) {
+	_m.Called(c)
+}
+
 // NewIAuthHandler creates a new instance of IAuthHandler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewIAuthHandler(t interface {
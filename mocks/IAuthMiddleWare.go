@@ -0,0 +1,53 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	gin "github.com/gin-gonic/gin"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IAuthMiddleWare is an autogenerated mock type for the IAuthMiddleWare type
+type IAuthMiddleWare struct {
+	mock.Mock
+}
+
+// Authenticate provides a mock function with given fields: c
+func (_m *IAuthMiddleWare) Authenticate(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Authorize provides a mock function with given fields: permission
+func (_m *IAuthMiddleWare) Authorize(permission string) gin.HandlerFunc {
+	ret := _m.Called(permission)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Authorize")
+	}
+
+	var r0 gin.HandlerFunc
+	if rf, ok := ret.Get(0).(func(string) gin.HandlerFunc); ok {
+		r0 = rf(permission)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(gin.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
+// NewIAuthMiddleWare creates a new instance of IAuthMiddleWare. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIAuthMiddleWare(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IAuthMiddleWare {
+	mock := &IAuthMiddleWare{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -5,10 +5,9 @@ package mocks
 import (
 	context "context"
 	request "cow_sso/api/handlers/auth/request"
+	response "cow_sso/api/handlers/auth/response"
 
 	mock "github.com/stretchr/testify/mock"
-
-	response "cow_sso/api/handlers/auth/response"
 )
 
 // IAuthService is an autogenerated mock type for the IAuthService type
@@ -16,23 +15,87 @@ type IAuthService struct {
 	mock.Mock
 }
 
-// IsValidToken provides a mock function with given fields: ctx, accessToken
-func (_m *IAuthService) IsValidToken(ctx context.Context, accessToken string) (bool, error) {
+// ChangePassword provides a mock function with given fields: ctx, userID, nickName, changeRequest
+func (_m *IAuthService) ChangePassword(ctx context.Context, userID string, nickName string, changeRequest request.ChangePasswordRequest) error {
+	ret := _m.Called(ctx, userID, nickName, changeRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangePassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, request.ChangePasswordRequest) error); ok {
+		r0 = rf(ctx, userID, nickName, changeRequest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ForgotPassword provides a mock function with given fields: ctx, forgotRequest
+func (_m *IAuthService) ForgotPassword(ctx context.Context, forgotRequest request.ForgotPasswordRequest) error {
+	ret := _m.Called(ctx, forgotRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ForgotPassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ForgotPasswordRequest) error); ok {
+		r0 = rf(ctx, forgotRequest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetUserInfo provides a mock function with given fields: ctx, accessToken
+func (_m *IAuthService) GetUserInfo(ctx context.Context, accessToken string) (response.UserInfoResponse, error) {
+	ret := _m.Called(ctx, accessToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserInfo")
+	}
+
+	var r0 response.UserInfoResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (response.UserInfoResponse, error)); ok {
+		return rf(ctx, accessToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) response.UserInfoResponse); ok {
+		r0 = rf(ctx, accessToken)
+	} else {
+		r0 = ret.Get(0).(response.UserInfoResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, accessToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IntrospectToken provides a mock function with given fields: ctx, accessToken
+func (_m *IAuthService) IntrospectToken(ctx context.Context, accessToken string) (response.TokenResponse, error) {
 	ret := _m.Called(ctx, accessToken)
 
 	if len(ret) == 0 {
-		panic("no return value specified for IsValidToken")
+		panic("no return value specified for IntrospectToken")
 	}
 
-	var r0 bool
+	var r0 response.TokenResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) (response.TokenResponse, error)); ok {
 		return rf(ctx, accessToken)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) response.TokenResponse); ok {
 		r0 = rf(ctx, accessToken)
 	} else {
-		r0 = ret.Get(0).(bool)
+		r0 = ret.Get(0).(response.TokenResponse)
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
@@ -90,6 +153,34 @@ func (_m *IAuthService) Logout(ctx context.Context, refreshTokenRequest request.
 	return r0
 }
 
+// RefreshToken provides a mock function with given fields: ctx, refreshTokenRequest
+func (_m *IAuthService) RefreshToken(ctx context.Context, refreshTokenRequest request.RefreshTokenRequest) (response.AuthResponse, error) {
+	ret := _m.Called(ctx, refreshTokenRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RefreshToken")
+	}
+
+	var r0 response.AuthResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.RefreshTokenRequest) (response.AuthResponse, error)); ok {
+		return rf(ctx, refreshTokenRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.RefreshTokenRequest) response.AuthResponse); ok {
+		r0 = rf(ctx, refreshTokenRequest)
+	} else {
+		r0 = ret.Get(0).(response.AuthResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.RefreshTokenRequest) error); ok {
+		r1 = rf(ctx, refreshTokenRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewIAuthService creates a new instance of IAuthService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewIAuthService(t interface {
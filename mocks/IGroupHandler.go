@@ -0,0 +1,78 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	gin "github.com/gin-gonic/gin"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IGroupHandler is an autogenerated mock type for the IGroupHandler type
+type IGroupHandler struct {
+	mock.Mock
+}
+
+// AddMember provides a mock function with given fields: c
+func (_m *IGroupHandler) AddMember(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Create provides a mock function with given fields: c
+func (_m *IGroupHandler) Create(c *gin.Context) {
+	_m.Called(c)
+}
+
+// CreateChild provides a mock function with given fields: c
+func (_m *IGroupHandler) CreateChild(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Delete provides a mock function with given fields: c
+func (_m *IGroupHandler) Delete(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetAll provides a mock function with given fields: c
+func (_m *IGroupHandler) GetAll(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetByID provides a mock function with given fields: c
+func (_m *IGroupHandler) GetByID(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetByUser provides a mock function with given fields: c
+func (_m *IGroupHandler) GetByUser(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetMembers provides a mock function with given fields: c
+func (_m *IGroupHandler) GetMembers(c *gin.Context) {
+	_m.Called(c)
+}
+
+// RemoveMember provides a mock function with given fields: c
+func (_m *IGroupHandler) RemoveMember(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Update provides a mock function with given fields: c
+func (_m *IGroupHandler) Update(c *gin.Context) {
+	_m.Called(c)
+}
+
+// NewIGroupHandler creates a new instance of IGroupHandler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIGroupHandler(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IGroupHandler {
+	mock := &IGroupHandler{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
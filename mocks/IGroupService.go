@@ -0,0 +1,270 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	request "cow_sso/api/handlers/group/request"
+	response "cow_sso/api/handlers/group/response"
+	userresponse "cow_sso/api/handlers/user/response"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IGroupService is an autogenerated mock type for the IGroupService type
+type IGroupService struct {
+	mock.Mock
+}
+
+// AddMember provides a mock function with given fields: ctx, groupID, nickName
+func (_m *IGroupService) AddMember(ctx context.Context, groupID string, nickName string) error {
+	ret := _m.Called(ctx, groupID, nickName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddMember")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, groupID, nickName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Create provides a mock function with given fields: ctx, parentID, groupRequest
+func (_m *IGroupService) Create(ctx context.Context, parentID string, groupRequest request.GroupRequest) (response.GroupResponse, error) {
+	ret := _m.Called(ctx, parentID, groupRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 response.GroupResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, request.GroupRequest) (response.GroupResponse, error)); ok {
+		return rf(ctx, parentID, groupRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, request.GroupRequest) response.GroupResponse); ok {
+		r0 = rf(ctx, parentID, groupRequest)
+	} else {
+		r0 = ret.Get(0).(response.GroupResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, request.GroupRequest) error); ok {
+		r1 = rf(ctx, parentID, groupRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, groupID
+func (_m *IGroupService) Delete(ctx context.Context, groupID string) (string, error) {
+	ret := _m.Called(ctx, groupID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+		return rf(ctx, groupID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, groupID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, groupID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAll provides a mock function with given fields: ctx, search
+func (_m *IGroupService) GetAll(ctx context.Context, search string) ([]response.GroupResponse, error) {
+	ret := _m.Called(ctx, search)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []response.GroupResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]response.GroupResponse, error)); ok {
+		return rf(ctx, search)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []response.GroupResponse); ok {
+		r0 = rf(ctx, search)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]response.GroupResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, search)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, groupID
+func (_m *IGroupService) GetByID(ctx context.Context, groupID string) (response.GroupResponse, error) {
+	ret := _m.Called(ctx, groupID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 response.GroupResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (response.GroupResponse, error)); ok {
+		return rf(ctx, groupID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) response.GroupResponse); ok {
+		r0 = rf(ctx, groupID)
+	} else {
+		r0 = ret.Get(0).(response.GroupResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, groupID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByUser provides a mock function with given fields: ctx, nickName
+func (_m *IGroupService) GetByUser(ctx context.Context, nickName string) ([]response.GroupResponse, error) {
+	ret := _m.Called(ctx, nickName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByUser")
+	}
+
+	var r0 []response.GroupResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]response.GroupResponse, error)); ok {
+		return rf(ctx, nickName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []response.GroupResponse); ok {
+		r0 = rf(ctx, nickName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]response.GroupResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, nickName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMembers provides a mock function with given fields: ctx, groupID, query
+func (_m *IGroupService) GetMembers(ctx context.Context, groupID string, query request.MembersQuery) ([]userresponse.UserResponse, error) {
+	ret := _m.Called(ctx, groupID, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMembers")
+	}
+
+	var r0 []userresponse.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, request.MembersQuery) ([]userresponse.UserResponse, error)); ok {
+		return rf(ctx, groupID, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, request.MembersQuery) []userresponse.UserResponse); ok {
+		r0 = rf(ctx, groupID, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]userresponse.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, request.MembersQuery) error); ok {
+		r1 = rf(ctx, groupID, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveMember provides a mock function with given fields: ctx, groupID, nickName
+func (_m *IGroupService) RemoveMember(ctx context.Context, groupID string, nickName string) error {
+	ret := _m.Called(ctx, groupID, nickName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveMember")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, groupID, nickName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, groupID, groupRequest
+func (_m *IGroupService) Update(ctx context.Context, groupID string, groupRequest request.GroupRequest) (response.GroupResponse, error) {
+	ret := _m.Called(ctx, groupID, groupRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 response.GroupResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, request.GroupRequest) (response.GroupResponse, error)); ok {
+		return rf(ctx, groupID, groupRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, request.GroupRequest) response.GroupResponse); ok {
+		r0 = rf(ctx, groupID, groupRequest)
+	} else {
+		r0 = ret.Get(0).(response.GroupResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, request.GroupRequest) error); ok {
+		r1 = rf(ctx, groupID, groupRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewIGroupService creates a new instance of IGroupService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIGroupService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IGroupService {
+	mock := &IGroupService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -4,6 +4,7 @@ package mocks
 
 import (
 	context "context"
+	dto "cow_sso/pkg/integration/keycloak/dto"
 
 	gocloak "github.com/Nerzal/gocloak/v13"
 
@@ -15,9 +16,101 @@ type IKeycloakClient struct {
 	mock.Mock
 }
 
-// CreateUser provides a mock function with given fields: ctx, token, role, user
-func (_m *IKeycloakClient) CreateUser(ctx context.Context, token string, role *gocloak.Role, user gocloak.User) (string, error) {
-	ret := _m.Called(ctx, token, role, user)
+// AddRealmRolesToUser provides a mock function with given fields: ctx, userID, roles
+func (_m *IKeycloakClient) AddRealmRolesToUser(ctx context.Context, userID string, roles []gocloak.Role) error {
+	ret := _m.Called(ctx, userID, roles)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddRealmRolesToUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []gocloak.Role) error); ok {
+		r0 = rf(ctx, userID, roles)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddUserToGroup provides a mock function with given fields: ctx, userID, groupID
+func (_m *IKeycloakClient) AddUserToGroup(ctx context.Context, userID string, groupID string) error {
+	ret := _m.Called(ctx, userID, groupID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddUserToGroup")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, groupID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CountUsers provides a mock function with given fields: ctx, params
+func (_m *IKeycloakClient) CountUsers(ctx context.Context, params gocloak.GetUsersParams) (int, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountUsers")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, gocloak.GetUsersParams) (int, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, gocloak.GetUsersParams) int); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, gocloak.GetUsersParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateGroup provides a mock function with given fields: ctx, parentID, group
+func (_m *IKeycloakClient) CreateGroup(ctx context.Context, parentID string, group gocloak.Group) (string, error) {
+	ret := _m.Called(ctx, parentID, group)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateGroup")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, gocloak.Group) (string, error)); ok {
+		return rf(ctx, parentID, group)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, gocloak.Group) string); ok {
+		r0 = rf(ctx, parentID, group)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, gocloak.Group) error); ok {
+		r1 = rf(ctx, parentID, group)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateUser provides a mock function with given fields: ctx, user
+func (_m *IKeycloakClient) CreateUser(ctx context.Context, user gocloak.User) (string, error) {
+	ret := _m.Called(ctx, user)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateUser")
@@ -25,17 +118,17 @@ func (_m *IKeycloakClient) CreateUser(ctx context.Context, token string, role *g
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, *gocloak.Role, gocloak.User) (string, error)); ok {
-		return rf(ctx, token, role, user)
+	if rf, ok := ret.Get(0).(func(context.Context, gocloak.User) (string, error)); ok {
+		return rf(ctx, user)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, *gocloak.Role, gocloak.User) string); ok {
-		r0 = rf(ctx, token, role, user)
+	if rf, ok := ret.Get(0).(func(context.Context, gocloak.User) string); ok {
+		r0 = rf(ctx, user)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, *gocloak.Role, gocloak.User) error); ok {
-		r1 = rf(ctx, token, role, user)
+	if rf, ok := ret.Get(1).(func(context.Context, gocloak.User) error); ok {
+		r1 = rf(ctx, user)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -43,17 +136,71 @@ func (_m *IKeycloakClient) CreateUser(ctx context.Context, token string, role *g
 	return r0, r1
 }
 
-// DeleteUserByID provides a mock function with given fields: ctx, token, userID
-func (_m *IKeycloakClient) DeleteUserByID(ctx context.Context, token string, userID string) error {
-	ret := _m.Called(ctx, token, userID)
+// DeleteGroup provides a mock function with given fields: ctx, groupID
+func (_m *IKeycloakClient) DeleteGroup(ctx context.Context, groupID string) error {
+	ret := _m.Called(ctx, groupID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteGroup")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, groupID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteRealmRolesFromUser provides a mock function with given fields: ctx, userID, roles
+func (_m *IKeycloakClient) DeleteRealmRolesFromUser(ctx context.Context, userID string, roles []gocloak.Role) error {
+	ret := _m.Called(ctx, userID, roles)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteRealmRolesFromUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []gocloak.Role) error); ok {
+		r0 = rf(ctx, userID, roles)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteUserByID provides a mock function with given fields: ctx, userID
+func (_m *IKeycloakClient) DeleteUserByID(ctx context.Context, userID string) error {
+	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteUserByID")
 	}
 
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteUserFromGroup provides a mock function with given fields: ctx, userID, groupID
+func (_m *IKeycloakClient) DeleteUserFromGroup(ctx context.Context, userID string, groupID string) error {
+	ret := _m.Called(ctx, userID, groupID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUserFromGroup")
+	}
+
 	var r0 error
 	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
-		r0 = rf(ctx, token, userID)
+		r0 = rf(ctx, userID, groupID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -61,9 +208,9 @@ func (_m *IKeycloakClient) DeleteUserByID(ctx context.Context, token string, use
 	return r0
 }
 
-// GetAllUsers provides a mock function with given fields: ctx, token
-func (_m *IKeycloakClient) GetAllUsers(ctx context.Context, token string) ([]*gocloak.User, error) {
-	ret := _m.Called(ctx, token)
+// GetAllUsers provides a mock function with given fields: ctx, params
+func (_m *IKeycloakClient) GetAllUsers(ctx context.Context, params gocloak.GetUsersParams) ([]*gocloak.User, error) {
+	ret := _m.Called(ctx, params)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetAllUsers")
@@ -71,19 +218,49 @@ func (_m *IKeycloakClient) GetAllUsers(ctx context.Context, token string) ([]*go
 
 	var r0 []*gocloak.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*gocloak.User, error)); ok {
-		return rf(ctx, token)
+	if rf, ok := ret.Get(0).(func(context.Context, gocloak.GetUsersParams) ([]*gocloak.User, error)); ok {
+		return rf(ctx, params)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) []*gocloak.User); ok {
-		r0 = rf(ctx, token)
+	if rf, ok := ret.Get(0).(func(context.Context, gocloak.GetUsersParams) []*gocloak.User); ok {
+		r0 = rf(ctx, params)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*gocloak.User)
 		}
 	}
 
+	if rf, ok := ret.Get(1).(func(context.Context, gocloak.GetUsersParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGroupByID provides a mock function with given fields: ctx, groupID
+func (_m *IKeycloakClient) GetGroupByID(ctx context.Context, groupID string) (*gocloak.Group, error) {
+	ret := _m.Called(ctx, groupID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGroupByID")
+	}
+
+	var r0 *gocloak.Group
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*gocloak.Group, error)); ok {
+		return rf(ctx, groupID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *gocloak.Group); ok {
+		r0 = rf(ctx, groupID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*gocloak.Group)
+		}
+	}
+
 	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, token)
+		r1 = rf(ctx, groupID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -91,29 +268,119 @@ func (_m *IKeycloakClient) GetAllUsers(ctx context.Context, token string) ([]*go
 	return r0, r1
 }
 
-// GetRoleByID provides a mock function with given fields: ctx, token, roleID
-func (_m *IKeycloakClient) GetRoleByID(ctx context.Context, token string, roleID string) (*gocloak.Role, error) {
-	ret := _m.Called(ctx, token, roleID)
+// GetGroupMembers provides a mock function with given fields: ctx, groupID, params
+func (_m *IKeycloakClient) GetGroupMembers(ctx context.Context, groupID string, params gocloak.GetGroupsParams) ([]*gocloak.User, error) {
+	ret := _m.Called(ctx, groupID, params)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetRoleByID")
+		panic("no return value specified for GetGroupMembers")
 	}
 
-	var r0 *gocloak.Role
+	var r0 []*gocloak.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*gocloak.Role, error)); ok {
-		return rf(ctx, token, roleID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, gocloak.GetGroupsParams) ([]*gocloak.User, error)); ok {
+		return rf(ctx, groupID, params)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *gocloak.Role); ok {
-		r0 = rf(ctx, token, roleID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, gocloak.GetGroupsParams) []*gocloak.User); ok {
+		r0 = rf(ctx, groupID, params)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*gocloak.Role)
+			r0 = ret.Get(0).([]*gocloak.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, token, roleID)
+	if rf, ok := ret.Get(1).(func(context.Context, string, gocloak.GetGroupsParams) error); ok {
+		r1 = rf(ctx, groupID, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGroups provides a mock function with given fields: ctx, params
+func (_m *IKeycloakClient) GetGroups(ctx context.Context, params gocloak.GetGroupsParams) ([]*gocloak.Group, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGroups")
+	}
+
+	var r0 []*gocloak.Group
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, gocloak.GetGroupsParams) ([]*gocloak.Group, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, gocloak.GetGroupsParams) []*gocloak.Group); ok {
+		r0 = rf(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*gocloak.Group)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, gocloak.GetGroupsParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRealmRoles provides a mock function with given fields: ctx
+func (_m *IKeycloakClient) GetRealmRoles(ctx context.Context) ([]*gocloak.Role, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRealmRoles")
+	}
+
+	var r0 []*gocloak.Role
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*gocloak.Role, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*gocloak.Role); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*gocloak.Role)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRealmRolesByName provides a mock function with given fields: ctx, roleNames
+func (_m *IKeycloakClient) GetRealmRolesByName(ctx context.Context, roleNames []string) ([]gocloak.Role, error) {
+	ret := _m.Called(ctx, roleNames)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRealmRolesByName")
+	}
+
+	var r0 []gocloak.Role
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]gocloak.Role, error)); ok {
+		return rf(ctx, roleNames)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []gocloak.Role); ok {
+		r0 = rf(ctx, roleNames)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]gocloak.Role)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, roleNames)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -121,9 +388,39 @@ func (_m *IKeycloakClient) GetRoleByID(ctx context.Context, token string, roleID
 	return r0, r1
 }
 
-// GetUserByID provides a mock function with given fields: ctx, token, userID
-func (_m *IKeycloakClient) GetUserByID(ctx context.Context, token string, userID string) (*gocloak.User, error) {
-	ret := _m.Called(ctx, token, userID)
+// GetUserByEmail provides a mock function with given fields: ctx, email
+func (_m *IKeycloakClient) GetUserByEmail(ctx context.Context, email string) (*gocloak.User, error) {
+	ret := _m.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserByEmail")
+	}
+
+	var r0 *gocloak.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*gocloak.User, error)); ok {
+		return rf(ctx, email)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *gocloak.User); ok {
+		r0 = rf(ctx, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*gocloak.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserByID provides a mock function with given fields: ctx, userID
+func (_m *IKeycloakClient) GetUserByID(ctx context.Context, userID string) (*gocloak.User, error) {
+	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetUserByID")
@@ -131,19 +428,19 @@ func (_m *IKeycloakClient) GetUserByID(ctx context.Context, token string, userID
 
 	var r0 *gocloak.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*gocloak.User, error)); ok {
-		return rf(ctx, token, userID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*gocloak.User, error)); ok {
+		return rf(ctx, userID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *gocloak.User); ok {
-		r0 = rf(ctx, token, userID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *gocloak.User); ok {
+		r0 = rf(ctx, userID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*gocloak.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, token, userID)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -151,9 +448,9 @@ func (_m *IKeycloakClient) GetUserByID(ctx context.Context, token string, userID
 	return r0, r1
 }
 
-// GetUserByNickName provides a mock function with given fields: ctx, token, nickName
-func (_m *IKeycloakClient) GetUserByNickName(ctx context.Context, token string, nickName string) (*gocloak.User, error) {
-	ret := _m.Called(ctx, token, nickName)
+// GetUserByNickName provides a mock function with given fields: ctx, nickName
+func (_m *IKeycloakClient) GetUserByNickName(ctx context.Context, nickName string) (*gocloak.User, error) {
+	ret := _m.Called(ctx, nickName)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetUserByNickName")
@@ -161,19 +458,107 @@ func (_m *IKeycloakClient) GetUserByNickName(ctx context.Context, token string,
 
 	var r0 *gocloak.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*gocloak.User, error)); ok {
-		return rf(ctx, token, nickName)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*gocloak.User, error)); ok {
+		return rf(ctx, nickName)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *gocloak.User); ok {
-		r0 = rf(ctx, token, nickName)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *gocloak.User); ok {
+		r0 = rf(ctx, nickName)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*gocloak.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, token, nickName)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, nickName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserGroups provides a mock function with given fields: ctx, userID
+func (_m *IKeycloakClient) GetUserGroups(ctx context.Context, userID string) ([]*gocloak.Group, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserGroups")
+	}
+
+	var r0 []*gocloak.Group
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*gocloak.Group, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*gocloak.Group); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*gocloak.Group)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserInfo provides a mock function with given fields: ctx, accessToken
+func (_m *IKeycloakClient) GetUserInfo(ctx context.Context, accessToken string) (dto.UserInfo, error) {
+	ret := _m.Called(ctx, accessToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserInfo")
+	}
+
+	var r0 dto.UserInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (dto.UserInfo, error)); ok {
+		return rf(ctx, accessToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) dto.UserInfo); ok {
+		r0 = rf(ctx, accessToken)
+	} else {
+		r0 = ret.Get(0).(dto.UserInfo)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, accessToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserRealmRoles provides a mock function with given fields: ctx, userID
+func (_m *IKeycloakClient) GetUserRealmRoles(ctx context.Context, userID string) ([]*gocloak.Role, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserRealmRoles")
+	}
+
+	var r0 []*gocloak.Role
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*gocloak.Role, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*gocloak.Role); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*gocloak.Role)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -181,23 +566,23 @@ func (_m *IKeycloakClient) GetUserByNickName(ctx context.Context, token string,
 	return r0, r1
 }
 
-// IsValidToken provides a mock function with given fields: ctx, accessToken
-func (_m *IKeycloakClient) IsValidToken(ctx context.Context, accessToken string) (bool, error) {
+// IntrospectToken provides a mock function with given fields: ctx, accessToken
+func (_m *IKeycloakClient) IntrospectToken(ctx context.Context, accessToken string) (dto.TokenIntrospection, error) {
 	ret := _m.Called(ctx, accessToken)
 
 	if len(ret) == 0 {
-		panic("no return value specified for IsValidToken")
+		panic("no return value specified for IntrospectToken")
 	}
 
-	var r0 bool
+	var r0 dto.TokenIntrospection
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) (dto.TokenIntrospection, error)); ok {
 		return rf(ctx, accessToken)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) dto.TokenIntrospection); ok {
 		r0 = rf(ctx, accessToken)
 	} else {
-		r0 = ret.Get(0).(bool)
+		r0 = ret.Get(0).(dto.TokenIntrospection)
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
@@ -257,6 +642,126 @@ func (_m *IKeycloakClient) Logout(ctx context.Context, refreshToken string) erro
 	return r0
 }
 
+// LogoutUserSessions provides a mock function with given fields: ctx, userID
+func (_m *IKeycloakClient) LogoutUserSessions(ctx context.Context, userID string) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LogoutUserSessions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RefreshToken provides a mock function with given fields: ctx, refreshToken
+func (_m *IKeycloakClient) RefreshToken(ctx context.Context, refreshToken string) (*gocloak.JWT, error) {
+	ret := _m.Called(ctx, refreshToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RefreshToken")
+	}
+
+	var r0 *gocloak.JWT
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*gocloak.JWT, error)); ok {
+		return rf(ctx, refreshToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *gocloak.JWT); ok {
+		r0 = rf(ctx, refreshToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*gocloak.JWT)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, refreshToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SendActionsEmail provides a mock function with given fields: ctx, userID, actions
+func (_m *IKeycloakClient) SendActionsEmail(ctx context.Context, userID string, actions []string) error {
+	ret := _m.Called(ctx, userID, actions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendActionsEmail")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) error); ok {
+		r0 = rf(ctx, userID, actions)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetPassword provides a mock function with given fields: ctx, userID, password, temporary
+func (_m *IKeycloakClient) SetPassword(ctx context.Context, userID string, password string, temporary bool) error {
+	ret := _m.Called(ctx, userID, password, temporary)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool) error); ok {
+		r0 = rf(ctx, userID, password, temporary)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateGroup provides a mock function with given fields: ctx, group
+func (_m *IKeycloakClient) UpdateGroup(ctx context.Context, group gocloak.Group) error {
+	ret := _m.Called(ctx, group)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateGroup")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, gocloak.Group) error); ok {
+		r0 = rf(ctx, group)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateUser provides a mock function with given fields: ctx, user
+func (_m *IKeycloakClient) UpdateUser(ctx context.Context, user gocloak.User) error {
+	ret := _m.Called(ctx, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, gocloak.User) error); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewIKeycloakClient creates a new instance of IKeycloakClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewIKeycloakClient(t interface {
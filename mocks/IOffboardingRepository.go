@@ -0,0 +1,81 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	dto "cow_sso/pkg/integration/offboarding/dto"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IOffboardingRepository is an autogenerated mock type for the IOffboardingRepository type
+type IOffboardingRepository struct {
+	mock.Mock
+}
+
+// Get provides a mock function with given fields: nickName
+func (_m *IOffboardingRepository) Get(nickName string) (dto.Offboarding, bool, error) {
+	ret := _m.Called(nickName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 dto.Offboarding
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string) (dto.Offboarding, bool, error)); ok {
+		return rf(nickName)
+	}
+	if rf, ok := ret.Get(0).(func(string) dto.Offboarding); ok {
+		r0 = rf(nickName)
+	} else {
+		r0 = ret.Get(0).(dto.Offboarding)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(nickName)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(nickName)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Save provides a mock function with given fields: _a0
+func (_m *IOffboardingRepository) Save(_a0 dto.Offboarding) error {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(dto.Offboarding) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewIOffboardingRepository creates a new instance of IOffboardingRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIOffboardingRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IOffboardingRepository {
+	mock := &IOffboardingRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,48 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	gin "github.com/gin-gonic/gin"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IRateLimitMiddleWare is an autogenerated mock type for the IRateLimitMiddleWare type
+type IRateLimitMiddleWare struct {
+	mock.Mock
+}
+
+// Limit provides a mock function with given fields: name
+func (_m *IRateLimitMiddleWare) Limit(name string) gin.HandlerFunc {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Limit")
+	}
+
+	var r0 gin.HandlerFunc
+	if rf, ok := ret.Get(0).(func(string) gin.HandlerFunc); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(gin.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
+// NewIRateLimitMiddleWare creates a new instance of IRateLimitMiddleWare. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIRateLimitMiddleWare(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IRateLimitMiddleWare {
+	mock := &IRateLimitMiddleWare{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -13,6 +13,36 @@ type IRestClient struct {
 	mock.Mock
 }
 
+// Delete provides a mock function with given fields: ctx, url, timeOut
+func (_m *IRestClient) Delete(ctx context.Context, url string, timeOut string) ([]byte, error) {
+	ret := _m.Called(ctx, url, timeOut)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]byte, error)); ok {
+		return rf(ctx, url, timeOut)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []byte); ok {
+		r0 = rf(ctx, url, timeOut)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, url, timeOut)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Get provides a mock function with given fields: ctx, url, timeOut
 func (_m *IRestClient) Get(ctx context.Context, url string, timeOut string) ([]byte, error) {
 	ret := _m.Called(ctx, url, timeOut)
@@ -43,6 +73,36 @@ func (_m *IRestClient) Get(ctx context.Context, url string, timeOut string) ([]b
 	return r0, r1
 }
 
+// Post provides a mock function with given fields: ctx, url, body, timeOut
+func (_m *IRestClient) Post(ctx context.Context, url string, body []byte, timeOut string) ([]byte, error) {
+	ret := _m.Called(ctx, url, body, timeOut)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Post")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte, string) ([]byte, error)); ok {
+		return rf(ctx, url, body, timeOut)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte, string) []byte); ok {
+		r0 = rf(ctx, url, body, timeOut)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []byte, string) error); ok {
+		r1 = rf(ctx, url, body, timeOut)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewIRestClient creates a new instance of IRestClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewIRestClient(t interface {
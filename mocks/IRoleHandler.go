@@ -0,0 +1,47 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	gin "github.com/gin-gonic/gin"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IRoleHandler is an autogenerated mock type for the IRoleHandler type
+type IRoleHandler struct {
+	mock.Mock
+}
+
+// GetAll provides a mock function with given fields: c
+func (_m *IRoleHandler) GetAll(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetByUser provides a mock function with given fields: c
+func (_m *IRoleHandler) GetByUser(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Grant provides a mock function with given fields: c
+func (_m *IRoleHandler) Grant(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Revoke provides a mock function with given fields: c
+func (_m *IRoleHandler) Revoke(c *gin.Context) {
+	_m.Called(c)
+}
+
+// NewIRoleHandler creates a new instance of IRoleHandler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIRoleHandler(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IRoleHandler {
+	mock := &IRoleHandler{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,150 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	request "cow_sso/api/handlers/role/request"
+	response "cow_sso/api/handlers/role/response"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IRoleService is an autogenerated mock type for the IRoleService type
+type IRoleService struct {
+	mock.Mock
+}
+
+// GetAll provides a mock function with given fields: ctx
+func (_m *IRoleService) GetAll(ctx context.Context) ([]response.RoleResponse, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []response.RoleResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]response.RoleResponse, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []response.RoleResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]response.RoleResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByUser provides a mock function with given fields: ctx, nickName
+func (_m *IRoleService) GetByUser(ctx context.Context, nickName string) ([]response.RoleResponse, error) {
+	ret := _m.Called(ctx, nickName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByUser")
+	}
+
+	var r0 []response.RoleResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]response.RoleResponse, error)); ok {
+		return rf(ctx, nickName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []response.RoleResponse); ok {
+		r0 = rf(ctx, nickName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]response.RoleResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, nickName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Grant provides a mock function with given fields: ctx, nickName, roleRequest
+func (_m *IRoleService) Grant(ctx context.Context, nickName string, roleRequest request.RoleRequest) ([]response.RoleResponse, error) {
+	ret := _m.Called(ctx, nickName, roleRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Grant")
+	}
+
+	var r0 []response.RoleResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, request.RoleRequest) ([]response.RoleResponse, error)); ok {
+		return rf(ctx, nickName, roleRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, request.RoleRequest) []response.RoleResponse); ok {
+		r0 = rf(ctx, nickName, roleRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]response.RoleResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, request.RoleRequest) error); ok {
+		r1 = rf(ctx, nickName, roleRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Revoke provides a mock function with given fields: ctx, nickName, roleName
+func (_m *IRoleService) Revoke(ctx context.Context, nickName string, roleName string) ([]response.RoleResponse, error) {
+	ret := _m.Called(ctx, nickName, roleName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 []response.RoleResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]response.RoleResponse, error)); ok {
+		return rf(ctx, nickName, roleName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []response.RoleResponse); ok {
+		r0 = rf(ctx, nickName, roleName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]response.RoleResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, nickName, roleName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewIRoleService creates a new instance of IRoleService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIRoleService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IRoleService {
+	mock := &IRoleService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
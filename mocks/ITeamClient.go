@@ -42,6 +42,42 @@ func (_m *ITeamClient) GetTeamsByUser(ctx context.Context, userID string) (dto.T
 	return r0, r1
 }
 
+// NotifyUserDeleted provides a mock function with given fields: ctx, notification
+func (_m *ITeamClient) NotifyUserDeleted(ctx context.Context, notification dto.UserDeletedNotification) error {
+	ret := _m.Called(ctx, notification)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NotifyUserDeleted")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.UserDeletedNotification) error); ok {
+		r0 = rf(ctx, notification)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveUserFromTeams provides a mock function with given fields: ctx, userID
+func (_m *ITeamClient) RemoveUserFromTeams(ctx context.Context, userID string) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveUserFromTeams")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewITeamClient creates a new instance of ITeamClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewITeamClient(t interface {
@@ -12,6 +12,16 @@ type IUserHandler struct {
 	mock.Mock
 }
 
+// ApproveRegistration provides a mock function with given fields: c
+func (_m *IUserHandler) ApproveRegistration(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Availability provides a mock function with given fields: c
+func (_m *IUserHandler) Availability(c *gin.Context) {
+	_m.Called(c)
+}
+
 // Create provides a mock function with given fields: c
 func (_m *IUserHandler) Create(c *gin.Context) {
 	_m.Called(c)
@@ -22,16 +32,96 @@ func (_m *IUserHandler) Delete(c *gin.Context) {
 	_m.Called(c)
 }
 
+// DeleteAttribute provides a mock function with given fields: c
+func (_m *IUserHandler) DeleteAttribute(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Disable provides a mock function with given fields: c
+func (_m *IUserHandler) Disable(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Enable provides a mock function with given fields: c
+func (_m *IUserHandler) Enable(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Export provides a mock function with given fields: c
+func (_m *IUserHandler) Export(c *gin.Context) {
+	_m.Called(c)
+}
+
 // GetAll provides a mock function with given fields: c
 func (_m *IUserHandler) GetAll(c *gin.Context) {
 	_m.Called(c)
 }
 
+// GetAttribute provides a mock function with given fields: c
+func (_m *IUserHandler) GetAttribute(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetByEmail provides a mock function with given fields: c
+func (_m *IUserHandler) GetByEmail(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetByID provides a mock function with given fields: c
+func (_m *IUserHandler) GetByID(c *gin.Context) {
+	_m.Called(c)
+}
+
 // GetByNickName provides a mock function with given fields: c
 func (_m *IUserHandler) GetByNickName(c *gin.Context) {
 	_m.Called(c)
 }
 
+// GetOffboarding provides a mock function with given fields: c
+func (_m *IUserHandler) GetOffboarding(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetRegistrations provides a mock function with given fields: c
+func (_m *IUserHandler) GetRegistrations(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Import provides a mock function with given fields: c
+func (_m *IUserHandler) Import(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Patch provides a mock function with given fields: c
+func (_m *IUserHandler) Patch(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Register provides a mock function with given fields: c
+func (_m *IUserHandler) Register(c *gin.Context) {
+	_m.Called(c)
+}
+
+// RejectRegistration provides a mock function with given fields: c
+func (_m *IUserHandler) RejectRegistration(c *gin.Context) {
+	_m.Called(c)
+}
+
+// ResetPassword provides a mock function with given fields: c
+func (_m *IUserHandler) ResetPassword(c *gin.Context) {
+	_m.Called(c)
+}
+
+// SetAttribute provides a mock function with given fields: c
+func (_m *IUserHandler) SetAttribute(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Update provides a mock function with given fields: c
+func (_m *IUserHandler) Update(c *gin.Context) {
+	_m.Called(c)
+}
+
 // NewIUserHandler creates a new instance of IUserHandler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewIUserHandler(t interface {
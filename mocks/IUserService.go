@@ -5,10 +5,10 @@ package mocks
 import (
 	context "context"
 	request "cow_sso/api/handlers/user/request"
+	response "cow_sso/api/handlers/user/response"
+	io "io"
 
 	mock "github.com/stretchr/testify/mock"
-
-	response "cow_sso/api/handlers/user/response"
 )
 
 // IUserService is an autogenerated mock type for the IUserService type
@@ -16,17 +16,73 @@ type IUserService struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: ctx, token, userRequest
-func (_m *IUserService) Create(ctx context.Context, token string, userRequest request.UserRequest) error {
-	ret := _m.Called(ctx, token, userRequest)
+// ApproveRegistration provides a mock function with given fields: ctx, nickName
+func (_m *IUserService) ApproveRegistration(ctx context.Context, nickName string) (response.UserResponse, error) {
+	ret := _m.Called(ctx, nickName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApproveRegistration")
+	}
+
+	var r0 response.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (response.UserResponse, error)); ok {
+		return rf(ctx, nickName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) response.UserResponse); ok {
+		r0 = rf(ctx, nickName)
+	} else {
+		r0 = ret.Get(0).(response.UserResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, nickName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Availability provides a mock function with given fields: ctx, query
+func (_m *IUserService) Availability(ctx context.Context, query request.AvailabilityQuery) (response.AvailabilityResponse, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Availability")
+	}
+
+	var r0 response.AvailabilityResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.AvailabilityQuery) (response.AvailabilityResponse, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.AvailabilityQuery) response.AvailabilityResponse); ok {
+		r0 = rf(ctx, query)
+	} else {
+		r0 = ret.Get(0).(response.AvailabilityResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.AvailabilityQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, userRequest
+func (_m *IUserService) Create(ctx context.Context, userRequest request.UserRequest) error {
+	ret := _m.Called(ctx, userRequest)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, request.UserRequest) error); ok {
-		r0 = rf(ctx, token, userRequest)
+	if rf, ok := ret.Get(0).(func(context.Context, request.UserRequest) error); ok {
+		r0 = rf(ctx, userRequest)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -34,9 +90,9 @@ func (_m *IUserService) Create(ctx context.Context, token string, userRequest re
 	return r0
 }
 
-// Delete provides a mock function with given fields: ctx, token, userID
-func (_m *IUserService) Delete(ctx context.Context, token string, userID string) (string, error) {
-	ret := _m.Called(ctx, token, userID)
+// Delete provides a mock function with given fields: ctx, userID
+func (_m *IUserService) Delete(ctx context.Context, userID string) (string, error) {
+	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Delete")
@@ -44,17 +100,17 @@ func (_m *IUserService) Delete(ctx context.Context, token string, userID string)
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (string, error)); ok {
-		return rf(ctx, token, userID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+		return rf(ctx, userID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
-		r0 = rf(ctx, token, userID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, userID)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, token, userID)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -62,29 +118,63 @@ func (_m *IUserService) Delete(ctx context.Context, token string, userID string)
 	return r0, r1
 }
 
-// GetAll provides a mock function with given fields: ctx, token
-func (_m *IUserService) GetAll(ctx context.Context, token string) ([]response.UserResponse, error) {
-	ret := _m.Called(ctx, token)
+// DeleteAttribute provides a mock function with given fields: ctx, nickName, key
+func (_m *IUserService) DeleteAttribute(ctx context.Context, nickName string, key string) error {
+	ret := _m.Called(ctx, nickName, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAttribute")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, nickName, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Export provides a mock function with given fields: ctx, query, w
+func (_m *IUserService) Export(ctx context.Context, query request.ExportQuery, w io.Writer) error {
+	ret := _m.Called(ctx, query, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Export")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ExportQuery, io.Writer) error); ok {
+		r0 = rf(ctx, query, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: ctx, query
+func (_m *IUserService) GetAll(ctx context.Context, query request.UserQuery) (response.UsersResponse, error) {
+	ret := _m.Called(ctx, query)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetAll")
 	}
 
-	var r0 []response.UserResponse
+	var r0 response.UsersResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) ([]response.UserResponse, error)); ok {
-		return rf(ctx, token)
+	if rf, ok := ret.Get(0).(func(context.Context, request.UserQuery) (response.UsersResponse, error)); ok {
+		return rf(ctx, query)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) []response.UserResponse); ok {
-		r0 = rf(ctx, token)
+	if rf, ok := ret.Get(0).(func(context.Context, request.UserQuery) response.UsersResponse); ok {
+		r0 = rf(ctx, query)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]response.UserResponse)
-		}
+		r0 = ret.Get(0).(response.UsersResponse)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, token)
+	if rf, ok := ret.Get(1).(func(context.Context, request.UserQuery) error); ok {
+		r1 = rf(ctx, query)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -92,9 +182,93 @@ func (_m *IUserService) GetAll(ctx context.Context, token string) ([]response.Us
 	return r0, r1
 }
 
-// GetByNickName provides a mock function with given fields: ctx, token, nickName
-func (_m *IUserService) GetByNickName(ctx context.Context, token string, nickName string) (response.UserResponse, error) {
-	ret := _m.Called(ctx, token, nickName)
+// GetAttribute provides a mock function with given fields: ctx, nickName, key
+func (_m *IUserService) GetAttribute(ctx context.Context, nickName string, key string) (response.AttributeResponse, error) {
+	ret := _m.Called(ctx, nickName, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAttribute")
+	}
+
+	var r0 response.AttributeResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (response.AttributeResponse, error)); ok {
+		return rf(ctx, nickName, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) response.AttributeResponse); ok {
+		r0 = rf(ctx, nickName, key)
+	} else {
+		r0 = ret.Get(0).(response.AttributeResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, nickName, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByEmail provides a mock function with given fields: ctx, email, expand
+func (_m *IUserService) GetByEmail(ctx context.Context, email string, expand []string) (response.UserResponse, error) {
+	ret := _m.Called(ctx, email, expand)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByEmail")
+	}
+
+	var r0 response.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (response.UserResponse, error)); ok {
+		return rf(ctx, email, expand)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) response.UserResponse); ok {
+		r0 = rf(ctx, email, expand)
+	} else {
+		r0 = ret.Get(0).(response.UserResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, email, expand)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, userID, expand
+func (_m *IUserService) GetByID(ctx context.Context, userID string, expand []string) (response.UserResponse, error) {
+	ret := _m.Called(ctx, userID, expand)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 response.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (response.UserResponse, error)); ok {
+		return rf(ctx, userID, expand)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) response.UserResponse); ok {
+		r0 = rf(ctx, userID, expand)
+	} else {
+		r0 = ret.Get(0).(response.UserResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, userID, expand)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByNickName provides a mock function with given fields: ctx, nickName, expand
+func (_m *IUserService) GetByNickName(ctx context.Context, nickName string, expand []string) (response.UserResponse, error) {
+	ret := _m.Called(ctx, nickName, expand)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetByNickName")
@@ -102,17 +276,249 @@ func (_m *IUserService) GetByNickName(ctx context.Context, token string, nickNam
 
 	var r0 response.UserResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (response.UserResponse, error)); ok {
-		return rf(ctx, token, nickName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (response.UserResponse, error)); ok {
+		return rf(ctx, nickName, expand)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) response.UserResponse); ok {
-		r0 = rf(ctx, token, nickName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) response.UserResponse); ok {
+		r0 = rf(ctx, nickName, expand)
 	} else {
 		r0 = ret.Get(0).(response.UserResponse)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, token, nickName)
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, nickName, expand)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOffboarding provides a mock function with given fields: ctx, nickName
+func (_m *IUserService) GetOffboarding(ctx context.Context, nickName string) (response.OffboardingResponse, error) {
+	ret := _m.Called(ctx, nickName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOffboarding")
+	}
+
+	var r0 response.OffboardingResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (response.OffboardingResponse, error)); ok {
+		return rf(ctx, nickName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) response.OffboardingResponse); ok {
+		r0 = rf(ctx, nickName)
+	} else {
+		r0 = ret.Get(0).(response.OffboardingResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, nickName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRegistrations provides a mock function with given fields: ctx, query
+func (_m *IUserService) GetRegistrations(ctx context.Context, query request.UserQuery) (response.UsersResponse, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRegistrations")
+	}
+
+	var r0 response.UsersResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.UserQuery) (response.UsersResponse, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.UserQuery) response.UsersResponse); ok {
+		r0 = rf(ctx, query)
+	} else {
+		r0 = ret.Get(0).(response.UsersResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.UserQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Import provides a mock function with given fields: ctx, rows, dryRun
+func (_m *IUserService) Import(ctx context.Context, rows []request.UserRequest, dryRun bool) (response.ImportResponse, error) {
+	ret := _m.Called(ctx, rows, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Import")
+	}
+
+	var r0 response.ImportResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []request.UserRequest, bool) (response.ImportResponse, error)); ok {
+		return rf(ctx, rows, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []request.UserRequest, bool) response.ImportResponse); ok {
+		r0 = rf(ctx, rows, dryRun)
+	} else {
+		r0 = ret.Get(0).(response.ImportResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []request.UserRequest, bool) error); ok {
+		r1 = rf(ctx, rows, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Register provides a mock function with given fields: ctx, registerRequest
+func (_m *IUserService) Register(ctx context.Context, registerRequest request.RegisterRequest) (response.RegistrationResponse, error) {
+	ret := _m.Called(ctx, registerRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Register")
+	}
+
+	var r0 response.RegistrationResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.RegisterRequest) (response.RegistrationResponse, error)); ok {
+		return rf(ctx, registerRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.RegisterRequest) response.RegistrationResponse); ok {
+		r0 = rf(ctx, registerRequest)
+	} else {
+		r0 = ret.Get(0).(response.RegistrationResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.RegisterRequest) error); ok {
+		r1 = rf(ctx, registerRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RejectRegistration provides a mock function with given fields: ctx, nickName
+func (_m *IUserService) RejectRegistration(ctx context.Context, nickName string) error {
+	ret := _m.Called(ctx, nickName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RejectRegistration")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, nickName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResetPassword provides a mock function with given fields: ctx, nickName, passwordRequest
+func (_m *IUserService) ResetPassword(ctx context.Context, nickName string, passwordRequest request.PasswordRequest) error {
+	ret := _m.Called(ctx, nickName, passwordRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetPassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, request.PasswordRequest) error); ok {
+		r0 = rf(ctx, nickName, passwordRequest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetAttribute provides a mock function with given fields: ctx, nickName, key, value
+func (_m *IUserService) SetAttribute(ctx context.Context, nickName string, key string, value string) (response.AttributeResponse, error) {
+	ret := _m.Called(ctx, nickName, key, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAttribute")
+	}
+
+	var r0 response.AttributeResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (response.AttributeResponse, error)); ok {
+		return rf(ctx, nickName, key, value)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) response.AttributeResponse); ok {
+		r0 = rf(ctx, nickName, key, value)
+	} else {
+		r0 = ret.Get(0).(response.AttributeResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, nickName, key, value)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetEnabled provides a mock function with given fields: ctx, nickName, enabled
+func (_m *IUserService) SetEnabled(ctx context.Context, nickName string, enabled bool) (response.UserResponse, error) {
+	ret := _m.Called(ctx, nickName, enabled)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetEnabled")
+	}
+
+	var r0 response.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) (response.UserResponse, error)); ok {
+		return rf(ctx, nickName, enabled)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) response.UserResponse); ok {
+		r0 = rf(ctx, nickName, enabled)
+	} else {
+		r0 = ret.Get(0).(response.UserResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, nickName, enabled)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, nickName, updateRequest, partial, ifMatch
+func (_m *IUserService) Update(ctx context.Context, nickName string, updateRequest request.UpdateUserRequest, partial bool, ifMatch string) (response.UserResponse, error) {
+	ret := _m.Called(ctx, nickName, updateRequest, partial, ifMatch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 response.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, request.UpdateUserRequest, bool, string) (response.UserResponse, error)); ok {
+		return rf(ctx, nickName, updateRequest, partial, ifMatch)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, request.UpdateUserRequest, bool, string) response.UserResponse); ok {
+		r0 = rf(ctx, nickName, updateRequest, partial, ifMatch)
+	} else {
+		r0 = ret.Get(0).(response.UserResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, request.UpdateUserRequest, bool, string) error); ok {
+		r1 = rf(ctx, nickName, updateRequest, partial, ifMatch)
 	} else {
 		r1 = ret.Error(1)
 	}
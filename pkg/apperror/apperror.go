@@ -0,0 +1,46 @@
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// AppError is an error that knows which http status it should be reported with.
+type AppError struct {
+	Details any
+	Message string
+	Code    int
+}
+
+func New(code int, message string) *AppError {
+	return &AppError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+func Unauthorized(message string) *AppError {
+	return New(http.StatusUnauthorized, message)
+}
+
+// Conflict reports that another resource already holds the value sent for field.
+func Conflict(field string, message string) *AppError {
+	return &AppError{
+		Code:    http.StatusConflict,
+		Message: message,
+		Details: field,
+	}
+}
+
+func (e *AppError) Error() string {
+	return e.Message
+}
+
+// Code returns the status carried by err, or fallback when err isn't an AppError.
+func Code(err error, fallback int) int {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return fallback
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "github.com/golang-jwt/jwt/v4"
+
+type TokenIntrospection struct {
+	Claims TokenClaims
+	Active bool
+}
+
+type TokenClaims struct {
+	ResourceAccess map[string]RolesClaim `json:"resource_access,omitempty"`
+	Username       string                `json:"preferred_username,omitempty"`
+	Email          string                `json:"email,omitempty"`
+	Scope          string                `json:"scope,omitempty"`
+	RealmAccess    RolesClaim            `json:"realm_access,omitempty"`
+	// AuthorizedParty is the client the token was issued to.
+	AuthorizedParty string `json:"azp,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type RolesClaim struct {
+	Roles []string `json:"roles,omitempty"`
+}
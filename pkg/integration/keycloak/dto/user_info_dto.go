@@ -0,0 +1,8 @@
+package dto
+
+import "github.com/Nerzal/gocloak/v13"
+
+type UserInfo struct {
+	Profile *gocloak.UserInfo
+	Claims  TokenClaims
+}
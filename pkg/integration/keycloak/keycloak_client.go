@@ -4,41 +4,85 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
 
+	"cow_sso/pkg/apperror"
 	"cow_sso/pkg/config"
+	"cow_sso/pkg/integration/keycloak/dto"
 
 	"github.com/Nerzal/gocloak/v13"
+	"github.com/golang-jwt/jwt/v4"
 )
 
 type IKeycloakClient interface {
 	Login(ctx context.Context, user string, password string) (*gocloak.JWT, error)
 	Logout(ctx context.Context, refreshToken string) error
-	IsValidToken(ctx context.Context, accessToken string) (bool, error)
-	GetUserByID(ctx context.Context, token string, userID string) (*gocloak.User, error)
-	GetAllUsers(ctx context.Context, token string) ([]*gocloak.User, error)
-	GetUserByNickName(ctx context.Context, token string, nickName string) (*gocloak.User, error)
-	GetRoleByID(ctx context.Context, token string, roleID string) (*gocloak.Role, error)
-	CreateUser(ctx context.Context, token string, role *gocloak.Role, user gocloak.User) (string, error)
-	DeleteUserByID(ctx context.Context, token string, userID string) error
+	RefreshToken(ctx context.Context, refreshToken string) (*gocloak.JWT, error)
+	IntrospectToken(ctx context.Context, accessToken string) (dto.TokenIntrospection, error)
+	GetUserInfo(ctx context.Context, accessToken string) (dto.UserInfo, error)
+	GetUserByID(ctx context.Context, userID string) (*gocloak.User, error)
+	GetAllUsers(ctx context.Context, params gocloak.GetUsersParams) ([]*gocloak.User, error)
+	CountUsers(ctx context.Context, params gocloak.GetUsersParams) (int, error)
+	GetUserByNickName(ctx context.Context, nickName string) (*gocloak.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*gocloak.User, error)
+	GetRealmRoles(ctx context.Context) ([]*gocloak.Role, error)
+	GetRealmRolesByName(ctx context.Context, roleNames []string) ([]gocloak.Role, error)
+	GetUserRealmRoles(ctx context.Context, userID string) ([]*gocloak.Role, error)
+	AddRealmRolesToUser(ctx context.Context, userID string, roles []gocloak.Role) error
+	DeleteRealmRolesFromUser(ctx context.Context, userID string, roles []gocloak.Role) error
+	GetGroups(ctx context.Context, params gocloak.GetGroupsParams) ([]*gocloak.Group, error)
+	GetGroupByID(ctx context.Context, groupID string) (*gocloak.Group, error)
+	CreateGroup(ctx context.Context, parentID string, group gocloak.Group) (string, error)
+	UpdateGroup(ctx context.Context, group gocloak.Group) error
+	DeleteGroup(ctx context.Context, groupID string) error
+	GetGroupMembers(ctx context.Context, groupID string, params gocloak.GetGroupsParams) ([]*gocloak.User, error)
+	GetUserGroups(ctx context.Context, userID string) ([]*gocloak.Group, error)
+	AddUserToGroup(ctx context.Context, userID string, groupID string) error
+	DeleteUserFromGroup(ctx context.Context, userID string, groupID string) error
+	CreateUser(ctx context.Context, user gocloak.User) (string, error)
+	UpdateUser(ctx context.Context, user gocloak.User) error
+	SetPassword(ctx context.Context, userID string, password string, temporary bool) error
+	SendActionsEmail(ctx context.Context, userID string, actions []string) error
+	LogoutUserSessions(ctx context.Context, userID string) error
+	DeleteUserByID(ctx context.Context, userID string) error
 }
 
 type keycloakClient struct {
-	host   *gocloak.GoCloak
-	realm  string
-	client string
-	secret string
+	host            *gocloak.GoCloak
+	validator       *tokenValidator
+	serviceAccount  *serviceAccount
+	realm           string
+	client          string
+	secret          string
+	validation      string
+	actionsRedirect string
+	actionsLifespan int
 }
 
 func NewKeycloakClient() IKeycloakClient {
-	host := gocloak.NewClient(config.Get().UString("keycloak.host"))
+	hostURL := config.Get().UString("keycloak.host")
+	host := gocloak.NewClient(hostURL)
+	realm := config.Get().UString("keycloak.realm")
 	client := config.Get().UString("keycloak.client")
 	secret := os.Getenv("KEYCLOAK_SECRET")
+	issuer := config.Get().UString("keycloak.issuer", fmt.Sprintf("%s/realms/%s", hostURL, realm))
+	audience := config.Get().UString("keycloak.audience", client)
 	return &keycloakClient{
-		host:   host,
-		realm:  config.Get().UString("keycloak.realm"),
-		client: client,
-		secret: secret,
+		host: host,
+		validator: newTokenValidator(func(ctx context.Context) (*gocloak.CertResponse, error) {
+			return host.GetCerts(ctx, realm)
+		}, issuer, audience),
+		serviceAccount: newServiceAccount(func(ctx context.Context) (*gocloak.JWT, error) {
+			return host.LoginClient(ctx, client, secret, realm)
+		}),
+		realm:           realm,
+		client:          client,
+		secret:          secret,
+		validation:      config.Get().UString("keycloak.token-validation", _validationIntrospection),
+		actionsRedirect: config.Get().UString("keycloak.actions-email.redirect-uri"),
+		actionsLifespan: config.Get().UInt("keycloak.actions-email.lifespan", 43200),
 	}
 }
 
@@ -58,56 +102,361 @@ func (k *keycloakClient) Logout(ctx context.Context, refreshToken string) error
 	return nil
 }
 
-func (k *keycloakClient) IsValidToken(ctx context.Context, accessToken string) (bool, error) {
+func (k *keycloakClient) RefreshToken(ctx context.Context, refreshToken string) (*gocloak.JWT, error) {
+	token, err := k.host.RefreshToken(ctx, refreshToken, k.client, k.secret, k.realm)
+	if err != nil {
+		var apiErr *gocloak.APIError
+		if errors.As(err, &apiErr) && (apiErr.Code == http.StatusBadRequest || apiErr.Code == http.StatusUnauthorized) {
+			return nil, apperror.Unauthorized("refresh token is expired or revoked")
+		}
+		return nil, err
+	}
+	return token, nil
+}
+
+func (k *keycloakClient) IntrospectToken(ctx context.Context, accessToken string) (dto.TokenIntrospection, error) {
+	var introspection dto.TokenIntrospection
+	if k.validation == _validationLocal {
+		return k.validator.Validate(ctx, accessToken)
+	}
+
 	ret, err := k.host.RetrospectToken(ctx, accessToken, k.client, k.secret, k.realm)
 	if err != nil {
-		return false, err
+		return introspection, err
+	}
+	if ret.Active == nil || !*ret.Active {
+		return introspection, nil
+	}
+
+	// keycloak already vouched for the token, its claims only need decoding.
+	if _, _, err := jwt.NewParser().ParseUnverified(accessToken, &introspection.Claims); err != nil {
+		return introspection, err
+	}
+	introspection.Active = true
+	return introspection, nil
+}
+
+func (k *keycloakClient) GetUserInfo(ctx context.Context, accessToken string) (dto.UserInfo, error) {
+	var userInfo dto.UserInfo
+	profile, err := k.host.GetUserInfo(ctx, accessToken, k.realm)
+	if err != nil {
+		var apiErr *gocloak.APIError
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusUnauthorized {
+			return userInfo, apperror.Unauthorized("invalid token")
+		}
+		return userInfo, err
+	}
+	userInfo.Profile = profile
+
+	// the userinfo call already validated the token, roles only need decoding from it.
+	if _, _, err := jwt.NewParser().ParseUnverified(accessToken, &userInfo.Claims); err != nil {
+		return userInfo, err
+	}
+	return userInfo, nil
+}
+
+func (k *keycloakClient) GetUserByID(ctx context.Context, userID string) (*gocloak.User, error) {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return nil, err
 	}
+	user, err := k.host.GetUserByID(ctx, token, k.realm, userID)
+	if err != nil {
+		var apiErr *gocloak.APIError
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+			return nil, apperror.New(http.StatusNotFound, fmt.Sprintf("user %s doesn't exist", userID))
+		}
+		return nil, err
+	}
+	return user, nil
+}
 
-	return *ret.Active, nil
+func (k *keycloakClient) GetAllUsers(ctx context.Context, params gocloak.GetUsersParams) ([]*gocloak.User, error) {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return k.host.GetUsers(ctx, token, k.realm, params)
 }
 
-func (k *keycloakClient) GetUserByID(ctx context.Context, token string, userID string) (*gocloak.User, error) {
-	return k.host.GetUserByID(ctx, token, k.realm, userID)
+func (k *keycloakClient) CountUsers(ctx context.Context, params gocloak.GetUsersParams) (int, error) {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return k.host.GetUserCount(ctx, token, k.realm, params)
 }
 
-func (k *keycloakClient) GetAllUsers(ctx context.Context, token string) ([]*gocloak.User, error) {
-	return k.host.GetUsers(ctx, token, k.realm, gocloak.GetUsersParams{})
+func (k *keycloakClient) GetUserByNickName(ctx context.Context, nickName string) (*gocloak.User, error) {
+	return k.getUniqueUser(ctx, gocloak.GetUsersParams{Username: &nickName}, "nick name", nickName, func(user *gocloak.User) string {
+		return gocloak.PString(user.Username)
+	})
 }
 
-func (k *keycloakClient) GetUserByNickName(ctx context.Context, token string, nickName string) (*gocloak.User, error) {
-	users, err := k.host.GetUsers(ctx, token, k.realm, gocloak.GetUsersParams{
-		Username: &nickName,
+func (k *keycloakClient) GetUserByEmail(ctx context.Context, email string) (*gocloak.User, error) {
+	return k.getUniqueUser(ctx, gocloak.GetUsersParams{Email: &email}, "email", email, func(user *gocloak.User) string {
+		return gocloak.PString(user.Email)
 	})
+}
+
+// getUniqueUser returns the only user whose field, read by fieldOf, equals value ignoring case.
+// Keycloak's exact search is still case insensitive and older versions ignore it, so the
+// results are compared again here: nobody matching is a 404 and more than one a 409.
+func (k *keycloakClient) getUniqueUser(ctx context.Context, params gocloak.GetUsersParams, field string, value string, fieldOf func(user *gocloak.User) string) (*gocloak.User, error) {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	params.Exact = gocloak.BoolP(true)
+	users, err := k.host.GetUsers(ctx, token, k.realm, params)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(users) == 0 {
-		return nil, errors.New(fmt.Sprintf("user %s doesn't exist", nickName))
+	var matches []*gocloak.User
+	for _, user := range users {
+		if strings.EqualFold(fieldOf(user), value) {
+			matches = append(matches, user)
+		}
 	}
+	switch len(matches) {
+	case 0:
+		return nil, apperror.New(http.StatusNotFound, fmt.Sprintf("user with %s %s doesn't exist", field, value))
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, apperror.New(http.StatusConflict, fmt.Sprintf("%d users match %s %s", len(matches), field, value))
+	}
+}
 
-	return users[0], nil
+func (k *keycloakClient) GetRealmRoles(ctx context.Context) ([]*gocloak.Role, error) {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return k.host.GetRealmRoles(ctx, token, k.realm, gocloak.GetRoleParams{})
 }
 
-func (k *keycloakClient) GetRoleByID(ctx context.Context, token string, roleName string) (*gocloak.Role, error) {
-	return k.host.GetRealmRole(ctx, token, k.realm, roleName)
+// GetRealmRolesByName looks up every role in roleNames, failing with a bad request
+// when one of them doesn't exist in the realm.
+func (k *keycloakClient) GetRealmRolesByName(ctx context.Context, roleNames []string) ([]gocloak.Role, error) {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roles := make([]gocloak.Role, 0, len(roleNames))
+	for _, roleName := range roleNames {
+		role, err := k.host.GetRealmRole(ctx, token, k.realm, roleName)
+		if err != nil {
+			var apiErr *gocloak.APIError
+			if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+				return nil, apperror.New(http.StatusBadRequest, fmt.Sprintf("role %s doesn't exist", roleName))
+			}
+			return nil, err
+		}
+		roles = append(roles, *role)
+	}
+	return roles, nil
 }
 
-func (k *keycloakClient) CreateUser(ctx context.Context, token string, role *gocloak.Role, user gocloak.User) (string, error) {
-	id, err := k.host.CreateUser(ctx, token, k.realm, user)
+// GetUserRealmRoles returns the realm roles mapped directly to the user, leaving out the ones
+// it only inherits through composite roles or groups.
+func (k *keycloakClient) GetUserRealmRoles(ctx context.Context, userID string) ([]*gocloak.Role, error) {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return k.host.GetRealmRolesByUserID(ctx, token, k.realm, userID)
+}
+
+func (k *keycloakClient) AddRealmRolesToUser(ctx context.Context, userID string, roles []gocloak.Role) error {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return err
+	}
+	return k.host.AddRealmRoleToUser(ctx, token, k.realm, userID, roles)
+}
+
+func (k *keycloakClient) DeleteRealmRolesFromUser(ctx context.Context, userID string, roles []gocloak.Role) error {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return err
+	}
+	return k.host.DeleteRealmRoleFromUser(ctx, token, k.realm, userID, roles)
+}
+
+func (k *keycloakClient) GetGroups(ctx context.Context, params gocloak.GetGroupsParams) ([]*gocloak.Group, error) {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return k.host.GetGroups(ctx, token, k.realm, params)
+}
+
+func (k *keycloakClient) GetGroupByID(ctx context.Context, groupID string) (*gocloak.Group, error) {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	group, err := k.host.GetGroup(ctx, token, k.realm, groupID)
+	if err != nil {
+		var apiErr *gocloak.APIError
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+			return nil, apperror.New(http.StatusNotFound, fmt.Sprintf("group %s doesn't exist", groupID))
+		}
+		return nil, err
+	}
+	return group, nil
+}
+
+// CreateGroup creates group at the top level of the realm, or as a subgroup of parentID when it isn't empty.
+func (k *keycloakClient) CreateGroup(ctx context.Context, parentID string, group gocloak.Group) (string, error) {
+	token, err := k.serviceAccount.Token(ctx)
 	if err != nil {
 		return "", err
 	}
-	var roles []gocloak.Role
-	roles = append(roles, *role)
-	err = k.host.AddRealmRoleToUser(ctx, token, k.realm, id, roles)
+	var id string
+	if parentID == "" {
+		id, err = k.host.CreateGroup(ctx, token, k.realm, group)
+	} else {
+		id, err = k.host.CreateChildGroup(ctx, token, k.realm, parentID, group)
+	}
 	if err != nil {
+		var apiErr *gocloak.APIError
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusConflict {
+			return "", apperror.New(http.StatusConflict, fmt.Sprintf("group %s already exists", gocloak.PString(group.Name)))
+		}
 		return "", err
 	}
 	return id, nil
 }
 
-func (k *keycloakClient) DeleteUserByID(ctx context.Context, token string, userID string) error {
+func (k *keycloakClient) UpdateGroup(ctx context.Context, group gocloak.Group) error {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return err
+	}
+	return k.host.UpdateGroup(ctx, token, k.realm, group)
+}
+
+func (k *keycloakClient) DeleteGroup(ctx context.Context, groupID string) error {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return err
+	}
+	return k.host.DeleteGroup(ctx, token, k.realm, groupID)
+}
+
+func (k *keycloakClient) GetGroupMembers(ctx context.Context, groupID string, params gocloak.GetGroupsParams) ([]*gocloak.User, error) {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return k.host.GetGroupMembers(ctx, token, k.realm, groupID, params)
+}
+
+func (k *keycloakClient) GetUserGroups(ctx context.Context, userID string) ([]*gocloak.Group, error) {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return k.host.GetUserGroups(ctx, token, k.realm, userID, gocloak.GetGroupsParams{})
+}
+
+func (k *keycloakClient) AddUserToGroup(ctx context.Context, userID string, groupID string) error {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return err
+	}
+	return k.host.AddUserToGroup(ctx, token, k.realm, userID, groupID)
+}
+
+func (k *keycloakClient) DeleteUserFromGroup(ctx context.Context, userID string, groupID string) error {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return err
+	}
+	return k.host.DeleteUserFromGroup(ctx, token, k.realm, userID, groupID)
+}
+
+// CreateUser only creates the user, granting its roles is up to AddRealmRolesToUser.
+func (k *keycloakClient) CreateUser(ctx context.Context, user gocloak.User) (string, error) {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	id, err := k.host.CreateUser(ctx, token, k.realm, user)
+	if err != nil {
+		return "", userConflict(err, user)
+	}
+	return id, nil
+}
+
+func (k *keycloakClient) UpdateUser(ctx context.Context, user gocloak.User) error {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return err
+	}
+	return userConflict(k.host.UpdateUser(ctx, token, k.realm, user), user)
+}
+
+// userConflict turns keycloak's 409, "User exists with same username" or "... same email",
+// into a conflict naming the colliding field. Any other error is returned as is.
+func userConflict(err error, user gocloak.User) error {
+	var apiErr *gocloak.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusConflict {
+		return err
+	}
+	if strings.Contains(strings.ToLower(apiErr.Message), "email") {
+		return apperror.Conflict("email", fmt.Sprintf("a user with email %s already exists", gocloak.PString(user.Email)))
+	}
+	return apperror.Conflict("nick_name", fmt.Sprintf("a user with nick name %s already exists", gocloak.PString(user.Username)))
+}
+
+// SetPassword replaces the user's password. A password the realm's password policy rejects is a 400.
+func (k *keycloakClient) SetPassword(ctx context.Context, userID string, password string, temporary bool) error {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return err
+	}
+	err = k.host.SetPassword(ctx, token, userID, k.realm, password, temporary)
+	var apiErr *gocloak.APIError
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusBadRequest {
+		return apperror.New(http.StatusBadRequest, fmt.Sprintf("password rejected: %s", apiErr.Message))
+	}
+	return err
+}
+
+// SendActionsEmail emails the user a link to perform the given required actions, such as UPDATE_PASSWORD or VERIFY_EMAIL.
+func (k *keycloakClient) SendActionsEmail(ctx context.Context, userID string, actions []string) error {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return err
+	}
+	params := gocloak.ExecuteActionsEmail{
+		UserID:   &userID,
+		ClientID: &k.client,
+		Lifespan: &k.actionsLifespan,
+		Actions:  &actions,
+	}
+	if k.actionsRedirect != "" {
+		params.RedirectURI = &k.actionsRedirect
+	}
+	return k.host.ExecuteActionsEmail(ctx, token, k.realm, params)
+}
+
+func (k *keycloakClient) LogoutUserSessions(ctx context.Context, userID string) error {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return err
+	}
+	return k.host.LogoutAllSessions(ctx, token, k.realm, userID)
+}
+
+func (k *keycloakClient) DeleteUserByID(ctx context.Context, userID string) error {
+	token, err := k.serviceAccount.Token(ctx)
+	if err != nil {
+		return err
+	}
 	return k.host.DeleteUser(ctx, token, k.realm, userID)
 }
@@ -0,0 +1,135 @@
+package keycloak
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cow_sso/pkg/apperror"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_userConflict(t *testing.T) {
+	user := gocloak.User{
+		Username: gocloak.StringP("diegof"),
+		Email:    gocloak.StringP("diego@gmail.com"),
+	}
+	tests := []struct {
+		err    error
+		expErr error
+		name   string
+	}{
+		{
+			name: "no error",
+		},
+		{
+			name:   "other error",
+			err:    errors.New("some error"),
+			expErr: errors.New("some error"),
+		},
+		{
+			name:   "other keycloak error",
+			err:    &gocloak.APIError{Code: http.StatusBadRequest, Message: "400 Bad Request"},
+			expErr: &gocloak.APIError{Code: http.StatusBadRequest, Message: "400 Bad Request"},
+		},
+		{
+			name:   "same username",
+			err:    &gocloak.APIError{Code: http.StatusConflict, Message: "409 Conflict: User exists with same username"},
+			expErr: apperror.Conflict("nick_name", "a user with nick name diegof already exists"),
+		},
+		{
+			name:   "same email",
+			err:    &gocloak.APIError{Code: http.StatusConflict, Message: "409 Conflict: User exists with same email"},
+			expErr: apperror.Conflict("email", "a user with email diego@gmail.com already exists"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expErr, userConflict(tt.err, user))
+		})
+	}
+}
+
+// stubUsersClient points a client at a fake admin api answering every user search with users.
+func stubUsersClient(t *testing.T, users []gocloak.User) (*keycloakClient, *http.Request) {
+	var searched http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		searched = *r
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(users)
+	}))
+	t.Cleanup(server.Close)
+	return &keycloakClient{
+		host: gocloak.NewClient(server.URL),
+		serviceAccount: newServiceAccount(func(ctx context.Context) (*gocloak.JWT, error) {
+			return &gocloak.JWT{AccessToken: "admin", ExpiresIn: 300}, nil
+		}),
+		realm: "SSO",
+	}, &searched
+}
+
+func Test_GetUserByNickName(t *testing.T) {
+	tests := []struct {
+		expErr error
+		name   string
+		users  []gocloak.User
+		outPut string
+	}{
+		{
+			name:   "nobody matches",
+			expErr: apperror.New(http.StatusNotFound, "user with nick name ana doesn't exist"),
+		},
+		{
+			name: "only a longer nick name matches",
+			users: []gocloak.User{
+				{ID: gocloak.StringP("1"), Username: gocloak.StringP("anabel")},
+			},
+			expErr: apperror.New(http.StatusNotFound, "user with nick name ana doesn't exist"),
+		},
+		{
+			name: "exact match among substring matches",
+			users: []gocloak.User{
+				{ID: gocloak.StringP("1"), Username: gocloak.StringP("anabel")},
+				{ID: gocloak.StringP("2"), Username: gocloak.StringP("ana")},
+			},
+			outPut: "2",
+		},
+		{
+			name: "ambiguous",
+			users: []gocloak.User{
+				{ID: gocloak.StringP("1"), Username: gocloak.StringP("ana")},
+				{ID: gocloak.StringP("2"), Username: gocloak.StringP("ANA")},
+			},
+			expErr: apperror.New(http.StatusConflict, "2 users match nick name ana"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, searched := stubUsersClient(t, tt.users)
+			user, err := client.GetUserByNickName(context.Background(), "ana")
+			assert.Equal(t, tt.expErr, err)
+			if tt.outPut != "" {
+				assert.Equal(t, tt.outPut, *user.ID)
+			}
+			assert.Equal(t, "/admin/realms/SSO/users", searched.URL.Path)
+			assert.Equal(t, "ana", searched.URL.Query().Get("username"))
+			assert.Equal(t, "true", searched.URL.Query().Get("exact"))
+		})
+	}
+}
+
+func Test_GetUserByEmail(t *testing.T) {
+	client, searched := stubUsersClient(t, []gocloak.User{
+		{ID: gocloak.StringP("1"), Email: gocloak.StringP("Ana@Gmail.com")},
+	})
+	user, err := client.GetUserByEmail(context.Background(), "ana@gmail.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", *user.ID)
+	assert.Equal(t, "ana@gmail.com", searched.URL.Query().Get("email"))
+	assert.Equal(t, "true", searched.URL.Query().Get("exact"))
+}
@@ -0,0 +1,46 @@
+package keycloak
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+// _tokenRenewMargin is how long before expiry the admin token is renewed,
+// so a call never leaves with a token that dies on its way to keycloak.
+const _tokenRenewMargin = 30 * time.Second
+
+type clientLogin func(ctx context.Context) (*gocloak.JWT, error)
+
+// serviceAccount holds the admin token this service gets for itself through the
+// client_credentials grant, renewing it shortly before it expires.
+type serviceAccount struct {
+	expiresAt time.Time
+	login     clientLogin
+	token     string
+	mu        sync.Mutex
+}
+
+func newServiceAccount(login clientLogin) *serviceAccount {
+	return &serviceAccount{
+		login: login,
+	}
+}
+
+func (s *serviceAccount) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Now().Add(_tokenRenewMargin).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	jwt, err := s.login(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.token = jwt.AccessToken
+	s.expiresAt = time.Now().Add(time.Duration(jwt.ExpiresIn) * time.Second)
+	return s.token, nil
+}
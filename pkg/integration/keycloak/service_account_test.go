@@ -0,0 +1,74 @@
+package keycloak
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ServiceAccountToken(t *testing.T) {
+	tests := []struct {
+		expErr    error
+		expiresAt time.Time
+		login     clientLogin
+		name      string
+		cached    string
+		outPut    string
+		expLogins int
+	}{
+		{
+			name: "error login",
+			login: func(ctx context.Context) (*gocloak.JWT, error) {
+				return nil, errors.New("some error")
+			},
+			expErr:    errors.New("some error"),
+			expLogins: 1,
+		},
+		{
+			name: "first login",
+			login: func(ctx context.Context) (*gocloak.JWT, error) {
+				return &gocloak.JWT{AccessToken: "new", ExpiresIn: 300}, nil
+			},
+			outPut:    "new",
+			expLogins: 1,
+		},
+		{
+			name:      "cached token",
+			cached:    "old",
+			expiresAt: time.Now().Add(5 * time.Minute),
+			login: func(ctx context.Context) (*gocloak.JWT, error) {
+				return &gocloak.JWT{AccessToken: "new", ExpiresIn: 300}, nil
+			},
+			outPut: "old",
+		},
+		{
+			name:      "token about to expire",
+			cached:    "old",
+			expiresAt: time.Now().Add(10 * time.Second),
+			login: func(ctx context.Context) (*gocloak.JWT, error) {
+				return &gocloak.JWT{AccessToken: "new", ExpiresIn: 300}, nil
+			},
+			outPut:    "new",
+			expLogins: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logins := 0
+			account := newServiceAccount(func(ctx context.Context) (*gocloak.JWT, error) {
+				logins++
+				return tt.login(ctx)
+			})
+			account.token = tt.cached
+			account.expiresAt = tt.expiresAt
+			token, err := account.Token(context.Background())
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, token)
+			assert.Equal(t, tt.expLogins, logins)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package keycloak
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"cow_sso/pkg/integration/keycloak/dto"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	_validationLocal         = "local"
+	_validationIntrospection = "introspection"
+	_minKeysRefreshInterval  = time.Minute
+)
+
+var errUnknownKey = errors.New("token signed with an unknown key")
+
+type certsFetcher func(ctx context.Context) (*gocloak.CertResponse, error)
+
+// tokenValidator verifies access tokens locally against the realm's JWKS keys,
+// downloading them again only when a token references a kid it hasn't seen.
+type tokenValidator struct {
+	lastFetch time.Time
+	// fetchErr is the error of the last download, answered until the next one is allowed.
+	fetchErr error
+	keys     map[string]*rsa.PublicKey
+	fetch    certsFetcher
+	parser   *jwt.Parser
+	issuer   string
+	audience string
+	mu       sync.RWMutex
+}
+
+func newTokenValidator(fetch certsFetcher, issuer string, audience string) *tokenValidator {
+	return &tokenValidator{
+		keys:     map[string]*rsa.PublicKey{},
+		fetch:    fetch,
+		parser:   jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "RS384", "RS512"})),
+		issuer:   issuer,
+		audience: audience,
+	}
+}
+
+// Validate reports whether accessToken is signed by the realm and currently usable,
+// along with its claims. Only a failure to download the realm keys is returned as an error.
+func (v *tokenValidator) Validate(ctx context.Context, accessToken string) (dto.TokenIntrospection, error) {
+	var introspection dto.TokenIntrospection
+	var claims dto.TokenClaims
+	var keysErr error
+	_, err := v.parser.ParseWithClaims(accessToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := v.key(ctx, kid)
+		if err != nil && !errors.Is(err, errUnknownKey) {
+			keysErr = err
+		}
+		return key, err
+	})
+	if keysErr != nil {
+		return introspection, keysErr
+	}
+	if err != nil {
+		return introspection, nil
+	}
+
+	if claims.ExpiresAt == nil || !claims.VerifyIssuer(v.issuer, true) || !v.meantForUs(claims) {
+		return introspection, nil
+	}
+	introspection.Active = true
+	introspection.Claims = claims
+	return introspection, nil
+}
+
+// meantForUs reports whether the token names the audience in aud or was issued to it. Keycloak puts
+// account in aud and the client in azp unless the realm has an audience mapper for the client.
+func (v *tokenValidator) meantForUs(claims dto.TokenClaims) bool {
+	return claims.VerifyAudience(v.audience, true) || claims.AuthorizedParty == v.audience
+}
+
+func (v *tokenValidator) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.RLock()
+	key, ok := v.keys[kid]
+	v.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(v.lastFetch) < _minKeysRefreshInterval {
+		if v.fetchErr != nil {
+			return nil, v.fetchErr
+		}
+		return nil, errUnknownKey
+	}
+
+	if err := v.refresh(ctx); err != nil {
+		return nil, err
+	}
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, errUnknownKey
+}
+
+// refresh must be called with the write lock held. Failed downloads count as an attempt too,
+// so an unreachable keycloak isn't asked again for every token until the interval passes.
+func (v *tokenValidator) refresh(ctx context.Context) error {
+	certs, err := v.fetch(ctx)
+	v.lastFetch = time.Now()
+	v.fetchErr = err
+	if err != nil {
+		return err
+	}
+	if certs.Keys == nil {
+		return nil
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, cert := range *certs.Keys {
+		if cert.Kid == nil || cert.Kty == nil || *cert.Kty != "RSA" || cert.N == nil || cert.E == nil {
+			continue
+		}
+		if cert.Use != nil && *cert.Use != "sig" {
+			continue
+		}
+		key, err := rsaPublicKey(*cert.N, *cert.E)
+		if err != nil {
+			continue
+		}
+		keys[*cert.Kid] = key
+	}
+	v.keys = keys
+	return nil
+}
+
+func rsaPublicKey(n string, e string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key modulus: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key exponent: %w", err)
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
@@ -0,0 +1,246 @@
+package keycloak
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"cow_sso/pkg/integration/keycloak/dto"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	_testIssuer   = "http://localhost:8080/realms/SSO"
+	_testAudience = "cow"
+)
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.Claims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	assert.NoError(t, err)
+	return signed
+}
+
+func certsFor(key *rsa.PrivateKey, kid string) *gocloak.CertResponse {
+	n := base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	return &gocloak.CertResponse{
+		Keys: &[]gocloak.CertResponseKey{
+			{
+				Kid: gocloak.StringP(kid),
+				Kty: gocloak.StringP("RSA"),
+				Use: gocloak.StringP("sig"),
+				N:   &n,
+				E:   &e,
+			},
+		},
+	}
+}
+
+func validClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Issuer:    _testIssuer,
+		Audience:  jwt.ClaimStrings{_testAudience},
+		Subject:   "abc",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+}
+
+func Test_Validate(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	tests := []struct {
+		expErr error
+		fetch  certsFetcher
+		claims func() jwt.RegisteredClaims
+		signer *rsa.PrivateKey
+		name   string
+		kid    string
+		azp    string
+		outPut bool
+	}{
+		{
+			name:   "valid token",
+			kid:    "k1",
+			signer: key,
+			claims: validClaims,
+			outPut: true,
+		},
+		{
+			name:   "expired token",
+			kid:    "k1",
+			signer: key,
+			claims: func() jwt.RegisteredClaims {
+				c := validClaims()
+				c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+				return c
+			},
+		},
+		{
+			name:   "token not valid yet",
+			kid:    "k1",
+			signer: key,
+			claims: func() jwt.RegisteredClaims {
+				c := validClaims()
+				c.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Minute))
+				return c
+			},
+		},
+		{
+			name:   "token without exp",
+			kid:    "k1",
+			signer: key,
+			claims: func() jwt.RegisteredClaims {
+				c := validClaims()
+				c.ExpiresAt = nil
+				return c
+			},
+		},
+		{
+			name:   "wrong issuer",
+			kid:    "k1",
+			signer: key,
+			claims: func() jwt.RegisteredClaims {
+				c := validClaims()
+				c.Issuer = "http://evil/realms/SSO"
+				return c
+			},
+		},
+		{
+			name:   "wrong audience",
+			kid:    "k1",
+			signer: key,
+			claims: func() jwt.RegisteredClaims {
+				c := validClaims()
+				c.Audience = jwt.ClaimStrings{"account"}
+				return c
+			},
+		},
+		{
+			name:   "client only in azp",
+			kid:    "k1",
+			signer: key,
+			azp:    _testAudience,
+			claims: func() jwt.RegisteredClaims {
+				c := validClaims()
+				c.Audience = jwt.ClaimStrings{"account"}
+				return c
+			},
+			outPut: true,
+		},
+		{
+			name:   "another client in azp",
+			kid:    "k1",
+			signer: key,
+			azp:    "other",
+			claims: func() jwt.RegisteredClaims {
+				c := validClaims()
+				c.Audience = jwt.ClaimStrings{"account"}
+				return c
+			},
+		},
+		{
+			name:   "bad signature",
+			kid:    "k1",
+			signer: otherKey,
+			claims: validClaims,
+		},
+		{
+			name:   "unknown kid",
+			kid:    "k2",
+			signer: key,
+			claims: validClaims,
+		},
+		{
+			name:   "error fetching certs",
+			kid:    "k1",
+			signer: key,
+			claims: validClaims,
+			fetch: func(ctx context.Context) (*gocloak.CertResponse, error) {
+				return nil, errors.New("some error")
+			},
+			expErr: errors.New("some error"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetch := tt.fetch
+			if fetch == nil {
+				fetch = func(ctx context.Context) (*gocloak.CertResponse, error) {
+					return certsFor(key, "k1"), nil
+				}
+			}
+			validator := newTokenValidator(fetch, _testIssuer, _testAudience)
+			claims := dto.TokenClaims{RegisteredClaims: tt.claims(), AuthorizedParty: tt.azp}
+			introspection, err := validator.Validate(context.Background(), signToken(t, tt.signer, tt.kid, claims))
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, introspection.Active)
+			if tt.outPut {
+				assert.Equal(t, "abc", introspection.Claims.Subject)
+			}
+		})
+	}
+}
+
+func Test_ValidateRefreshesKeysOnUnknownKid(t *testing.T) {
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	fetches := 0
+	validator := newTokenValidator(func(ctx context.Context) (*gocloak.CertResponse, error) {
+		fetches++
+		if fetches == 1 {
+			return certsFor(oldKey, "old"), nil
+		}
+		return certsFor(newKey, "new"), nil
+	}, _testIssuer, _testAudience)
+
+	introspection, err := validator.Validate(context.Background(), signToken(t, oldKey, "old", validClaims()))
+	assert.NoError(t, err)
+	assert.True(t, introspection.Active)
+
+	introspection, err = validator.Validate(context.Background(), signToken(t, oldKey, "old", validClaims()))
+	assert.NoError(t, err)
+	assert.True(t, introspection.Active)
+	assert.Equal(t, 1, fetches)
+
+	validator.lastFetch = time.Time{}
+	introspection, err = validator.Validate(context.Background(), signToken(t, newKey, "new", validClaims()))
+	assert.NoError(t, err)
+	assert.True(t, introspection.Active)
+	assert.Equal(t, 2, fetches)
+}
+
+func Test_ValidateBacksOffAfterFailedFetch(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	fetches := 0
+	validator := newTokenValidator(func(ctx context.Context) (*gocloak.CertResponse, error) {
+		fetches++
+		if fetches == 1 {
+			return nil, errors.New("keycloak is down")
+		}
+		return certsFor(key, "kid"), nil
+	}, _testIssuer, _testAudience)
+
+	for i := 0; i < 3; i++ {
+		_, err := validator.Validate(context.Background(), signToken(t, key, "kid", validClaims()))
+		assert.Equal(t, errors.New("keycloak is down"), err)
+	}
+	assert.Equal(t, 1, fetches)
+
+	validator.lastFetch = time.Time{}
+	introspection, err := validator.Validate(context.Background(), signToken(t, key, "kid", validClaims()))
+	assert.NoError(t, err)
+	assert.True(t, introspection.Active)
+	assert.Equal(t, 2, fetches)
+}
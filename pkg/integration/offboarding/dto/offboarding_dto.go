@@ -0,0 +1,33 @@
+package dto
+
+import "time"
+
+const (
+	StatusRunning   = "running"
+	StatusFailed    = "failed"
+	StatusCompleted = "completed"
+
+	StepPending = "pending"
+	StepDone    = "done"
+	StepFailed  = "failed"
+)
+
+// Offboarding is the persisted state of a user's offboarding, kept so a failed run can resume
+// from the step that failed instead of starting over.
+type Offboarding struct {
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    string    `json:"user_id"`
+	NickName  string    `json:"nick_name"`
+	Status    string    `json:"status"`
+	// Teams are the codes of the teams the user belonged to when the offboarding started.
+	Teams []string `json:"teams,omitempty"`
+	Steps []Step   `json:"steps"`
+}
+
+type Step struct {
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+}
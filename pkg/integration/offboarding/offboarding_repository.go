@@ -0,0 +1,84 @@
+package offboarding
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"cow_sso/pkg/config"
+	"cow_sso/pkg/integration/offboarding/dto"
+)
+
+const _defaultDir = "data/offboarding"
+
+type IOffboardingRepository interface {
+	Get(nickName string) (dto.Offboarding, bool, error)
+	Save(offboarding dto.Offboarding) error
+}
+
+// offboardingRepository keeps every offboarding as a json file named after the user's nick name,
+// which outlives the keycloak user it belonged to.
+type offboardingRepository struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewOffboardingRepository() IOffboardingRepository {
+	return &offboardingRepository{
+		dir: config.Get().UString("offboarding.dir", _defaultDir),
+	}
+}
+
+// Get returns the last offboarding of the user, reporting false when there never was one.
+func (r *offboardingRepository) Get(nickName string) (dto.Offboarding, bool, error) {
+	var offboarding dto.Offboarding
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := os.ReadFile(r.path(nickName))
+	if errors.Is(err, os.ErrNotExist) {
+		return offboarding, false, nil
+	}
+	if err != nil {
+		return offboarding, false, err
+	}
+	if err := json.Unmarshal(b, &offboarding); err != nil {
+		return offboarding, false, err
+	}
+	return offboarding, true, nil
+}
+
+// Save replaces the stored offboarding through a rename, so a crash never leaves half a file behind.
+func (r *offboardingRepository) Save(offboarding dto.Offboarding) error {
+	b, err := json.MarshalIndent(offboarding, "", "  ")
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(r.dir, 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(r.dir, "offboarding-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path(offboarding.NickName))
+}
+
+func (r *offboardingRepository) path(nickName string) string {
+	return filepath.Join(r.dir, url.PathEscape(strings.ToLower(nickName))+".json")
+}
@@ -0,0 +1,55 @@
+package offboarding
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cow_sso/pkg/integration/offboarding/dto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_OffboardingRepository(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "offboarding")
+	r := &offboardingRepository{dir: dir}
+
+	_, found, err := r.Get("diegof")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	startedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	offboarding := dto.Offboarding{
+		StartedAt: startedAt,
+		UpdatedAt: startedAt,
+		UserID:    "abcde8",
+		NickName:  "DiegoF",
+		Status:    dto.StatusRunning,
+		Steps:     []dto.Step{{Name: "revoke_sessions", Status: dto.StepPending}},
+	}
+	assert.NoError(t, r.Save(offboarding))
+
+	offboarding.Status = dto.StatusCompleted
+	offboarding.Steps[0].Status = dto.StepDone
+	assert.NoError(t, r.Save(offboarding))
+
+	stored, found, err := r.Get("diegof")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, offboarding, stored)
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func Test_OffboardingRepository_corrupted(t *testing.T) {
+	dir := t.TempDir()
+	r := &offboardingRepository{dir: dir}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "diegof.json"), []byte("{"), 0o600))
+
+	_, found, err := r.Get("diegof")
+	assert.Error(t, err)
+	assert.False(t, found)
+}
@@ -1,6 +1,7 @@
 package restful
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -11,6 +12,8 @@ import (
 
 type IRestClient interface {
 	Get(ctx context.Context, url string, timeOut string) ([]byte, error)
+	Post(ctx context.Context, url string, body []byte, timeOut string) ([]byte, error)
+	Delete(ctx context.Context, url string, timeOut string) ([]byte, error)
 }
 
 type restClient struct{}
@@ -20,13 +23,33 @@ func NewRestClient() IRestClient {
 }
 
 func (rs *restClient) Get(ctx context.Context, url string, timeOut string) ([]byte, error) {
+	return rs.do(ctx, http.MethodGet, url, nil, timeOut)
+}
+
+// Post sends body as json.
+func (rs *restClient) Post(ctx context.Context, url string, body []byte, timeOut string) ([]byte, error) {
+	return rs.do(ctx, http.MethodPost, url, body, timeOut)
+}
+
+func (rs *restClient) Delete(ctx context.Context, url string, timeOut string) ([]byte, error) {
+	return rs.do(ctx, http.MethodDelete, url, nil, timeOut)
+}
+
+func (rs *restClient) do(ctx context.Context, method string, url string, body []byte, timeOut string) ([]byte, error) {
 	to, _ := time.ParseDuration(timeOut)
 	ctx, cancel := context.WithTimeout(ctx, to)
 	defer cancel()
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
 		return nil, err
 	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -34,15 +57,15 @@ func (rs *restClient) Get(ctx context.Context, url string, timeOut string) ([]by
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode > 400 {
+	if resp.StatusCode >= http.StatusBadRequest {
 		code := strings.ReplaceAll(strings.ToLower(http.StatusText(resp.StatusCode)), " ", "_")
-		return nil, fmt.Errorf("%d %s: %s", resp.StatusCode, code, string(body))
+		return nil, fmt.Errorf("%d %s: %s", resp.StatusCode, code, string(respBody))
 	}
 
-	return body, nil
+	return respBody, nil
 }
@@ -0,0 +1,82 @@
+package restful
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Do(t *testing.T) {
+	tests := []struct {
+		expErr  error
+		name    string
+		method  string
+		body    []byte
+		outPut  []byte
+		resBody string
+		resCode int
+	}{
+		{
+			name:    "get",
+			method:  http.MethodGet,
+			resCode: http.StatusOK,
+			resBody: `{"teams":[]}`,
+			outPut:  []byte(`{"teams":[]}`),
+		},
+		{
+			name:    "post sends the body",
+			method:  http.MethodPost,
+			body:    []byte(`{"user_id":"1"}`),
+			resCode: http.StatusNoContent,
+			outPut:  []byte{},
+		},
+		{
+			name:    "bad request",
+			method:  http.MethodDelete,
+			resCode: http.StatusBadRequest,
+			resBody: "invalid user",
+			expErr:  errors.New("400 bad_request: invalid user"),
+		},
+		{
+			name:    "server error",
+			method:  http.MethodGet,
+			resCode: http.StatusInternalServerError,
+			resBody: "boom",
+			expErr:  errors.New("500 internal_server_error: boom"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.method, r.Method)
+				if tt.body != nil {
+					body, _ := io.ReadAll(r.Body)
+					assert.Equal(t, tt.body, body)
+					assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				}
+				w.WriteHeader(tt.resCode)
+				_, _ = w.Write([]byte(tt.resBody))
+			}))
+			defer server.Close()
+
+			client := NewRestClient()
+			var res []byte
+			var err error
+			switch tt.method {
+			case http.MethodGet:
+				res, err = client.Get(context.Background(), server.URL, "1s")
+			case http.MethodPost:
+				res, err = client.Post(context.Background(), server.URL, tt.body, "1s")
+			case http.MethodDelete:
+				res, err = client.Delete(context.Background(), server.URL, "1s")
+			}
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, res)
+		})
+	}
+}
@@ -8,3 +8,10 @@ type TeamResponse struct {
 	Code string `json:"code"`
 	Debt int    `json:"debt"`
 }
+
+// UserDeletedNotification tells the team api that a user who still belonged to teams was deleted.
+type UserDeletedNotification struct {
+	UserID   string   `json:"user_id"`
+	NickName string   `json:"nick_name"`
+	Teams    []string `json:"teams"`
+}
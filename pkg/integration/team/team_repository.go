@@ -11,11 +11,14 @@ import (
 )
 
 const (
-	_getTeamsByUser = "/teams/user"
+	_getTeamsByUser   = "/teams/user"
+	_notifyUserDelete = "/teams/user/deleted"
 )
 
 type ITeamClient interface {
 	GetTeamsByUser(ctx context.Context, userID string) (dto.TeamsByUserResponse, error)
+	RemoveUserFromTeams(ctx context.Context, userID string) error
+	NotifyUserDeleted(ctx context.Context, notification dto.UserDeletedNotification) error
 }
 
 type teamClient struct {
@@ -45,3 +48,23 @@ func (t *teamClient) GetTeamsByUser(ctx context.Context, userID string) (dto.Tea
 
 	return teams, nil
 }
+
+// RemoveUserFromTeams asks the team api to drop every membership of the user.
+func (t *teamClient) RemoveUserFromTeams(ctx context.Context, userID string) error {
+	url := fmt.Sprintf("%s%s/%s", config.Get().UString("cow-api.url"), _getTeamsByUser, userID)
+	timeOut := config.Get().UString("cow-api.timeout")
+	_, err := t.restfulService.Delete(ctx, url, timeOut)
+	return err
+}
+
+func (t *teamClient) NotifyUserDeleted(ctx context.Context, notification dto.UserDeletedNotification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s%s", config.Get().UString("cow-api.url"), _notifyUserDelete)
+	timeOut := config.Get().UString("cow-api.timeout")
+	_, err = t.restfulService.Post(ctx, url, body, timeOut)
+	return err
+}
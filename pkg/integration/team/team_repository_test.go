@@ -6,6 +6,7 @@ import (
 	"cow_sso/pkg/integration/team/dto"
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -91,3 +92,90 @@ func Test_GetTeamsByUser(t *testing.T) {
 		})
 	}
 }
+
+func Test_NotifyUserDeleted(t *testing.T) {
+	notification := dto.UserDeletedNotification{
+		UserID:   "ABC",
+		NickName: "diegof",
+		Teams:    []string{"XYZ"},
+	}
+	body, _ := json.Marshal(notification)
+
+	tests := []struct {
+		name   string
+		mocks  teamMocks
+		expErr error
+	}{
+		{
+			name: "error integration",
+			mocks: teamMocks{
+				func(f *mockTeamClient) {
+					f.restfulService.On("Post", mock.Anything, mock.Anything, body, "5s").Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "full flow",
+			mocks: teamMocks{
+				func(f *mockTeamClient) {
+					f.restfulService.On("Post", mock.Anything, mock.MatchedBy(func(url string) bool {
+						return strings.HasSuffix(url, "/teams/user/deleted")
+					}), body, "5s").Return([]byte{}, nil)
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockTeamClient{
+				restfulService: &mocks.IRestClient{},
+			}
+			tt.mocks.teamClient(m)
+			r := NewTeamClient(m.restfulService)
+			err := r.NotifyUserDeleted(context.Background(), notification)
+			assert.Equal(t, tt.expErr, err)
+			m.restfulService.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_RemoveUserFromTeams(t *testing.T) {
+	tests := []struct {
+		name   string
+		mocks  teamMocks
+		expErr error
+	}{
+		{
+			name: "error integration",
+			mocks: teamMocks{
+				func(f *mockTeamClient) {
+					f.restfulService.On("Delete", mock.Anything, mock.Anything, "5s").Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "full flow",
+			mocks: teamMocks{
+				func(f *mockTeamClient) {
+					f.restfulService.On("Delete", mock.Anything, mock.MatchedBy(func(url string) bool {
+						return strings.HasSuffix(url, "/teams/user/ABC")
+					}), "5s").Return([]byte{}, nil)
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockTeamClient{
+				restfulService: &mocks.IRestClient{},
+			}
+			tt.mocks.teamClient(m)
+			r := NewTeamClient(m.restfulService)
+			err := r.RemoveUserFromTeams(context.Background(), "ABC")
+			assert.Equal(t, tt.expErr, err)
+			m.restfulService.AssertExpectations(t)
+		})
+	}
+}
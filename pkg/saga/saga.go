@@ -0,0 +1,73 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Step is one action of a saga, along with the compensation that undoes it. Steps whose
+// effect doesn't need undoing, or that run last, can leave Compensate nil.
+type Step struct {
+	Action     func(ctx context.Context) error
+	Compensate func(ctx context.Context) error
+	Name       string
+}
+
+// Saga runs steps in order. When one fails, the steps that already succeeded are compensated
+// in reverse order, so the saga either completes or leaves nothing half done behind.
+type Saga struct {
+	steps []Step
+}
+
+func New(steps ...Step) *Saga {
+	return &Saga{steps: steps}
+}
+
+// Add appends step, for steps that only apply to some runs.
+func (s *Saga) Add(step Step) {
+	s.steps = append(s.steps, step)
+}
+
+// Run runs the steps, returning an *Error when one fails. Compensations run even when ctx
+// has been canceled, an aborted request shouldn't leave the saga half applied.
+func (s *Saga) Run(ctx context.Context) error {
+	for i, step := range s.steps {
+		if err := step.Action(ctx); err != nil {
+			sagaErr := &Error{Step: step.Name, Err: err}
+			compensateCtx := context.WithoutCancel(ctx)
+			for j := i - 1; j >= 0; j-- {
+				if s.steps[j].Compensate == nil {
+					continue
+				}
+				if err := s.steps[j].Compensate(compensateCtx); err != nil {
+					sagaErr.Compensations = append(sagaErr.Compensations, fmt.Errorf("undoing %s: %w", s.steps[j].Name, err))
+				}
+			}
+			return sagaErr
+		}
+	}
+	return nil
+}
+
+// Error tells which step failed and which compensations failed too, if any. It unwraps to the
+// step's error, so callers can still tell what kind of failure it was.
+type Error struct {
+	Err           error
+	Step          string
+	Compensations []error
+}
+
+func (e *Error) Error() string {
+	message := fmt.Sprintf("%s: %s", e.Step, e.Err.Error())
+	if len(e.Compensations) > 0 {
+		message += fmt.Sprintf(", and rolling back failed: %s", errors.Join(e.Compensations...).Error())
+		message = strings.ReplaceAll(message, "\n", "; ")
+	}
+	return message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,100 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"cow_sso/pkg/apperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Run(t *testing.T) {
+	tests := []struct {
+		expErr   error
+		name     string
+		failAt   string
+		failUndo string
+		expCalls []string
+	}{
+		{
+			name:     "full flow",
+			expCalls: []string{"do a", "do b", "do c"},
+		},
+		{
+			name:     "first step fails",
+			failAt:   "a",
+			expErr:   &Error{Step: "a", Err: errors.New("a failed")},
+			expCalls: []string{"do a"},
+		},
+		{
+			name:     "compensates in reverse order",
+			failAt:   "c",
+			expErr:   &Error{Step: "c", Err: errors.New("c failed")},
+			expCalls: []string{"do a", "do b", "do c", "undo a"},
+		},
+		{
+			name:     "compensation fails",
+			failAt:   "c",
+			failUndo: "a",
+			expErr: &Error{
+				Step:          "c",
+				Err:           errors.New("c failed"),
+				Compensations: []error{errors.New("undoing a: undo a failed")},
+			},
+			expCalls: []string{"do a", "do b", "do c", "undo a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			step := func(name string, undo bool) Step {
+				step := Step{
+					Name: name,
+					Action: func(ctx context.Context) error {
+						calls = append(calls, "do "+name)
+						if tt.failAt == name {
+							return errors.New(name + " failed")
+						}
+						return nil
+					},
+				}
+				if undo {
+					step.Compensate = func(ctx context.Context) error {
+						assert.NoError(t, ctx.Err())
+						calls = append(calls, "undo "+name)
+						if tt.failUndo == name {
+							return errors.New("undo " + name + " failed")
+						}
+						return nil
+					}
+				}
+				return step
+			}
+
+			s := New(step("a", true), step("b", false))
+			s.Add(step("c", true))
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			err := s.Run(ctx)
+			if tt.expErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expErr.Error())
+			}
+			assert.Equal(t, tt.expCalls, calls)
+		})
+	}
+}
+
+func Test_Error(t *testing.T) {
+	err := &Error{
+		Step:          "assign_roles",
+		Err:           apperror.New(http.StatusBadRequest, "role x doesn't exist"),
+		Compensations: []error{errors.New("undoing create_user: timeout")},
+	}
+	assert.Equal(t, "assign_roles: role x doesn't exist, and rolling back failed: undoing create_user: timeout", err.Error())
+	assert.Equal(t, http.StatusBadRequest, apperror.Code(err, http.StatusInternalServerError))
+}
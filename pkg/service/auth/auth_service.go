@@ -2,16 +2,29 @@ package auth
 
 import (
 	"context"
+	"net/http"
 
 	"cow_sso/api/handlers/auth/request"
 	"cow_sso/api/handlers/auth/response"
+	userResponse "cow_sso/api/handlers/user/response"
+	"cow_sso/pkg/apperror"
 	"cow_sso/pkg/integration/keycloak"
+	"cow_sso/pkg/integration/keycloak/dto"
+
+	"github.com/Nerzal/gocloak/v13"
 )
 
+// _forgotPasswordActions are the actions emailed to users who forgot their password.
+var _forgotPasswordActions = []string{"UPDATE_PASSWORD"}
+
 type IAuthService interface {
 	Login(ctx context.Context, authRequest request.AuthRequest) (response.AuthResponse, error)
 	Logout(ctx context.Context, refreshTokenRequest request.RefreshTokenRequest) error
-	IsValidToken(ctx context.Context, accessToken string) (bool, error)
+	RefreshToken(ctx context.Context, refreshTokenRequest request.RefreshTokenRequest) (response.AuthResponse, error)
+	IntrospectToken(ctx context.Context, accessToken string) (response.TokenResponse, error)
+	GetUserInfo(ctx context.Context, accessToken string) (response.UserInfoResponse, error)
+	ChangePassword(ctx context.Context, userID string, nickName string, changeRequest request.ChangePasswordRequest) error
+	ForgotPassword(ctx context.Context, forgotRequest request.ForgotPasswordRequest) error
 }
 
 type authService struct {
@@ -30,17 +43,118 @@ func (a *authService) Login(ctx context.Context, authRequest request.AuthRequest
 	if err != nil {
 		return authResponse, err
 	}
-	authResponse.Token = token.AccessToken
-	authResponse.ExpiresIn = token.ExpiresIn
-	authResponse.RefreshToken = token.RefreshToken
-	authResponse.RefreshExpiresIn = token.RefreshExpiresIn
-	return authResponse, nil
+	return toAuthResponse(token), nil
 }
 
 func (a *authService) Logout(ctx context.Context, refreshTokenRequest request.RefreshTokenRequest) error {
 	return a.keycloakClient.Logout(ctx, refreshTokenRequest.RefreshToken)
 }
 
-func (a *authService) IsValidToken(ctx context.Context, accessToken string) (bool, error) {
-	return a.keycloakClient.IsValidToken(ctx, accessToken)
+func (a *authService) RefreshToken(ctx context.Context, refreshTokenRequest request.RefreshTokenRequest) (response.AuthResponse, error) {
+	var authResponse response.AuthResponse
+	token, err := a.keycloakClient.RefreshToken(ctx, refreshTokenRequest.RefreshToken)
+	if err != nil {
+		return authResponse, err
+	}
+	return toAuthResponse(token), nil
+}
+
+func (a *authService) IntrospectToken(ctx context.Context, accessToken string) (response.TokenResponse, error) {
+	var tokenResponse response.TokenResponse
+	introspection, err := a.keycloakClient.IntrospectToken(ctx, accessToken)
+	if err != nil || !introspection.Active {
+		return tokenResponse, err
+	}
+
+	claims := introspection.Claims
+	tokenResponse.Active = true
+	tokenResponse.Sub = claims.Subject
+	tokenResponse.Username = claims.Username
+	tokenResponse.Email = claims.Email
+	tokenResponse.Scope = claims.Scope
+	tokenResponse.RealmRoles = claims.RealmAccess.Roles
+	tokenResponse.ClientRoles = clientRoles(claims)
+	if claims.ExpiresAt != nil {
+		tokenResponse.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		tokenResponse.Iat = claims.IssuedAt.Unix()
+	}
+	return tokenResponse, nil
+}
+
+func (a *authService) GetUserInfo(ctx context.Context, accessToken string) (response.UserInfoResponse, error) {
+	var userInfoResponse response.UserInfoResponse
+	userInfo, err := a.keycloakClient.GetUserInfo(ctx, accessToken)
+	if err != nil {
+		return userInfoResponse, err
+	}
+
+	profile := userInfo.Profile
+	userInfoResponse.UserResponse = userResponse.UserResponse{
+		ID:            gocloak.PString(profile.Sub),
+		Name:          gocloak.PString(profile.GivenName),
+		LastName:      gocloak.PString(profile.FamilyName),
+		Email:         gocloak.PString(profile.Email),
+		NickName:      gocloak.PString(profile.PreferredUsername),
+		EmailVerified: gocloak.PBool(profile.EmailVerified),
+		// keycloak refuses the userinfo of disabled users, so whoever gets here is enabled
+		Enabled: true,
+	}
+	userInfoResponse.RealmRoles = userInfo.Claims.RealmAccess.Roles
+	userInfoResponse.ClientRoles = clientRoles(userInfo.Claims)
+	return userInfoResponse, nil
+}
+
+// ChangePassword sets the user's new password once a login with the current one proves it's the user asking.
+func (a *authService) ChangePassword(ctx context.Context, userID string, nickName string, changeRequest request.ChangePasswordRequest) error {
+	if changeRequest.NewPassword == changeRequest.CurrentPassword {
+		return apperror.New(http.StatusBadRequest, "the new password must be different from the current one")
+	}
+	token, err := a.keycloakClient.Login(ctx, nickName, changeRequest.CurrentPassword)
+	if err != nil {
+		return apperror.New(http.StatusForbidden, "current password is incorrect")
+	}
+	// the login was only a check, a failure ending its session just leaves it to expire
+	_ = a.keycloakClient.Logout(ctx, token.RefreshToken)
+	return a.keycloakClient.SetPassword(ctx, userID, changeRequest.NewPassword, false)
+}
+
+// ForgotPassword emails the user with forgotRequest's email a link to choose a new password.
+// Unknown and disabled users are skipped without an error, so callers can't tell who has an account.
+func (a *authService) ForgotPassword(ctx context.Context, forgotRequest request.ForgotPasswordRequest) error {
+	user, err := a.keycloakClient.GetUserByEmail(ctx, forgotRequest.Email)
+	if err != nil {
+		if apperror.Code(err, http.StatusInternalServerError) == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	if !gocloak.PBool(user.Enabled) {
+		return nil
+	}
+	return a.keycloakClient.SendActionsEmail(ctx, gocloak.PString(user.ID), _forgotPasswordActions)
+}
+
+func toAuthResponse(token *gocloak.JWT) response.AuthResponse {
+	return response.AuthResponse{
+		Token:            token.AccessToken,
+		ExpiresIn:        token.ExpiresIn,
+		RefreshToken:     token.RefreshToken,
+		RefreshExpiresIn: token.RefreshExpiresIn,
+	}
+}
+
+func clientRoles(claims dto.TokenClaims) map[string][]string {
+	var roles map[string][]string
+	for client, access := range claims.ResourceAccess {
+		if len(access.Roles) == 0 {
+			continue
+		}
+		if roles == nil {
+			roles = map[string][]string{}
+		}
+		roles[client] = access.Roles
+	}
+	return roles
 }
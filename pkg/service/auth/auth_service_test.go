@@ -3,13 +3,19 @@ package auth
 import (
 	"context"
 	"errors"
+	"net/http"
 	"testing"
+	"time"
 
 	"cow_sso/api/handlers/auth/request"
 	"cow_sso/api/handlers/auth/response"
+	userResponse "cow_sso/api/handlers/user/response"
 	"cow_sso/mocks"
+	"cow_sso/pkg/apperror"
+	"cow_sso/pkg/integration/keycloak/dto"
 
 	"github.com/Nerzal/gocloak/v13"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -131,33 +137,207 @@ func Test_Logout(t *testing.T) {
 	}
 }
 
-func Test_IsValidToken(t *testing.T) {
+func Test_RefreshToken(t *testing.T) {
+	tests := []struct {
+		name                string
+		refreshTokenRequest request.RefreshTokenRequest
+		mocks               authMocks
+		outPut              response.AuthResponse
+		expErr              error
+	}{
+		{
+			name: "refresh fail",
+			refreshTokenRequest: request.RefreshTokenRequest{
+				RefreshToken: "refresh_token",
+			},
+			mocks: authMocks{
+				func(f *mockAuthService) {
+					f.keycloakClient.On("RefreshToken", mock.Anything, "refresh_token").Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "refresh success",
+			refreshTokenRequest: request.RefreshTokenRequest{
+				RefreshToken: "refresh_token",
+			},
+			mocks: authMocks{
+				func(f *mockAuthService) {
+					x := gocloak.JWT{
+						AccessToken:      "token",
+						ExpiresIn:        300,
+						RefreshToken:     "new_refresh_token",
+						RefreshExpiresIn: 1800,
+					}
+					f.keycloakClient.On("RefreshToken", mock.Anything, "refresh_token").Return(&x, nil)
+				},
+			},
+			outPut: response.AuthResponse{
+				Token:            "token",
+				ExpiresIn:        300,
+				RefreshToken:     "new_refresh_token",
+				RefreshExpiresIn: 1800,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockAuthService{
+				keycloakClient: &mocks.IKeycloakClient{},
+			}
+			tt.mocks.authService(m)
+			service := NewAuthService(m.keycloakClient)
+			auth, err := service.RefreshToken(context.Background(), tt.refreshTokenRequest)
+			if err != nil {
+				assert.Equal(t, tt.expErr, err)
+			}
+			assert.Equal(t, tt.outPut, auth)
+		})
+	}
+}
+
+func Test_IntrospectToken(t *testing.T) {
+	tests := []struct {
+		name        string
+		accessToken string
+		mocks       authMocks
+		expErr      error
+		outPut      response.TokenResponse
+	}{
+		{
+			name:        "error introspecting token",
+			accessToken: "abc",
+			mocks: authMocks{
+				func(f *mockAuthService) {
+					f.keycloakClient.On("IntrospectToken", mock.Anything, "abc").Return(dto.TokenIntrospection{}, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name:        "inactive token",
+			accessToken: "abc",
+			mocks: authMocks{
+				func(f *mockAuthService) {
+					f.keycloakClient.On("IntrospectToken", mock.Anything, "abc").Return(dto.TokenIntrospection{}, nil)
+				},
+			},
+		},
+		{
+			name:        "active token",
+			accessToken: "abc",
+			mocks: authMocks{
+				func(f *mockAuthService) {
+					f.keycloakClient.On("IntrospectToken", mock.Anything, "abc").Return(dto.TokenIntrospection{
+						Active: true,
+						Claims: dto.TokenClaims{
+							Username: "diegof",
+							Email:    "diego@gmail.com",
+							Scope:    "openid email",
+							RealmAccess: dto.RolesClaim{
+								Roles: []string{"user"},
+							},
+							ResourceAccess: map[string]dto.RolesClaim{
+								"cow":     {Roles: []string{"admin"}},
+								"account": {},
+							},
+							RegisteredClaims: jwt.RegisteredClaims{
+								Subject:   "abcde8",
+								ExpiresAt: jwt.NewNumericDate(time.Unix(1700000300, 0)),
+								IssuedAt:  jwt.NewNumericDate(time.Unix(1700000000, 0)),
+							},
+						},
+					}, nil)
+				},
+			},
+			outPut: response.TokenResponse{
+				Active:     true,
+				Sub:        "abcde8",
+				Username:   "diegof",
+				Email:      "diego@gmail.com",
+				Scope:      "openid email",
+				RealmRoles: []string{"user"},
+				ClientRoles: map[string][]string{
+					"cow": {"admin"},
+				},
+				Exp: 1700000300,
+				Iat: 1700000000,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockAuthService{
+				keycloakClient: &mocks.IKeycloakClient{},
+			}
+			tt.mocks.authService(m)
+			service := NewAuthService(m.keycloakClient)
+			resp, err := service.IntrospectToken(context.Background(), tt.accessToken)
+			if err != nil {
+				assert.Equal(t, tt.expErr, err)
+			}
+			assert.Equal(t, tt.outPut, resp)
+		})
+	}
+}
+
+func Test_GetUserInfo(t *testing.T) {
 	tests := []struct {
 		name        string
 		accessToken string
 		mocks       authMocks
 		expErr      error
-		outPut      bool
+		outPut      response.UserInfoResponse
 	}{
 		{
-			name:        "error valid token",
+			name:        "error getting user info",
 			accessToken: "abc",
 			mocks: authMocks{
 				func(f *mockAuthService) {
-					f.keycloakClient.On("IsValidToken", mock.Anything, "abc").Return(false, errors.New("some error"))
+					f.keycloakClient.On("GetUserInfo", mock.Anything, "abc").Return(dto.UserInfo{}, errors.New("some error"))
 				},
 			},
 			expErr: errors.New("some error"),
 		},
 		{
-			name:        "valid token",
+			name:        "full flow",
 			accessToken: "abc",
 			mocks: authMocks{
 				func(f *mockAuthService) {
-					f.keycloakClient.On("IsValidToken", mock.Anything, "abc").Return(true, nil)
+					f.keycloakClient.On("GetUserInfo", mock.Anything, "abc").Return(dto.UserInfo{
+						Profile: &gocloak.UserInfo{
+							Sub:               gocloak.StringP("abcde8"),
+							GivenName:         gocloak.StringP("diego"),
+							Email:             gocloak.StringP("diego@gmail.com"),
+							PreferredUsername: gocloak.StringP("diegof"),
+							EmailVerified:     gocloak.BoolP(true),
+						},
+						Claims: dto.TokenClaims{
+							RealmAccess: dto.RolesClaim{
+								Roles: []string{"user"},
+							},
+							ResourceAccess: map[string]dto.RolesClaim{
+								"cow": {Roles: []string{"viewer"}},
+							},
+						},
+					}, nil)
+				},
+			},
+			outPut: response.UserInfoResponse{
+				UserResponse: userResponse.UserResponse{
+					ID:            "abcde8",
+					Name:          "diego",
+					Email:         "diego@gmail.com",
+					NickName:      "diegof",
+					Enabled:       true,
+					EmailVerified: true,
+				},
+				RealmRoles: []string{"user"},
+				ClientRoles: map[string][]string{
+					"cow": {"viewer"},
 				},
 			},
-			outPut: true,
 		},
 	}
 	for _, tt := range tests {
@@ -167,7 +347,7 @@ func Test_IsValidToken(t *testing.T) {
 			}
 			tt.mocks.authService(m)
 			service := NewAuthService(m.keycloakClient)
-			resp, err := service.IsValidToken(context.Background(), tt.accessToken)
+			resp, err := service.GetUserInfo(context.Background(), tt.accessToken)
 			if err != nil {
 				assert.Equal(t, tt.expErr, err)
 			}
@@ -175,3 +355,125 @@ func Test_IsValidToken(t *testing.T) {
 		})
 	}
 }
+
+func Test_ChangePassword(t *testing.T) {
+	changeRequest := request.ChangePasswordRequest{
+		CurrentPassword: "old-password",
+		NewPassword:     "new-password",
+	}
+	tests := []struct {
+		expErr        error
+		mocks         authMocks
+		name          string
+		changeRequest request.ChangePasswordRequest
+	}{
+		{
+			name:          "same password",
+			changeRequest: request.ChangePasswordRequest{CurrentPassword: "old-password", NewPassword: "old-password"},
+			mocks: authMocks{
+				func(f *mockAuthService) {},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "the new password must be different from the current one"),
+		},
+		{
+			name:          "wrong current password",
+			changeRequest: changeRequest,
+			mocks: authMocks{
+				func(f *mockAuthService) {
+					f.keycloakClient.On("Login", mock.Anything, "diegof", "old-password").Return(nil, errors.New("user or password incorrect"))
+				},
+			},
+			expErr: apperror.New(http.StatusForbidden, "current password is incorrect"),
+		},
+		{
+			name:          "password rejected",
+			changeRequest: changeRequest,
+			mocks: authMocks{
+				func(f *mockAuthService) {
+					f.keycloakClient.On("Login", mock.Anything, "diegof", "old-password").Return(&gocloak.JWT{RefreshToken: "refresh_token"}, nil)
+					f.keycloakClient.On("Logout", mock.Anything, "refresh_token").Return(nil)
+					f.keycloakClient.On("SetPassword", mock.Anything, "1", "new-password", false).Return(apperror.New(http.StatusBadRequest, "password rejected: invalidPasswordHistoryMessage"))
+				},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "password rejected: invalidPasswordHistoryMessage"),
+		},
+		{
+			name:          "full flow",
+			changeRequest: changeRequest,
+			mocks: authMocks{
+				func(f *mockAuthService) {
+					f.keycloakClient.On("Login", mock.Anything, "diegof", "old-password").Return(&gocloak.JWT{RefreshToken: "refresh_token"}, nil)
+					f.keycloakClient.On("Logout", mock.Anything, "refresh_token").Return(errors.New("some error"))
+					f.keycloakClient.On("SetPassword", mock.Anything, "1", "new-password", false).Return(nil)
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockAuthService{
+				keycloakClient: &mocks.IKeycloakClient{},
+			}
+			tt.mocks.authService(m)
+			service := NewAuthService(m.keycloakClient)
+			err := service.ChangePassword(context.Background(), "1", "diegof", tt.changeRequest)
+			assert.Equal(t, tt.expErr, err)
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_ForgotPassword(t *testing.T) {
+	tests := []struct {
+		expErr error
+		mocks  authMocks
+		name   string
+	}{
+		{
+			name: "unknown email",
+			mocks: authMocks{
+				func(f *mockAuthService) {
+					f.keycloakClient.On("GetUserByEmail", mock.Anything, "diego@gmail.com").Return(nil, apperror.New(http.StatusNotFound, "user with email diego@gmail.com doesn't exist"))
+				},
+			},
+		},
+		{
+			name: "error getting user",
+			mocks: authMocks{
+				func(f *mockAuthService) {
+					f.keycloakClient.On("GetUserByEmail", mock.Anything, "diego@gmail.com").Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "disabled user",
+			mocks: authMocks{
+				func(f *mockAuthService) {
+					f.keycloakClient.On("GetUserByEmail", mock.Anything, "diego@gmail.com").Return(&gocloak.User{ID: gocloak.StringP("1"), Enabled: gocloak.BoolP(false)}, nil)
+				},
+			},
+		},
+		{
+			name: "full flow",
+			mocks: authMocks{
+				func(f *mockAuthService) {
+					f.keycloakClient.On("GetUserByEmail", mock.Anything, "diego@gmail.com").Return(&gocloak.User{ID: gocloak.StringP("1"), Enabled: gocloak.BoolP(true)}, nil)
+					f.keycloakClient.On("SendActionsEmail", mock.Anything, "1", []string{"UPDATE_PASSWORD"}).Return(nil)
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockAuthService{
+				keycloakClient: &mocks.IKeycloakClient{},
+			}
+			tt.mocks.authService(m)
+			service := NewAuthService(m.keycloakClient)
+			err := service.ForgotPassword(context.Background(), request.ForgotPasswordRequest{Email: "diego@gmail.com"})
+			assert.Equal(t, tt.expErr, err)
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
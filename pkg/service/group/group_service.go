@@ -0,0 +1,233 @@
+package group
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"cow_sso/api/handlers/group/request"
+	"cow_sso/api/handlers/group/response"
+	userResponse "cow_sso/api/handlers/user/response"
+	"cow_sso/pkg/apperror"
+	"cow_sso/pkg/config"
+	"cow_sso/pkg/integration/keycloak"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+const (
+	_defaultPageSize = 20
+	_maxPageSize     = 100
+)
+
+type IGroupService interface {
+	GetAll(ctx context.Context, search string) ([]response.GroupResponse, error)
+	GetByID(ctx context.Context, groupID string) (response.GroupResponse, error)
+	Create(ctx context.Context, parentID string, groupRequest request.GroupRequest) (response.GroupResponse, error)
+	Update(ctx context.Context, groupID string, groupRequest request.GroupRequest) (response.GroupResponse, error)
+	Delete(ctx context.Context, groupID string) (string, error)
+	GetMembers(ctx context.Context, groupID string, query request.MembersQuery) ([]userResponse.UserResponse, error)
+	GetByUser(ctx context.Context, nickName string) ([]response.GroupResponse, error)
+	AddMember(ctx context.Context, groupID string, nickName string) error
+	RemoveMember(ctx context.Context, groupID string, nickName string) error
+}
+
+type groupService struct {
+	keycloakClient keycloak.IKeycloakClient
+	attributes     map[string]bool
+}
+
+func NewGroupService(keycloakClient keycloak.IKeycloakClient) IGroupService {
+	return &groupService{
+		keycloakClient: keycloakClient,
+		attributes:     loadAttributeKeys(),
+	}
+}
+
+// loadAttributeKeys reads the keys of the user attributes allow-list, the only attributes members show.
+func loadAttributeKeys() map[string]bool {
+	keys := map[string]bool{}
+	for key := range config.Get().UMap("attributes") {
+		keys[key] = true
+	}
+	return keys
+}
+
+// GetAll returns the realm's group tree, keeping only the branches that match search when it isn't empty.
+func (gs *groupService) GetAll(ctx context.Context, search string) ([]response.GroupResponse, error) {
+	params := gocloak.GetGroupsParams{}
+	if search != "" {
+		params.Search = &search
+	}
+	groups, err := gs.keycloakClient.GetGroups(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	groupsResponse := []response.GroupResponse{}
+	for _, group := range groups {
+		groupsResponse = append(groupsResponse, toGroupResponse(*group))
+	}
+	return groupsResponse, nil
+}
+
+func (gs *groupService) GetByID(ctx context.Context, groupID string) (response.GroupResponse, error) {
+	group, err := gs.keycloakClient.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return response.GroupResponse{}, err
+	}
+	return toGroupResponse(*group), nil
+}
+
+// Create adds a top level group, or a subgroup of parentID when it isn't empty.
+func (gs *groupService) Create(ctx context.Context, parentID string, groupRequest request.GroupRequest) (response.GroupResponse, error) {
+	var groupResponse response.GroupResponse
+	if strings.TrimSpace(groupRequest.Name) == "" {
+		return groupResponse, apperror.New(http.StatusBadRequest, "group's name is required")
+	}
+	if parentID != "" {
+		if _, err := gs.keycloakClient.GetGroupByID(ctx, parentID); err != nil {
+			return groupResponse, err
+		}
+	}
+
+	id, err := gs.keycloakClient.CreateGroup(ctx, parentID, gocloak.Group{
+		Name:       &groupRequest.Name,
+		Attributes: toAttributes(groupRequest.Attributes),
+	})
+	if err != nil {
+		return groupResponse, err
+	}
+	return gs.GetByID(ctx, id)
+}
+
+// Update renames the group and replaces its attributes.
+func (gs *groupService) Update(ctx context.Context, groupID string, groupRequest request.GroupRequest) (response.GroupResponse, error) {
+	var groupResponse response.GroupResponse
+	if strings.TrimSpace(groupRequest.Name) == "" {
+		return groupResponse, apperror.New(http.StatusBadRequest, "group's name is required")
+	}
+	group, err := gs.keycloakClient.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return groupResponse, err
+	}
+
+	group.Name = &groupRequest.Name
+	group.Attributes = toAttributes(groupRequest.Attributes)
+	if err := gs.keycloakClient.UpdateGroup(ctx, *group); err != nil {
+		return groupResponse, err
+	}
+	return gs.GetByID(ctx, groupID)
+}
+
+// Delete removes the group along with its subgroups, returning its name.
+func (gs *groupService) Delete(ctx context.Context, groupID string) (string, error) {
+	group, err := gs.keycloakClient.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return "", err
+	}
+	if err := gs.keycloakClient.DeleteGroup(ctx, groupID); err != nil {
+		return "", err
+	}
+	return gocloak.PString(group.Name), nil
+}
+
+func (gs *groupService) GetMembers(ctx context.Context, groupID string, query request.MembersQuery) ([]userResponse.UserResponse, error) {
+	if query.First < 0 {
+		return nil, apperror.New(http.StatusBadRequest, "first must not be negative")
+	}
+	if query.Max <= 0 {
+		query.Max = _defaultPageSize
+	}
+	if query.Max > _maxPageSize {
+		query.Max = _maxPageSize
+	}
+	if _, err := gs.keycloakClient.GetGroupByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	members, err := gs.keycloakClient.GetGroupMembers(ctx, groupID, gocloak.GetGroupsParams{
+		First: &query.First,
+		Max:   &query.Max,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	membersResponse := []userResponse.UserResponse{}
+	for _, member := range members {
+		membersResponse = append(membersResponse, userResponse.NewUserResponse(member, func(key string) bool {
+			return gs.attributes[key]
+		}))
+	}
+	return membersResponse, nil
+}
+
+func (gs *groupService) GetByUser(ctx context.Context, nickName string) ([]response.GroupResponse, error) {
+	user, err := gs.keycloakClient.GetUserByNickName(ctx, nickName)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := gs.keycloakClient.GetUserGroups(ctx, *user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupsResponse := []response.GroupResponse{}
+	for _, group := range groups {
+		groupsResponse = append(groupsResponse, toGroupResponse(*group))
+	}
+	return groupsResponse, nil
+}
+
+func (gs *groupService) AddMember(ctx context.Context, groupID string, nickName string) error {
+	if _, err := gs.keycloakClient.GetGroupByID(ctx, groupID); err != nil {
+		return err
+	}
+	user, err := gs.keycloakClient.GetUserByNickName(ctx, nickName)
+	if err != nil {
+		return err
+	}
+	return gs.keycloakClient.AddUserToGroup(ctx, *user.ID, groupID)
+}
+
+func (gs *groupService) RemoveMember(ctx context.Context, groupID string, nickName string) error {
+	if _, err := gs.keycloakClient.GetGroupByID(ctx, groupID); err != nil {
+		return err
+	}
+	user, err := gs.keycloakClient.GetUserByNickName(ctx, nickName)
+	if err != nil {
+		return err
+	}
+	return gs.keycloakClient.DeleteUserFromGroup(ctx, *user.ID, groupID)
+}
+
+func toAttributes(attributes map[string]string) *map[string][]string {
+	groupAttributes := map[string][]string{}
+	for key, value := range attributes {
+		groupAttributes[key] = []string{value}
+	}
+	return &groupAttributes
+}
+
+func toGroupResponse(group gocloak.Group) response.GroupResponse {
+	groupResponse := response.GroupResponse{
+		ID:   gocloak.PString(group.ID),
+		Name: gocloak.PString(group.Name),
+		Path: gocloak.PString(group.Path),
+	}
+	if group.Attributes != nil && len(*group.Attributes) > 0 {
+		groupResponse.Attributes = map[string]string{}
+		for key, values := range *group.Attributes {
+			if len(values) > 0 {
+				groupResponse.Attributes[key] = values[0]
+			}
+		}
+	}
+	if group.SubGroups != nil {
+		for _, subGroup := range *group.SubGroups {
+			groupResponse.SubGroups = append(groupResponse.SubGroups, toGroupResponse(subGroup))
+		}
+	}
+	return groupResponse
+}
@@ -0,0 +1,494 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"cow_sso/api/handlers/group/request"
+	"cow_sso/api/handlers/group/response"
+	userResponse "cow_sso/api/handlers/user/response"
+	"cow_sso/mocks"
+	"cow_sso/pkg/apperror"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockGroupService struct {
+	keycloakClient *mocks.IKeycloakClient
+}
+
+type groupMocks struct {
+	groupService func(f *mockGroupService)
+}
+
+func sales() *gocloak.Group {
+	return &gocloak.Group{
+		ID:   gocloak.StringP("g1"),
+		Name: gocloak.StringP("sales"),
+		Path: gocloak.StringP("/sales"),
+		Attributes: &map[string][]string{
+			"cost-center": {"cc-10"},
+		},
+		SubGroups: &[]gocloak.Group{
+			{
+				ID:   gocloak.StringP("g2"),
+				Name: gocloak.StringP("north"),
+				Path: gocloak.StringP("/sales/north"),
+			},
+		},
+	}
+}
+
+func salesResponse() response.GroupResponse {
+	return response.GroupResponse{
+		ID:   "g1",
+		Name: "sales",
+		Path: "/sales",
+		Attributes: map[string]string{
+			"cost-center": "cc-10",
+		},
+		SubGroups: []response.GroupResponse{
+			{ID: "g2", Name: "north", Path: "/sales/north"},
+		},
+	}
+}
+
+func diegof() *gocloak.User {
+	return &gocloak.User{
+		ID:       gocloak.StringP("abcde8"),
+		Username: gocloak.StringP("diegof"),
+	}
+}
+
+func Test_GetAll(t *testing.T) {
+	tests := []struct {
+		expErr error
+		mocks  groupMocks
+		name   string
+		search string
+		outPut []response.GroupResponse
+	}{
+		{
+			name: "error GetGroups",
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					f.keycloakClient.Mock.On("GetGroups", mock.Anything, gocloak.GetGroupsParams{}).Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name:   "full flow",
+			search: "sal",
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					f.keycloakClient.Mock.On("GetGroups", mock.Anything, gocloak.GetGroupsParams{Search: gocloak.StringP("sal")}).Return([]*gocloak.Group{sales()}, nil)
+				},
+			},
+			outPut: []response.GroupResponse{salesResponse()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockGroupService{
+				keycloakClient: &mocks.IKeycloakClient{},
+			}
+			tt.mocks.groupService(m)
+			service := NewGroupService(m.keycloakClient)
+			groups, err := service.GetAll(context.Background(), tt.search)
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, groups)
+		})
+	}
+}
+
+func Test_Create(t *testing.T) {
+	tests := []struct {
+		expErr       error
+		mocks        groupMocks
+		groupRequest request.GroupRequest
+		name         string
+		parentID     string
+		outPut       response.GroupResponse
+	}{
+		{
+			name: "name is required",
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {},
+			},
+			groupRequest: request.GroupRequest{Name: " "},
+			expErr:       apperror.New(http.StatusBadRequest, "group's name is required"),
+		},
+		{
+			name:         "parent not found",
+			parentID:     "g0",
+			groupRequest: request.GroupRequest{Name: "north"},
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					f.keycloakClient.Mock.On("GetGroupByID", mock.Anything, "g0").Return(nil, apperror.New(http.StatusNotFound, "group g0 doesn't exist"))
+				},
+			},
+			expErr: apperror.New(http.StatusNotFound, "group g0 doesn't exist"),
+		},
+		{
+			name:         "error CreateGroup",
+			groupRequest: request.GroupRequest{Name: "sales"},
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					f.keycloakClient.Mock.On("CreateGroup", mock.Anything, "", gocloak.Group{
+						Name:       gocloak.StringP("sales"),
+						Attributes: &map[string][]string{},
+					}).Return("", apperror.New(http.StatusConflict, "group sales already exists"))
+				},
+			},
+			expErr: apperror.New(http.StatusConflict, "group sales already exists"),
+		},
+		{
+			name: "top level group",
+			groupRequest: request.GroupRequest{
+				Name:       "sales",
+				Attributes: map[string]string{"cost-center": "cc-10"},
+			},
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					f.keycloakClient.Mock.On("CreateGroup", mock.Anything, "", gocloak.Group{
+						Name:       gocloak.StringP("sales"),
+						Attributes: &map[string][]string{"cost-center": {"cc-10"}},
+					}).Return("g1", nil)
+					f.keycloakClient.Mock.On("GetGroupByID", mock.Anything, "g1").Return(sales(), nil)
+				},
+			},
+			outPut: salesResponse(),
+		},
+		{
+			name:         "subgroup",
+			parentID:     "g1",
+			groupRequest: request.GroupRequest{Name: "north"},
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					f.keycloakClient.Mock.On("GetGroupByID", mock.Anything, "g1").Return(sales(), nil)
+					f.keycloakClient.Mock.On("CreateGroup", mock.Anything, "g1", gocloak.Group{
+						Name:       gocloak.StringP("north"),
+						Attributes: &map[string][]string{},
+					}).Return("g2", nil)
+					f.keycloakClient.Mock.On("GetGroupByID", mock.Anything, "g2").Return(&(*sales().SubGroups)[0], nil)
+				},
+			},
+			outPut: response.GroupResponse{ID: "g2", Name: "north", Path: "/sales/north"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockGroupService{
+				keycloakClient: &mocks.IKeycloakClient{},
+			}
+			tt.mocks.groupService(m)
+			service := NewGroupService(m.keycloakClient)
+			group, err := service.Create(context.Background(), tt.parentID, tt.groupRequest)
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, group)
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Update(t *testing.T) {
+	tests := []struct {
+		expErr       error
+		mocks        groupMocks
+		groupRequest request.GroupRequest
+		name         string
+		outPut       response.GroupResponse
+	}{
+		{
+			name: "name is required",
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "group's name is required"),
+		},
+		{
+			name:         "error UpdateGroup",
+			groupRequest: request.GroupRequest{Name: "marketing"},
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					f.keycloakClient.Mock.On("GetGroupByID", mock.Anything, "g1").Return(sales(), nil)
+					f.keycloakClient.Mock.On("UpdateGroup", mock.Anything, mock.Anything).Return(errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name:         "full flow",
+			groupRequest: request.GroupRequest{Name: "marketing"},
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					updated := sales()
+					updated.Name = gocloak.StringP("marketing")
+					updated.Attributes = &map[string][]string{}
+					renamed := sales()
+					renamed.Name = gocloak.StringP("marketing")
+					renamed.Path = gocloak.StringP("/marketing")
+					renamed.Attributes = nil
+					renamed.SubGroups = nil
+					f.keycloakClient.Mock.On("GetGroupByID", mock.Anything, "g1").Return(sales(), nil).Once()
+					f.keycloakClient.Mock.On("UpdateGroup", mock.Anything, *updated).Return(nil)
+					f.keycloakClient.Mock.On("GetGroupByID", mock.Anything, "g1").Return(renamed, nil).Once()
+				},
+			},
+			outPut: response.GroupResponse{ID: "g1", Name: "marketing", Path: "/marketing"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockGroupService{
+				keycloakClient: &mocks.IKeycloakClient{},
+			}
+			tt.mocks.groupService(m)
+			service := NewGroupService(m.keycloakClient)
+			group, err := service.Update(context.Background(), "g1", tt.groupRequest)
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, group)
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Delete(t *testing.T) {
+	tests := []struct {
+		expErr error
+		mocks  groupMocks
+		name   string
+		outPut string
+	}{
+		{
+			name: "group not found",
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					f.keycloakClient.Mock.On("GetGroupByID", mock.Anything, "g1").Return(nil, apperror.New(http.StatusNotFound, "group g1 doesn't exist"))
+				},
+			},
+			expErr: apperror.New(http.StatusNotFound, "group g1 doesn't exist"),
+		},
+		{
+			name: "error DeleteGroup",
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					f.keycloakClient.Mock.On("GetGroupByID", mock.Anything, "g1").Return(sales(), nil)
+					f.keycloakClient.Mock.On("DeleteGroup", mock.Anything, "g1").Return(errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "full flow",
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					f.keycloakClient.Mock.On("GetGroupByID", mock.Anything, "g1").Return(sales(), nil)
+					f.keycloakClient.Mock.On("DeleteGroup", mock.Anything, "g1").Return(nil)
+				},
+			},
+			outPut: "sales",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockGroupService{
+				keycloakClient: &mocks.IKeycloakClient{},
+			}
+			tt.mocks.groupService(m)
+			service := NewGroupService(m.keycloakClient)
+			name, err := service.Delete(context.Background(), "g1")
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, name)
+		})
+	}
+}
+
+func Test_GetMembers(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expErr error
+		mocks  groupMocks
+		name   string
+		query  request.MembersQuery
+		outPut []userResponse.UserResponse
+	}{
+		{
+			name:  "negative first",
+			query: request.MembersQuery{First: -1},
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "first must not be negative"),
+		},
+		{
+			name:  "error GetGroupMembers",
+			query: request.MembersQuery{Max: 500},
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					f.keycloakClient.Mock.On("GetGroupByID", mock.Anything, "g1").Return(sales(), nil)
+					f.keycloakClient.Mock.On("GetGroupMembers", mock.Anything, "g1", gocloak.GetGroupsParams{
+						First: gocloak.IntP(0),
+						Max:   gocloak.IntP(100),
+					}).Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "full flow",
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					member := diegof()
+					member.Enabled = gocloak.BoolP(true)
+					member.EmailVerified = gocloak.BoolP(true)
+					member.CreatedTimestamp = gocloak.Int64P(createdAt.UnixMilli())
+					member.Attributes = &map[string][]string{"locale": {"es"}, "registration": {"pending"}}
+					f.keycloakClient.Mock.On("GetGroupByID", mock.Anything, "g1").Return(sales(), nil)
+					f.keycloakClient.Mock.On("GetGroupMembers", mock.Anything, "g1", gocloak.GetGroupsParams{
+						First: gocloak.IntP(0),
+						Max:   gocloak.IntP(20),
+					}).Return([]*gocloak.User{member}, nil)
+				},
+			},
+			outPut: []userResponse.UserResponse{
+				{
+					ID:            "abcde8",
+					NickName:      "diegof",
+					Enabled:       true,
+					EmailVerified: true,
+					CreatedAt:     &createdAt,
+					Attributes:    map[string]string{"locale": "es"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockGroupService{
+				keycloakClient: &mocks.IKeycloakClient{},
+			}
+			tt.mocks.groupService(m)
+			service := NewGroupService(m.keycloakClient)
+			members, err := service.GetMembers(context.Background(), "g1", tt.query)
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, members)
+		})
+	}
+}
+
+func Test_GetByUser(t *testing.T) {
+	tests := []struct {
+		expErr error
+		mocks  groupMocks
+		name   string
+		outPut []response.GroupResponse
+	}{
+		{
+			name: "error GetUserGroups",
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(diegof(), nil)
+					f.keycloakClient.Mock.On("GetUserGroups", mock.Anything, "abcde8").Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "full flow",
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(diegof(), nil)
+					f.keycloakClient.Mock.On("GetUserGroups", mock.Anything, "abcde8").Return([]*gocloak.Group{sales()}, nil)
+				},
+			},
+			outPut: []response.GroupResponse{salesResponse()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockGroupService{
+				keycloakClient: &mocks.IKeycloakClient{},
+			}
+			tt.mocks.groupService(m)
+			service := NewGroupService(m.keycloakClient)
+			groups, err := service.GetByUser(context.Background(), "diegof")
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, groups)
+		})
+	}
+}
+
+func Test_Membership(t *testing.T) {
+	tests := []struct {
+		expErr error
+		mocks  groupMocks
+		name   string
+		remove bool
+	}{
+		{
+			name: "group not found",
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					f.keycloakClient.Mock.On("GetGroupByID", mock.Anything, "g1").Return(nil, apperror.New(http.StatusNotFound, "group g1 doesn't exist"))
+				},
+			},
+			expErr: apperror.New(http.StatusNotFound, "group g1 doesn't exist"),
+		},
+		{
+			name: "error GetUserByNickName",
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					f.keycloakClient.Mock.On("GetGroupByID", mock.Anything, "g1").Return(sales(), nil)
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "add member",
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					f.keycloakClient.Mock.On("GetGroupByID", mock.Anything, "g1").Return(sales(), nil)
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(diegof(), nil)
+					f.keycloakClient.Mock.On("AddUserToGroup", mock.Anything, "abcde8", "g1").Return(nil)
+				},
+			},
+		},
+		{
+			name:   "remove member",
+			remove: true,
+			mocks: groupMocks{
+				groupService: func(f *mockGroupService) {
+					f.keycloakClient.Mock.On("GetGroupByID", mock.Anything, "g1").Return(sales(), nil)
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(diegof(), nil)
+					f.keycloakClient.Mock.On("DeleteUserFromGroup", mock.Anything, "abcde8", "g1").Return(errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockGroupService{
+				keycloakClient: &mocks.IKeycloakClient{},
+			}
+			tt.mocks.groupService(m)
+			service := NewGroupService(m.keycloakClient)
+			var err error
+			if tt.remove {
+				err = service.RemoveMember(context.Background(), "g1", "diegof")
+			} else {
+				err = service.AddMember(context.Background(), "g1", "diegof")
+			}
+			assert.Equal(t, tt.expErr, err)
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package role
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"cow_sso/api/handlers/role/request"
+	"cow_sso/api/handlers/role/response"
+	"cow_sso/pkg/apperror"
+	"cow_sso/pkg/integration/keycloak"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+type IRoleService interface {
+	GetAll(ctx context.Context) ([]response.RoleResponse, error)
+	GetByUser(ctx context.Context, nickName string) ([]response.RoleResponse, error)
+	Grant(ctx context.Context, nickName string, roleRequest request.RoleRequest) ([]response.RoleResponse, error)
+	Revoke(ctx context.Context, nickName string, roleName string) ([]response.RoleResponse, error)
+}
+
+type roleService struct {
+	keycloakClient keycloak.IKeycloakClient
+}
+
+func NewRoleService(keycloakClient keycloak.IKeycloakClient) IRoleService {
+	return &roleService{
+		keycloakClient: keycloakClient,
+	}
+}
+
+func (rs *roleService) GetAll(ctx context.Context) ([]response.RoleResponse, error) {
+	roles, err := rs.keycloakClient.GetRealmRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toRolesResponse(roles), nil
+}
+
+// GetByUser returns the realm roles granted directly to the user.
+func (rs *roleService) GetByUser(ctx context.Context, nickName string) ([]response.RoleResponse, error) {
+	user, err := rs.keycloakClient.GetUserByNickName(ctx, nickName)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := rs.keycloakClient.GetUserRealmRoles(ctx, *user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toRolesResponse(roles), nil
+}
+
+// Grant adds the requested realm roles to the user and returns the roles it ends up with.
+func (rs *roleService) Grant(ctx context.Context, nickName string, roleRequest request.RoleRequest) ([]response.RoleResponse, error) {
+	if len(roleRequest.Roles) == 0 {
+		return nil, apperror.New(http.StatusBadRequest, "at least one role is required")
+	}
+	user, err := rs.keycloakClient.GetUserByNickName(ctx, nickName)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := rs.keycloakClient.GetRealmRolesByName(ctx, roleRequest.Roles)
+	if err != nil {
+		return nil, err
+	}
+	if err := rs.keycloakClient.AddRealmRolesToUser(ctx, *user.ID, roles); err != nil {
+		return nil, err
+	}
+
+	userRoles, err := rs.keycloakClient.GetUserRealmRoles(ctx, *user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toRolesResponse(userRoles), nil
+}
+
+// Revoke removes roleName from the user's realm roles and returns the ones it keeps.
+func (rs *roleService) Revoke(ctx context.Context, nickName string, roleName string) ([]response.RoleResponse, error) {
+	user, err := rs.keycloakClient.GetUserByNickName(ctx, nickName)
+	if err != nil {
+		return nil, err
+	}
+	userRoles, err := rs.keycloakClient.GetUserRealmRoles(ctx, *user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []*gocloak.Role
+	var revoked *gocloak.Role
+	for _, role := range userRoles {
+		if gocloak.PString(role.Name) == roleName {
+			revoked = role
+			continue
+		}
+		kept = append(kept, role)
+	}
+	if revoked == nil {
+		return nil, apperror.New(http.StatusNotFound, fmt.Sprintf("user %s doesn't have the role %s", nickName, roleName))
+	}
+
+	if err := rs.keycloakClient.DeleteRealmRolesFromUser(ctx, *user.ID, []gocloak.Role{*revoked}); err != nil {
+		return nil, err
+	}
+	return toRolesResponse(kept), nil
+}
+
+func toRolesResponse(roles []*gocloak.Role) []response.RoleResponse {
+	rolesResponse := []response.RoleResponse{}
+	for _, role := range roles {
+		rolesResponse = append(rolesResponse, response.RoleResponse{
+			ID:          gocloak.PString(role.ID),
+			Name:        gocloak.PString(role.Name),
+			Description: gocloak.PString(role.Description),
+			Composite:   gocloak.PBool(role.Composite),
+		})
+	}
+	return rolesResponse
+}
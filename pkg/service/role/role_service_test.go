@@ -0,0 +1,290 @@
+package role
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"cow_sso/api/handlers/role/request"
+	"cow_sso/api/handlers/role/response"
+	"cow_sso/mocks"
+	"cow_sso/pkg/apperror"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockRoleService struct {
+	keycloakClient *mocks.IKeycloakClient
+}
+
+type roleMocks struct {
+	roleService func(f *mockRoleService)
+}
+
+func diegof() *gocloak.User {
+	return &gocloak.User{
+		ID:       gocloak.StringP("abcde8"),
+		Username: gocloak.StringP("diegof"),
+	}
+}
+
+func realmRole(id string, name string) *gocloak.Role {
+	return &gocloak.Role{
+		ID:   gocloak.StringP(id),
+		Name: gocloak.StringP(name),
+	}
+}
+
+func Test_GetAll(t *testing.T) {
+	tests := []struct {
+		expErr error
+		mocks  roleMocks
+		name   string
+		outPut []response.RoleResponse
+	}{
+		{
+			name: "error GetRealmRoles",
+			mocks: roleMocks{
+				roleService: func(f *mockRoleService) {
+					f.keycloakClient.Mock.On("GetRealmRoles", mock.Anything).Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "full flow",
+			mocks: roleMocks{
+				roleService: func(f *mockRoleService) {
+					admin := realmRole("2", "admin")
+					admin.Description = gocloak.StringP("manages users")
+					admin.Composite = gocloak.BoolP(true)
+					f.keycloakClient.Mock.On("GetRealmRoles", mock.Anything).Return([]*gocloak.Role{realmRole("1", "user"), admin}, nil)
+				},
+			},
+			outPut: []response.RoleResponse{
+				{ID: "1", Name: "user"},
+				{ID: "2", Name: "admin", Description: "manages users", Composite: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockRoleService{
+				keycloakClient: &mocks.IKeycloakClient{},
+			}
+			tt.mocks.roleService(m)
+			service := NewRoleService(m.keycloakClient)
+			roles, err := service.GetAll(context.Background())
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, roles)
+		})
+	}
+}
+
+func Test_GetByUser(t *testing.T) {
+	tests := []struct {
+		expErr error
+		mocks  roleMocks
+		name   string
+		outPut []response.RoleResponse
+	}{
+		{
+			name: "error GetUserByNickName",
+			mocks: roleMocks{
+				roleService: func(f *mockRoleService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "error GetUserRealmRoles",
+			mocks: roleMocks{
+				roleService: func(f *mockRoleService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(diegof(), nil)
+					f.keycloakClient.Mock.On("GetUserRealmRoles", mock.Anything, "abcde8").Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "user without roles",
+			mocks: roleMocks{
+				roleService: func(f *mockRoleService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(diegof(), nil)
+					f.keycloakClient.Mock.On("GetUserRealmRoles", mock.Anything, "abcde8").Return([]*gocloak.Role{}, nil)
+				},
+			},
+			outPut: []response.RoleResponse{},
+		},
+		{
+			name: "full flow",
+			mocks: roleMocks{
+				roleService: func(f *mockRoleService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(diegof(), nil)
+					f.keycloakClient.Mock.On("GetUserRealmRoles", mock.Anything, "abcde8").Return([]*gocloak.Role{realmRole("1", "user")}, nil)
+				},
+			},
+			outPut: []response.RoleResponse{{ID: "1", Name: "user"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockRoleService{
+				keycloakClient: &mocks.IKeycloakClient{},
+			}
+			tt.mocks.roleService(m)
+			service := NewRoleService(m.keycloakClient)
+			roles, err := service.GetByUser(context.Background(), "diegof")
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, roles)
+		})
+	}
+}
+
+func Test_Grant(t *testing.T) {
+	tests := []struct {
+		expErr      error
+		mocks       roleMocks
+		name        string
+		roleRequest request.RoleRequest
+		outPut      []response.RoleResponse
+	}{
+		{
+			name: "no roles",
+			mocks: roleMocks{
+				roleService: func(f *mockRoleService) {},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "at least one role is required"),
+		},
+		{
+			name:        "error GetUserByNickName",
+			roleRequest: request.RoleRequest{Roles: []string{"admin"}},
+			mocks: roleMocks{
+				roleService: func(f *mockRoleService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name:        "unknown role",
+			roleRequest: request.RoleRequest{Roles: []string{"root"}},
+			mocks: roleMocks{
+				roleService: func(f *mockRoleService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(diegof(), nil)
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"root"}).Return(nil, apperror.New(http.StatusBadRequest, "role root doesn't exist"))
+				},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "role root doesn't exist"),
+		},
+		{
+			name:        "error AddRealmRolesToUser",
+			roleRequest: request.RoleRequest{Roles: []string{"admin"}},
+			mocks: roleMocks{
+				roleService: func(f *mockRoleService) {
+					roles := []gocloak.Role{*realmRole("2", "admin")}
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(diegof(), nil)
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"admin"}).Return(roles, nil)
+					f.keycloakClient.Mock.On("AddRealmRolesToUser", mock.Anything, "abcde8", roles).Return(errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name:        "full flow",
+			roleRequest: request.RoleRequest{Roles: []string{"admin"}},
+			mocks: roleMocks{
+				roleService: func(f *mockRoleService) {
+					roles := []gocloak.Role{*realmRole("2", "admin")}
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(diegof(), nil)
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"admin"}).Return(roles, nil)
+					f.keycloakClient.Mock.On("AddRealmRolesToUser", mock.Anything, "abcde8", roles).Return(nil)
+					f.keycloakClient.Mock.On("GetUserRealmRoles", mock.Anything, "abcde8").Return([]*gocloak.Role{realmRole("1", "user"), realmRole("2", "admin")}, nil)
+				},
+			},
+			outPut: []response.RoleResponse{{ID: "1", Name: "user"}, {ID: "2", Name: "admin"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockRoleService{
+				keycloakClient: &mocks.IKeycloakClient{},
+			}
+			tt.mocks.roleService(m)
+			service := NewRoleService(m.keycloakClient)
+			roles, err := service.Grant(context.Background(), "diegof", tt.roleRequest)
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, roles)
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Revoke(t *testing.T) {
+	tests := []struct {
+		expErr error
+		mocks  roleMocks
+		name   string
+		outPut []response.RoleResponse
+	}{
+		{
+			name: "error GetUserRealmRoles",
+			mocks: roleMocks{
+				roleService: func(f *mockRoleService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(diegof(), nil)
+					f.keycloakClient.Mock.On("GetUserRealmRoles", mock.Anything, "abcde8").Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "role not granted",
+			mocks: roleMocks{
+				roleService: func(f *mockRoleService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(diegof(), nil)
+					f.keycloakClient.Mock.On("GetUserRealmRoles", mock.Anything, "abcde8").Return([]*gocloak.Role{realmRole("1", "user")}, nil)
+				},
+			},
+			expErr: apperror.New(http.StatusNotFound, "user diegof doesn't have the role admin"),
+		},
+		{
+			name: "error DeleteRealmRolesFromUser",
+			mocks: roleMocks{
+				roleService: func(f *mockRoleService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(diegof(), nil)
+					f.keycloakClient.Mock.On("GetUserRealmRoles", mock.Anything, "abcde8").Return([]*gocloak.Role{realmRole("1", "user"), realmRole("2", "admin")}, nil)
+					f.keycloakClient.Mock.On("DeleteRealmRolesFromUser", mock.Anything, "abcde8", []gocloak.Role{*realmRole("2", "admin")}).Return(errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "full flow",
+			mocks: roleMocks{
+				roleService: func(f *mockRoleService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(diegof(), nil)
+					f.keycloakClient.Mock.On("GetUserRealmRoles", mock.Anything, "abcde8").Return([]*gocloak.Role{realmRole("1", "user"), realmRole("2", "admin")}, nil)
+					f.keycloakClient.Mock.On("DeleteRealmRolesFromUser", mock.Anything, "abcde8", []gocloak.Role{*realmRole("2", "admin")}).Return(nil)
+				},
+			},
+			outPut: []response.RoleResponse{{ID: "1", Name: "user"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockRoleService{
+				keycloakClient: &mocks.IKeycloakClient{},
+			}
+			tt.mocks.roleService(m)
+			service := NewRoleService(m.keycloakClient)
+			roles, err := service.Revoke(context.Background(), "diegof", "admin")
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, roles)
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
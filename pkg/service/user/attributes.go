@@ -0,0 +1,107 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cow_sso/pkg/apperror"
+	"cow_sso/pkg/config"
+)
+
+const (
+	_attributeString  = "string"
+	_attributeNumber  = "number"
+	_attributeBoolean = "boolean"
+	_attributePhone   = "phone"
+)
+
+// _phonePattern accepts phone numbers in E.164 format, such as +5491122334455.
+var _phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// attributeSchema maps every attribute key users may carry to the type of its value,
+// as configured under attributes in properties.yml.
+type attributeSchema map[string]string
+
+func loadAttributeSchema() attributeSchema {
+	schema := attributeSchema{}
+	for key, kind := range config.Get().UMap("attributes") {
+		if name, ok := kind.(string); ok {
+			schema[key] = name
+		}
+	}
+	return schema
+}
+
+// allows reports whether key is in the allow-list.
+func (s attributeSchema) allows(key string) bool {
+	_, ok := s[key]
+	return ok
+}
+
+// checkKey fails with a bad request when key isn't in the allow-list.
+func (s attributeSchema) checkKey(key string) error {
+	if !s.allows(key) {
+		return apperror.New(http.StatusBadRequest, fmt.Sprintf("attribute %s isn't allowed", key))
+	}
+	return nil
+}
+
+// check fails when key isn't allowed or value doesn't have the key's type.
+func (s attributeSchema) check(key string, value string) error {
+	if err := s.checkKey(key); err != nil {
+		return err
+	}
+	valid := true
+	switch s[key] {
+	case _attributeNumber:
+		_, err := strconv.ParseFloat(value, 64)
+		valid = err == nil
+	case _attributeBoolean:
+		_, err := strconv.ParseBool(value)
+		valid = err == nil
+	case _attributePhone:
+		valid = _phonePattern.MatchString(value)
+	case _attributeString:
+		valid = strings.TrimSpace(value) != ""
+	}
+	if !valid {
+		return apperror.New(http.StatusUnprocessableEntity, fmt.Sprintf("attribute %s must be a %s", key, s[key]))
+	}
+	return nil
+}
+
+// checkAll checks every attribute in attributes, in key order so the error reported is always the same.
+func (s attributeSchema) checkAll(attributes map[string]string) error {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := s.check(key, attributes[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// searchQuery turns filter, a space separated list of key:value pairs, into keycloak's q search
+// after making sure every key is allowed.
+func (s attributeSchema) searchQuery(filter string) (string, error) {
+	var pairs []string
+	for _, pair := range strings.Fields(filter) {
+		key, value, found := strings.Cut(pair, ":")
+		if !found || key == "" || value == "" {
+			return "", apperror.New(http.StatusBadRequest, fmt.Sprintf("attribute filter %s must look like key:value", pair))
+		}
+		if err := s.checkKey(key); err != nil {
+			return "", err
+		}
+		pairs = append(pairs, key+":"+value)
+	}
+	return strings.Join(pairs, " "), nil
+}
@@ -0,0 +1,89 @@
+package user
+
+import (
+	"net/http"
+	"testing"
+
+	"cow_sso/pkg/apperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_attributeSchema_checkAll(t *testing.T) {
+	schema := attributeSchema{
+		"phone":       _attributePhone,
+		"locale":      _attributeString,
+		"employee-id": _attributeNumber,
+		"newsletter":  _attributeBoolean,
+	}
+
+	tests := []struct {
+		expErr     error
+		attributes map[string]string
+		name       string
+	}{
+		{
+			name:       "valid",
+			attributes: map[string]string{"phone": "+5491122334455", "locale": "es", "employee-id": "42", "newsletter": "true"},
+		},
+		{
+			name:       "key not allowed",
+			attributes: map[string]string{"password": "secret"},
+			expErr:     apperror.New(http.StatusBadRequest, "attribute password isn't allowed"),
+		},
+		{
+			name:       "blank string",
+			attributes: map[string]string{"locale": " "},
+			expErr:     apperror.New(http.StatusUnprocessableEntity, "attribute locale must be a string"),
+		},
+		{
+			name:       "not a boolean",
+			attributes: map[string]string{"newsletter": "maybe"},
+			expErr:     apperror.New(http.StatusUnprocessableEntity, "attribute newsletter must be a boolean"),
+		},
+		{
+			name:       "first invalid key in order",
+			attributes: map[string]string{"phone": "123", "employee-id": "abc"},
+			expErr:     apperror.New(http.StatusUnprocessableEntity, "attribute employee-id must be a number"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expErr, schema.checkAll(tt.attributes))
+		})
+	}
+}
+
+func Test_attributeSchema_searchQuery(t *testing.T) {
+	schema := attributeSchema{"locale": _attributeString, "department": _attributeString}
+
+	tests := []struct {
+		expErr error
+		name   string
+		filter string
+		outPut string
+	}{
+		{
+			name:   "pairs",
+			filter: " locale:es   department:sales ",
+			outPut: "locale:es department:sales",
+		},
+		{
+			name:   "missing value",
+			filter: "locale:",
+			expErr: apperror.New(http.StatusBadRequest, "attribute filter locale: must look like key:value"),
+		},
+		{
+			name:   "key not allowed",
+			filter: "password:secret",
+			expErr: apperror.New(http.StatusBadRequest, "attribute password isn't allowed"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := schema.searchQuery(tt.filter)
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, q)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"cow_sso/api/handlers/user/response"
+	"cow_sso/pkg/apperror"
+	"cow_sso/pkg/integration/team/dto"
+)
+
+// Deletion policies, set under deletion.policy in properties.yml.
+const (
+	// _deletionBlockAny refuses to delete users who belong to any team.
+	_deletionBlockAny = "block-any"
+	// _deletionBlockDebt refuses to delete users who owe something to any of their teams.
+	_deletionBlockDebt = "block-debt"
+	// _deletionAllowNotify deletes users whatever their teams, then lets the team api know.
+	_deletionAllowNotify = "allow-notify"
+)
+
+// blockingTeams returns the teams that keep the user from being deleted under policy.
+// Unknown policies block on any team, the same as block-any.
+func blockingTeams(policy string, teams []dto.TeamResponse) []response.BlockingTeam {
+	var blocking []response.BlockingTeam
+	for _, team := range teams {
+		switch policy {
+		case _deletionAllowNotify:
+			continue
+		case _deletionBlockDebt:
+			if team.Debt <= 0 {
+				continue
+			}
+		}
+		blocking = append(blocking, response.BlockingTeam{Code: team.Code, Debt: team.Debt})
+	}
+	return blocking
+}
+
+// deletionBlocked is the conflict answered when teams keep the user from being deleted.
+func deletionBlocked(policy string, nickName string, teams []response.BlockingTeam) *apperror.AppError {
+	codes := make([]string, 0, len(teams))
+	for _, team := range teams {
+		codes = append(codes, team.Code)
+	}
+	reason := "belonging to the teams"
+	if policy == _deletionBlockDebt {
+		reason = "owing debt to the teams"
+	}
+	return &apperror.AppError{
+		Code:    http.StatusConflict,
+		Message: fmt.Sprintf("user %s can't be deleted while %s %s", nickName, reason, strings.Join(codes, ", ")),
+		Details: response.DeletionBlocked{Teams: teams},
+	}
+}
@@ -0,0 +1,210 @@
+package user
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cow_sso/api/handlers/user/request"
+	"cow_sso/api/handlers/user/response"
+	"cow_sso/pkg/apperror"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+const (
+	_exportCSV            = "csv"
+	_exportNDJSON         = "ndjson"
+	_exportListSeparator  = ";"
+	_exportAttributeGroup = "attributes."
+)
+
+// userEncoder writes exported users one at a time, flush pushes what was written so far to the client.
+type userEncoder interface {
+	encode(user response.UserResponse) error
+	flush() error
+}
+
+// Export writes every user that matches query to w, paging through keycloak export.page-size users
+// at a time and flushing after each page, so the export never sits in memory as a whole.
+// Nothing is written when query is invalid or the first page can't be read.
+func (us *userService) Export(ctx context.Context, query request.ExportQuery, w io.Writer) error {
+	expansions, err := parseExpand(query.Expand)
+	if err != nil {
+		return err
+	}
+	filters, err := us.filters(query.UserFilter)
+	if err != nil {
+		return err
+	}
+	var encoder userEncoder
+	switch query.Format {
+	case "", _exportCSV:
+		encoder = us.newCSVEncoder(w, expansions)
+	case _exportNDJSON:
+		encoder = &ndjsonEncoder{encoder: json.NewEncoder(w), w: w}
+	default:
+		return apperror.New(http.StatusBadRequest, fmt.Sprintf("can't export users as %s", query.Format))
+	}
+
+	pageSize := us.exportPageSize
+	if pageSize <= 0 {
+		pageSize = _maxPageSize
+	}
+	for first := 0; ; first += pageSize {
+		page := filters
+		page.First = gocloak.IntP(first)
+		page.Max = gocloak.IntP(pageSize)
+		users, err := us.keycloakClient.GetAllUsers(ctx, page)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			userResponse := toUserResponse(user, us.attributes)
+			if err := us.expand(ctx, &userResponse, expansions); err != nil {
+				return err
+			}
+			if err := encoder.encode(userResponse); err != nil {
+				return err
+			}
+		}
+		if err := encoder.flush(); err != nil {
+			return err
+		}
+		if len(users) < pageSize {
+			return nil
+		}
+	}
+}
+
+type ndjsonEncoder struct {
+	encoder *json.Encoder
+	w       io.Writer
+}
+
+func (e *ndjsonEncoder) encode(user response.UserResponse) error {
+	return e.encoder.Encode(user)
+}
+
+func (e *ndjsonEncoder) flush() error {
+	flushWriter(e.w)
+	return nil
+}
+
+// _formulaPrefixes are the first characters that make a spreadsheet read a cell as a formula.
+const _formulaPrefixes = "=+-@\t\r"
+
+// csvEncoder writes a header row and then a row per user. Expansions get a column each, only when
+// asked for, and every allowed attribute gets an attributes.<key> column, as the import reads them.
+type csvEncoder struct {
+	writer      *csv.Writer
+	w           io.Writer
+	expansions  map[string]bool
+	attributes  []string
+	wroteHeader bool
+}
+
+func (us *userService) newCSVEncoder(w io.Writer, expansions map[string]bool) *csvEncoder {
+	attributes := make([]string, 0, len(us.attributes))
+	for key := range us.attributes {
+		attributes = append(attributes, key)
+	}
+	sort.Strings(attributes)
+	return &csvEncoder{
+		writer:     csv.NewWriter(w),
+		w:          w,
+		expansions: expansions,
+		attributes: attributes,
+	}
+}
+
+func (e *csvEncoder) header() []string {
+	header := []string{"id", "nick_name", "name", "last_name", "email", "enabled", "email_verified", "created_at"}
+	for _, expansion := range []string{_expandRoles, _expandGroups, _expandTeams} {
+		if e.expansions[expansion] {
+			header = append(header, expansion)
+		}
+	}
+	for _, key := range e.attributes {
+		header = append(header, _exportAttributeGroup+key)
+	}
+	return header
+}
+
+func (e *csvEncoder) encode(user response.UserResponse) error {
+	if !e.wroteHeader {
+		if err := e.writer.Write(e.header()); err != nil {
+			return err
+		}
+		e.wroteHeader = true
+	}
+
+	var createdAt string
+	if user.CreatedAt != nil {
+		createdAt = user.CreatedAt.Format(time.RFC3339)
+	}
+	record := []string{
+		user.ID,
+		user.NickName,
+		user.Name,
+		user.LastName,
+		user.Email,
+		strconv.FormatBool(user.Enabled),
+		strconv.FormatBool(user.EmailVerified),
+		createdAt,
+	}
+	if e.expansions[_expandRoles] {
+		record = append(record, strings.Join(user.Roles, _exportListSeparator))
+	}
+	if e.expansions[_expandGroups] {
+		record = append(record, strings.Join(user.Groups, _exportListSeparator))
+	}
+	if e.expansions[_expandTeams] {
+		record = append(record, strings.Join(user.Teams, _exportListSeparator))
+	}
+	for _, key := range e.attributes {
+		record = append(record, user.Attributes[key])
+	}
+	for i, value := range record {
+		record[i] = escapeFormula(value)
+	}
+	return e.writer.Write(record)
+}
+
+// escapeFormula quotes values a spreadsheet would run as a formula with a leading ', the import drops it again.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(_formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// flush also writes the header when no user matched, so an empty export is still a valid csv.
+func (e *csvEncoder) flush() error {
+	if !e.wroteHeader {
+		if err := e.writer.Write(e.header()); err != nil {
+			return err
+		}
+		e.wroteHeader = true
+	}
+	e.writer.Flush()
+	if err := e.writer.Error(); err != nil {
+		return err
+	}
+	flushWriter(e.w)
+	return nil
+}
+
+// flushWriter sends the buffered response to the client when w is an http response.
+func flushWriter(w io.Writer) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
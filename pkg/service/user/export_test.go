@@ -0,0 +1,157 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"cow_sso/api/handlers/user/request"
+	"cow_sso/mocks"
+	"cow_sso/pkg/apperror"
+	"cow_sso/pkg/integration/team/dto"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Export(t *testing.T) {
+	page := func(first int, params gocloak.GetUsersParams) gocloak.GetUsersParams {
+		params.First = gocloak.IntP(first)
+		params.Max = gocloak.IntP(2)
+		return params
+	}
+	diegof := &gocloak.User{
+		ID:         gocloak.StringP("1"),
+		Username:   gocloak.StringP("diegof"),
+		FirstName:  gocloak.StringP("diego"),
+		LastName:   gocloak.StringP("fernandez"),
+		Email:      gocloak.StringP("diegof@gmail.com"),
+		Enabled:    gocloak.BoolP(true),
+		Attributes: &map[string][]string{"locale": {"es"}, "LDAP_ID": {"cn=diegof"}},
+	}
+	diegoa := &gocloak.User{
+		ID:        gocloak.StringP("2"),
+		Username:  gocloak.StringP("diegoa"),
+		FirstName: gocloak.StringP("diego"),
+		LastName:  gocloak.StringP("alvarez, jr"),
+		Email:     gocloak.StringP("diegoa@gmail.com"),
+	}
+	diegob := &gocloak.User{
+		ID:       gocloak.StringP("3"),
+		Username: gocloak.StringP("diegob"),
+	}
+
+	tests := []struct {
+		expErr error
+		mocks  userMocks
+		name   string
+		query  request.ExportQuery
+		outPut string
+	}{
+		{
+			name:  "unknown format",
+			query: request.ExportQuery{Format: "xml"},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "can't export users as xml"),
+		},
+		{
+			name:  "unknown expansion",
+			query: request.ExportQuery{Expand: []string{"sessions"}},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "can't expand sessions"),
+		},
+		{
+			name: "error GetAllUsers",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetAllUsers", mock.Anything, page(0, gocloak.GetUsersParams{})).Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "nothing to export",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetAllUsers", mock.Anything, page(0, gocloak.GetUsersParams{})).Return([]*gocloak.User{}, nil)
+				},
+			},
+			outPut: "id,nick_name,name,last_name,email,enabled,email_verified,created_at,attributes.department,attributes.employee-id,attributes.locale,attributes.phone\n",
+		},
+		{
+			name: "csv with roles and teams",
+			query: request.ExportQuery{
+				UserFilter: request.UserFilter{Search: "diego"},
+				Expand:     []string{"roles", "teams"},
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					filters := gocloak.GetUsersParams{Search: gocloak.StringP("diego")}
+					f.keycloakClient.Mock.On("GetAllUsers", mock.Anything, page(0, filters)).Return([]*gocloak.User{diegof, diegoa}, nil)
+					f.keycloakClient.Mock.On("GetAllUsers", mock.Anything, page(2, filters)).Return([]*gocloak.User{}, nil)
+					f.keycloakClient.Mock.On("GetUserRealmRoles", mock.Anything, "1").Return([]*gocloak.Role{{Name: gocloak.StringP("user")}, {Name: gocloak.StringP("admin")}}, nil)
+					f.keycloakClient.Mock.On("GetUserRealmRoles", mock.Anything, "2").Return([]*gocloak.Role{}, nil)
+					f.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "1").Return(dto.TeamsByUserResponse{Teams: []dto.TeamResponse{{Code: "cow"}}}, nil)
+					f.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "2").Return(dto.TeamsByUserResponse{}, nil)
+				},
+			},
+			outPut: "id,nick_name,name,last_name,email,enabled,email_verified,created_at,roles,teams,attributes.department,attributes.employee-id,attributes.locale,attributes.phone\n" +
+				"1,diegof,diego,fernandez,diegof@gmail.com,true,false,,user;admin,cow,,,es,\n" +
+				"2,diegoa,diego,\"alvarez, jr\",diegoa@gmail.com,false,false,,,,,,,\n",
+		},
+		{
+			name: "csv escapes formulas",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetAllUsers", mock.Anything, page(0, gocloak.GetUsersParams{})).Return([]*gocloak.User{{
+						ID:         gocloak.StringP("4"),
+						Username:   gocloak.StringP("diegoh"),
+						FirstName:  gocloak.StringP("=HYPERLINK(\"http://evil.com\")"),
+						LastName:   gocloak.StringP("@SUM(A1)"),
+						Attributes: &map[string][]string{"phone": {"+5491155555555"}, "department": {"-1"}},
+					}}, nil)
+				},
+			},
+			outPut: "id,nick_name,name,last_name,email,enabled,email_verified,created_at,attributes.department,attributes.employee-id,attributes.locale,attributes.phone\n" +
+				"4,diegoh,\"'=HYPERLINK(\"\"http://evil.com\"\")\",'@SUM(A1),,false,false,,'-1,,,'+5491155555555\n",
+		},
+		{
+			name:  "ndjson over several pages",
+			query: request.ExportQuery{Format: "ndjson"},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetAllUsers", mock.Anything, page(0, gocloak.GetUsersParams{})).Return([]*gocloak.User{diegof, diegoa}, nil)
+					f.keycloakClient.Mock.On("GetAllUsers", mock.Anything, page(2, gocloak.GetUsersParams{})).Return([]*gocloak.User{diegob}, nil)
+				},
+			},
+			outPut: `{"attributes":{"locale":"es"},"id":"1","name":"diego","last_name":"fernandez","email":"diegof@gmail.com","nick_name":"diegof","enabled":true,"email_verified":false}` + "\n" +
+				`{"id":"2","name":"diego","last_name":"alvarez, jr","email":"diegoa@gmail.com","nick_name":"diegoa","enabled":false,"email_verified":false}` + "\n" +
+				`{"id":"3","name":"","last_name":"","email":"","nick_name":"diegob","enabled":false,"email_verified":false}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockUserService{
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
+			}
+			tt.mocks.userService(m)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			service.(*userService).exportPageSize = 2
+			var out bytes.Buffer
+			err := service.Export(context.Background(), tt.query, &out)
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, out.String())
+			m.keycloakClient.AssertExpectations(t)
+			m.teamClient.AssertExpectations(t)
+		})
+	}
+}
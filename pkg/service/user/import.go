@@ -0,0 +1,159 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"cow_sso/api/handlers/user/request"
+	"cow_sso/api/handlers/user/response"
+	"cow_sso/api/validation"
+	"cow_sso/pkg/apperror"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	_defaultImportWorkers = 4
+	_defaultImportMaxRows = 500
+)
+
+// Import creates every user in rows through Create, running import.workers of them at a time.
+// Rows that break the request rules, or repeat the nick name or email of an earlier valid row, are
+// reported without calling keycloak. A dry run only checks the rows, nothing is created.
+func (us *userService) Import(ctx context.Context, rows []request.UserRequest, dryRun bool) (response.ImportResponse, error) {
+	importResponse := response.ImportResponse{DryRun: dryRun}
+	if len(rows) == 0 {
+		return importResponse, apperror.New(http.StatusBadRequest, "there are no users to import")
+	}
+	if len(rows) > us.importMaxRows {
+		return importResponse, apperror.New(http.StatusBadRequest, fmt.Sprintf("an import takes at most %d users, got %d", us.importMaxRows, len(rows)))
+	}
+
+	importResponse.Rows = make([]response.ImportRow, len(rows))
+	pending := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(max(us.importWorkers, 1), len(rows)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pending {
+				importResponse.Rows[i] = us.importRow(ctx, rows[i], dryRun)
+			}
+		}()
+	}
+
+	seen := map[string]int{}
+	for i, row := range rows {
+		if invalid, ok := invalidRow(row); ok {
+			importResponse.Rows[i] = invalid
+			continue
+		}
+		if earlier, ok := repeated(seen, i+1, row); ok {
+			importResponse.Rows[i] = response.ImportRow{Status: response.ImportSkippedDuplicate, Reason: earlier}
+			continue
+		}
+		pending <- i
+	}
+	close(pending)
+	wg.Wait()
+
+	for i := range importResponse.Rows {
+		importResponse.Rows[i].Row = i + 1
+		importResponse.Rows[i].NickName = rows[i].NickName
+		switch importResponse.Rows[i].Status {
+		case response.ImportCreated:
+			importResponse.Created++
+		case response.ImportSkippedDuplicate:
+			importResponse.Skipped++
+		default:
+			importResponse.Failed++
+		}
+	}
+	return importResponse, nil
+}
+
+// repeated records row's nick name and email in seen, telling when an earlier row already used them.
+func repeated(seen map[string]int, number int, row request.UserRequest) (string, bool) {
+	keys := map[string]string{
+		"nick_name": "nick_name:" + strings.ToLower(row.NickName),
+		"email":     "email:" + strings.ToLower(row.Email),
+	}
+	for _, field := range []string{"nick_name", "email"} {
+		if earlier, ok := seen[keys[field]]; ok {
+			return fmt.Sprintf("%s repeats row %d", field, earlier), true
+		}
+	}
+	for _, key := range keys {
+		seen[key] = number
+	}
+	return "", false
+}
+
+// invalidRow checks row against the request rules, before it gets to claim its nick name and email.
+func invalidRow(row request.UserRequest) (response.ImportRow, bool) {
+	var importRow response.ImportRow
+	err := binding.Validator.ValidateStruct(&row)
+	if err == nil {
+		return importRow, false
+	}
+	importRow.Status = response.ImportFailed
+	importRow.Reason = "invalid fields"
+	if fields, ok := validation.Fields(err); ok {
+		importRow.Fields = fields
+	} else {
+		importRow.Reason = err.Error()
+	}
+	return importRow, true
+}
+
+func (us *userService) importRow(ctx context.Context, row request.UserRequest, dryRun bool) response.ImportRow {
+	var importRow response.ImportRow
+	if err := ctx.Err(); err != nil {
+		importRow.Status = response.ImportFailed
+		importRow.Reason = err.Error()
+		return importRow
+	}
+
+	var err error
+	if dryRun {
+		err = us.checkNewUser(ctx, row)
+	} else {
+		err = us.Create(ctx, row)
+	}
+	switch {
+	case err == nil:
+		importRow.Status = response.ImportCreated
+	case apperror.Code(err, http.StatusInternalServerError) == http.StatusConflict:
+		importRow.Status = response.ImportSkippedDuplicate
+		importRow.Reason = err.Error()
+	default:
+		importRow.Status = response.ImportFailed
+		importRow.Reason = err.Error()
+	}
+	return importRow
+}
+
+// checkNewUser runs the checks Create does, and the ones keycloak would, without creating the user.
+func (us *userService) checkNewUser(ctx context.Context, row request.UserRequest) error {
+	if _, _, err := us.newUser(ctx, row); err != nil {
+		return err
+	}
+	available, err := us.nickNameAvailable(ctx, row.NickName)
+	if err != nil {
+		return err
+	}
+	if !available {
+		return apperror.Conflict("nick_name", fmt.Sprintf("nick name %s is taken", row.NickName))
+	}
+	available, err = us.emailAvailable(ctx, row.Email)
+	if err != nil {
+		return err
+	}
+	if !available {
+		return apperror.Conflict("email", fmt.Sprintf("email %s is taken", row.Email))
+	}
+	return nil
+}
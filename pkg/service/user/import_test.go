@@ -0,0 +1,139 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	apiErrors "cow_sso/api/handlers/errors"
+	"cow_sso/api/handlers/user/request"
+	"cow_sso/api/handlers/user/response"
+	"cow_sso/api/validation"
+	"cow_sso/mocks"
+	"cow_sso/pkg/apperror"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Import(t *testing.T) {
+	validation.Register()
+	row := func(nickName string, email string) request.UserRequest {
+		return request.UserRequest{
+			Name:     "diego",
+			LastName: "fernandez",
+			Email:    email,
+			NickName: nickName,
+		}
+	}
+	username := func(nickName string) any {
+		return mock.MatchedBy(func(user gocloak.User) bool { return gocloak.PString(user.Username) == nickName })
+	}
+	roles := []gocloak.Role{{Name: gocloak.StringP("user")}}
+
+	tests := []struct {
+		expErr  error
+		mocks   userMocks
+		name    string
+		rows    []request.UserRequest
+		outPut  response.ImportResponse
+		maxRows int
+		dryRun  bool
+	}{
+		{
+			name: "nothing to import",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "there are no users to import"),
+		},
+		{
+			name:    "too many rows",
+			rows:    []request.UserRequest{row("diegof", "diegof@gmail.com"), row("diegoa", "diegoa@gmail.com")},
+			maxRows: 1,
+			mocks: userMocks{
+				userService: func(f *mockUserService) {},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "an import takes at most 1 users, got 2"),
+		},
+		{
+			name: "full flow",
+			rows: []request.UserRequest{
+				row("diegof", "diegof@gmail.com"),
+				row("diegoa", "not an email"),
+				row("diegoe", "DiegoF@Gmail.com"),
+				row("diegob", "diegob@gmail.com"),
+				row("diegoc", "diegoc@gmail.com"),
+				row("diegoa", "diegoa@gmail.com"),
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"user"}).Return(roles, nil)
+					f.keycloakClient.Mock.On("CreateUser", mock.Anything, username("diegof")).Return("1", nil)
+					f.keycloakClient.Mock.On("AddRealmRolesToUser", mock.Anything, "1", roles).Return(nil)
+					f.keycloakClient.Mock.On("CreateUser", mock.Anything, username("diegob")).Return("", apperror.Conflict("email", "user with email diegob@gmail.com already exists"))
+					f.keycloakClient.Mock.On("CreateUser", mock.Anything, username("diegoc")).Return("", errors.New("some error"))
+					f.keycloakClient.Mock.On("CreateUser", mock.Anything, username("diegoa")).Return("2", nil)
+					f.keycloakClient.Mock.On("AddRealmRolesToUser", mock.Anything, "2", roles).Return(nil)
+				},
+			},
+			outPut: response.ImportResponse{
+				Rows: []response.ImportRow{
+					{Row: 1, NickName: "diegof", Status: response.ImportCreated},
+					{Row: 2, NickName: "diegoa", Status: response.ImportFailed, Reason: "invalid fields", Fields: []apiErrors.FieldError{{Field: "email", Rule: "email"}}},
+					{Row: 3, NickName: "diegoe", Status: response.ImportSkippedDuplicate, Reason: "email repeats row 1"},
+					{Row: 4, NickName: "diegob", Status: response.ImportSkippedDuplicate, Reason: "create_user: user with email diegob@gmail.com already exists"},
+					{Row: 5, NickName: "diegoc", Status: response.ImportFailed, Reason: "create_user: some error"},
+					{Row: 6, NickName: "diegoa", Status: response.ImportCreated},
+				},
+				Created: 2,
+				Skipped: 2,
+				Failed:  2,
+			},
+		},
+		{
+			name:   "dry run",
+			dryRun: true,
+			rows:   []request.UserRequest{row("diegof", "diegof@gmail.com"), row("diegoa", "diegoa@gmail.com")},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"user"}).Return(roles, nil)
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(nil, apperror.New(http.StatusNotFound, "user with nick name diegof doesn't exist"))
+					f.keycloakClient.Mock.On("GetUserByEmail", mock.Anything, "diegof@gmail.com").Return(nil, apperror.New(http.StatusNotFound, "user with email diegof@gmail.com doesn't exist"))
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegoa").Return(&gocloak.User{Username: gocloak.StringP("diegoa")}, nil)
+				},
+			},
+			outPut: response.ImportResponse{
+				Rows: []response.ImportRow{
+					{Row: 1, NickName: "diegof", Status: response.ImportCreated},
+					{Row: 2, NickName: "diegoa", Status: response.ImportSkippedDuplicate, Reason: "nick name diegoa is taken"},
+				},
+				Created: 1,
+				Skipped: 1,
+				DryRun:  true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockUserService{
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
+			}
+			tt.mocks.userService(m)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			if tt.maxRows > 0 {
+				service.(*userService).importMaxRows = tt.maxRows
+			}
+			report, err := service.Import(context.Background(), tt.rows, tt.dryRun)
+			assert.Equal(t, tt.expErr, err)
+			if err == nil {
+				assert.Equal(t, tt.outPut, report)
+			}
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"cow_sso/api/handlers/user/response"
+	"cow_sso/pkg/apperror"
+	"cow_sso/pkg/config"
+	offboardingDto "cow_sso/pkg/integration/offboarding/dto"
+	teamDto "cow_sso/pkg/integration/team/dto"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+// Offboarding modes, set under offboarding.mode in properties.yml, choose how the user ends up.
+const (
+	_offboardingDelete    = "delete"
+	_offboardingAnonymize = "anonymize"
+)
+
+// loadOffboardingMode reads offboarding.mode, anonymize when it isn't set. Any other value stops
+// the service from starting, instead of hard deleting users because of a typo in the config.
+func loadOffboardingMode() string {
+	mode := config.Get().UString("offboarding.mode", _offboardingAnonymize)
+	if mode != _offboardingDelete && mode != _offboardingAnonymize {
+		panic(fmt.Sprintf("offboarding.mode must be %s or %s, got %q", _offboardingDelete, _offboardingAnonymize, mode))
+	}
+	return mode
+}
+
+// Offboarding steps, run in this order.
+const (
+	_stepRevokeSessions = "revoke_sessions"
+	_stepRemoveRoles    = "remove_roles"
+	_stepDropTeams      = "drop_team_memberships"
+	_stepDeleteUser     = "delete_user"
+	_stepAnonymizeUser  = "anonymize_user"
+	_stepNotifyTeams    = "notify_teams"
+)
+
+// startOffboarding returns the unfinished offboarding of the user, to resume it, or checks the
+// deletion policy and starts a new one. An unfinished offboarding is resumed by the user id it
+// stored, since once delete_user or anonymize_user went through there's no user under the nick
+// name anymore. When the nick name belongs to someone else by now, that user gets a new offboarding.
+func (us *userService) startOffboarding(ctx context.Context, nickName string) (offboardingDto.Offboarding, error) {
+	offboarding, found, err := us.offboardingRepository.Get(nickName)
+	if err != nil {
+		return offboarding, err
+	}
+	unfinished := found && offboarding.Status != offboardingDto.StatusCompleted
+
+	user, err := us.keycloakClient.GetUserByNickName(ctx, nickName)
+	if unfinished && apperror.Code(err, 0) == http.StatusNotFound {
+		return offboarding, nil
+	}
+	if err != nil {
+		return offboarding, err
+	}
+	if unfinished && gocloak.PString(user.ID) == offboarding.UserID {
+		return offboarding, nil
+	}
+
+	teams, err := us.teamClient.GetTeamsByUser(ctx, gocloak.PString(user.ID))
+	if err != nil {
+		return offboarding, err
+	}
+	if blocking := blockingTeams(us.deletionPolicy, teams.Teams); len(blocking) > 0 {
+		return offboarding, deletionBlocked(us.deletionPolicy, gocloak.PString(user.Username), blocking)
+	}
+	return us.newOffboarding(user, teams.Teams), nil
+}
+
+// offboard revokes the user's sessions, removes its realm role mappings, asks the team api to
+// drop its memberships and then deletes or anonymizes it, telling the team api afterwards when
+// it belonged to any team. Every step is persisted as it finishes, so when one fails the next
+// call picks up from there.
+func (us *userService) offboard(ctx context.Context, offboarding offboardingDto.Offboarding) error {
+	offboarding.Status = offboardingDto.StatusRunning
+	if err := us.saveOffboarding(&offboarding); err != nil {
+		return err
+	}
+	for i := range offboarding.Steps {
+		step := &offboarding.Steps[i]
+		if step.Status == offboardingDto.StepDone {
+			continue
+		}
+		stepErr := us.runStep(ctx, step.Name, offboarding)
+		finishedAt := time.Now().UTC()
+		step.FinishedAt = &finishedAt
+		if stepErr != nil {
+			step.Status = offboardingDto.StepFailed
+			step.Error = stepErr.Error()
+			offboarding.Status = offboardingDto.StatusFailed
+			if err := us.saveOffboarding(&offboarding); err != nil {
+				return err
+			}
+			return fmt.Errorf("offboarding of user %s stopped at %s: %w", offboarding.NickName, step.Name, stepErr)
+		}
+		step.Status = offboardingDto.StepDone
+		step.Error = ""
+		if err := us.saveOffboarding(&offboarding); err != nil {
+			return err
+		}
+	}
+
+	offboarding.Status = offboardingDto.StatusCompleted
+	return us.saveOffboarding(&offboarding)
+}
+
+func (us *userService) newOffboarding(user *gocloak.User, teams []teamDto.TeamResponse) offboardingDto.Offboarding {
+	offboarding := offboardingDto.Offboarding{
+		StartedAt: time.Now().UTC(),
+		UserID:    gocloak.PString(user.ID),
+		NickName:  gocloak.PString(user.Username),
+	}
+	for _, team := range teams {
+		offboarding.Teams = append(offboarding.Teams, team.Code)
+	}
+
+	steps := []string{_stepRevokeSessions, _stepRemoveRoles, _stepDropTeams, _stepAnonymizeUser}
+	if us.offboardingMode == _offboardingDelete {
+		steps[len(steps)-1] = _stepDeleteUser
+	}
+	if len(offboarding.Teams) > 0 {
+		steps = append(steps, _stepNotifyTeams)
+	}
+	for _, name := range steps {
+		offboarding.Steps = append(offboarding.Steps, offboardingDto.Step{Name: name, Status: offboardingDto.StepPending})
+	}
+	return offboarding
+}
+
+func (us *userService) saveOffboarding(offboarding *offboardingDto.Offboarding) error {
+	offboarding.UpdatedAt = time.Now().UTC()
+	return us.offboardingRepository.Save(*offboarding)
+}
+
+// runStep runs the named step. Keycloak answering that the user doesn't exist completes the keycloak
+// steps, as happens when a delete went through but saving its step as done didn't.
+func (us *userService) runStep(ctx context.Context, name string, offboarding offboardingDto.Offboarding) error {
+	switch name {
+	case _stepRevokeSessions:
+		return ignoreGone(us.keycloakClient.LogoutUserSessions(ctx, offboarding.UserID))
+	case _stepRemoveRoles:
+		return ignoreGone(us.removeRoles(ctx, offboarding.UserID))
+	case _stepDropTeams:
+		if len(offboarding.Teams) == 0 {
+			return nil
+		}
+		return us.teamClient.RemoveUserFromTeams(ctx, offboarding.UserID)
+	case _stepDeleteUser:
+		return ignoreGone(us.keycloakClient.DeleteUserByID(ctx, offboarding.UserID))
+	case _stepAnonymizeUser:
+		return ignoreGone(us.anonymize(ctx, offboarding.UserID))
+	case _stepNotifyTeams:
+		return us.teamClient.NotifyUserDeleted(ctx, teamDto.UserDeletedNotification{
+			UserID:   offboarding.UserID,
+			NickName: offboarding.NickName,
+			Teams:    offboarding.Teams,
+		})
+	}
+	return fmt.Errorf("unknown offboarding step %s", name)
+}
+
+// ignoreGone drops err when it's keycloak answering that the user doesn't exist.
+func ignoreGone(err error) error {
+	var apiErr *gocloak.APIError
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		return nil
+	}
+	if apperror.Code(err, 0) == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+func (us *userService) removeRoles(ctx context.Context, userID string) error {
+	roles, err := us.keycloakClient.GetUserRealmRoles(ctx, userID)
+	if err != nil || len(roles) == 0 {
+		return err
+	}
+	mapped := make([]gocloak.Role, 0, len(roles))
+	for _, role := range roles {
+		mapped = append(mapped, *role)
+	}
+	return us.keycloakClient.DeleteRealmRolesFromUser(ctx, userID, mapped)
+}
+
+// anonymize keeps the user, disabled, but replaces everything that could identify the person behind it.
+func (us *userService) anonymize(ctx context.Context, userID string) error {
+	user, err := us.keycloakClient.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	user.Username = gocloak.StringP("deleted-" + userID)
+	user.FirstName = gocloak.StringP("deleted")
+	user.LastName = gocloak.StringP("deleted")
+	user.Email = gocloak.StringP("")
+	user.EmailVerified = gocloak.BoolP(false)
+	user.Enabled = gocloak.BoolP(false)
+	user.Attributes = &map[string][]string{}
+	return us.keycloakClient.UpdateUser(ctx, *user)
+}
+
+// GetOffboarding returns the last offboarding of the user, which is still around once the user is gone.
+func (us *userService) GetOffboarding(ctx context.Context, nickName string) (response.OffboardingResponse, error) {
+	var offboardingResponse response.OffboardingResponse
+	offboarding, found, err := us.offboardingRepository.Get(nickName)
+	if err != nil {
+		return offboardingResponse, err
+	}
+	if !found {
+		return offboardingResponse, apperror.New(http.StatusNotFound, fmt.Sprintf("user %s was never offboarded", nickName))
+	}
+
+	offboardingResponse = response.OffboardingResponse{
+		StartedAt: offboarding.StartedAt,
+		UpdatedAt: offboarding.UpdatedAt,
+		UserID:    offboarding.UserID,
+		NickName:  offboarding.NickName,
+		Status:    offboarding.Status,
+		Steps:     make([]response.OffboardingStepResponse, 0, len(offboarding.Steps)),
+	}
+	for _, step := range offboarding.Steps {
+		offboardingResponse.Steps = append(offboardingResponse.Steps, response.OffboardingStepResponse{
+			FinishedAt: step.FinishedAt,
+			Name:       step.Name,
+			Status:     step.Status,
+			Error:      step.Error,
+		})
+	}
+	return offboardingResponse, nil
+}
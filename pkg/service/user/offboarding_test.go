@@ -0,0 +1,305 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"cow_sso/api/handlers/user/response"
+	"cow_sso/mocks"
+	"cow_sso/pkg/apperror"
+	"cow_sso/pkg/config"
+	offboardingDto "cow_sso/pkg/integration/offboarding/dto"
+	"cow_sso/pkg/integration/team/dto"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_offboard_resume(t *testing.T) {
+	m := &mockUserService{
+		keycloakClient:        &mocks.IKeycloakClient{},
+		teamClient:            &mocks.ITeamClient{},
+		offboardingRepository: &mocks.IOffboardingRepository{},
+	}
+	failed := offboardingDto.Offboarding{
+		UserID:   "AXYZT",
+		NickName: "diego",
+		Status:   offboardingDto.StatusFailed,
+		Steps: []offboardingDto.Step{
+			{Name: _stepRevokeSessions, Status: offboardingDto.StepDone},
+			{Name: _stepRemoveRoles, Status: offboardingDto.StepDone},
+			{Name: _stepDropTeams, Status: offboardingDto.StepDone},
+			{Name: _stepDeleteUser, Status: offboardingDto.StepFailed, Error: "some error"},
+		},
+	}
+	var saved offboardingDto.Offboarding
+	m.offboardingRepository.Mock.On("Get", "diego").Return(failed, true, nil)
+	m.offboardingRepository.Mock.On("Save", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(offboardingDto.Offboarding)
+	}).Return(nil)
+	m.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diego").Return(&gocloak.User{ID: gocloak.StringP("AXYZT"), Username: gocloak.StringP("diego")}, nil)
+	m.keycloakClient.Mock.On("DeleteUserByID", mock.Anything, "AXYZT").Return(nil)
+
+	service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+	userName, err := service.Delete(context.Background(), "diego")
+	assert.NoError(t, err)
+	assert.Equal(t, "diego", userName)
+	assert.Equal(t, offboardingDto.StatusCompleted, saved.Status)
+	assert.Equal(t, offboardingDto.StepDone, saved.Steps[3].Status)
+	assert.Empty(t, saved.Steps[3].Error)
+	m.keycloakClient.AssertExpectations(t)
+	m.teamClient.AssertNotCalled(t, "GetTeamsByUser", mock.Anything, mock.Anything)
+}
+
+func Test_offboard_resume_after_user_is_gone(t *testing.T) {
+	tests := []struct {
+		name       string
+		removeStep string
+	}{
+		{name: "after delete", removeStep: _stepDeleteUser},
+		{name: "after anonymize", removeStep: _stepAnonymizeUser},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockUserService{
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
+			}
+			failed := offboardingDto.Offboarding{
+				UserID:   "AXYZT",
+				NickName: "diego",
+				Status:   offboardingDto.StatusFailed,
+				Teams:    []string{"xs"},
+				Steps: []offboardingDto.Step{
+					{Name: _stepRevokeSessions, Status: offboardingDto.StepDone},
+					{Name: _stepRemoveRoles, Status: offboardingDto.StepDone},
+					{Name: _stepDropTeams, Status: offboardingDto.StepDone},
+					{Name: tt.removeStep, Status: offboardingDto.StepDone},
+					{Name: _stepNotifyTeams, Status: offboardingDto.StepFailed, Error: "some error"},
+				},
+			}
+			var saved offboardingDto.Offboarding
+			m.offboardingRepository.Mock.On("Get", "diego").Return(failed, true, nil)
+			m.offboardingRepository.Mock.On("Save", mock.Anything).Run(func(args mock.Arguments) {
+				saved = args.Get(0).(offboardingDto.Offboarding)
+			}).Return(nil)
+			m.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diego").Return(nil, apperror.New(http.StatusNotFound, "user with nick name diego doesn't exist"))
+			m.teamClient.Mock.On("NotifyUserDeleted", mock.Anything, dto.UserDeletedNotification{
+				UserID:   "AXYZT",
+				NickName: "diego",
+				Teams:    []string{"xs"},
+			}).Return(nil)
+
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			userName, err := service.Delete(context.Background(), "diego")
+			assert.NoError(t, err)
+			assert.Equal(t, "diego", userName)
+			assert.Equal(t, offboardingDto.StatusCompleted, saved.Status)
+			assert.Equal(t, offboardingDto.StepDone, saved.Steps[4].Status)
+			m.keycloakClient.AssertExpectations(t)
+			m.teamClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_offboard_nick_name_taken_again(t *testing.T) {
+	m := &mockUserService{
+		keycloakClient:        &mocks.IKeycloakClient{},
+		teamClient:            &mocks.ITeamClient{},
+		offboardingRepository: &mocks.IOffboardingRepository{},
+	}
+	failed := offboardingDto.Offboarding{
+		UserID:   "AXYZT",
+		NickName: "diego",
+		Status:   offboardingDto.StatusFailed,
+		Teams:    []string{"xs"},
+		Steps: []offboardingDto.Step{
+			{Name: _stepRevokeSessions, Status: offboardingDto.StepDone},
+			{Name: _stepRemoveRoles, Status: offboardingDto.StepDone},
+			{Name: _stepDropTeams, Status: offboardingDto.StepDone},
+			{Name: _stepDeleteUser, Status: offboardingDto.StepDone},
+			{Name: _stepNotifyTeams, Status: offboardingDto.StepFailed, Error: "some error"},
+		},
+	}
+	m.offboardingRepository.Mock.On("Get", "diego").Return(failed, true, nil)
+	m.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diego").Return(&gocloak.User{ID: gocloak.StringP("BNEW1"), Username: gocloak.StringP("diego")}, nil)
+	m.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "BNEW1").Return(dto.TeamsByUserResponse{Teams: []dto.TeamResponse{{Code: "cow"}}}, nil)
+
+	service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+	_, err := service.Delete(context.Background(), "diego")
+	assert.Equal(t, http.StatusConflict, apperror.Code(err, 0))
+	assert.EqualError(t, err, "user diego can't be deleted while belonging to the teams cow")
+	m.keycloakClient.AssertExpectations(t)
+	m.teamClient.AssertExpectations(t)
+	m.teamClient.AssertNotCalled(t, "NotifyUserDeleted", mock.Anything, mock.Anything)
+	m.offboardingRepository.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func Test_offboard_resume_delete_already_done(t *testing.T) {
+	m := &mockUserService{
+		keycloakClient:        &mocks.IKeycloakClient{},
+		teamClient:            &mocks.ITeamClient{},
+		offboardingRepository: &mocks.IOffboardingRepository{},
+	}
+	failed := offboardingDto.Offboarding{
+		UserID:   "AXYZT",
+		NickName: "diego",
+		Status:   offboardingDto.StatusFailed,
+		Steps: []offboardingDto.Step{
+			{Name: _stepRevokeSessions, Status: offboardingDto.StepDone},
+			{Name: _stepRemoveRoles, Status: offboardingDto.StepDone},
+			{Name: _stepDropTeams, Status: offboardingDto.StepDone},
+			{Name: _stepDeleteUser, Status: offboardingDto.StepFailed, Error: "some error"},
+		},
+	}
+	var saved offboardingDto.Offboarding
+	m.offboardingRepository.Mock.On("Get", "diego").Return(failed, true, nil)
+	m.offboardingRepository.Mock.On("Save", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(offboardingDto.Offboarding)
+	}).Return(nil)
+	m.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diego").Return(&gocloak.User{ID: gocloak.StringP("AXYZT"), Username: gocloak.StringP("diego")}, nil)
+	m.keycloakClient.Mock.On("DeleteUserByID", mock.Anything, "AXYZT").Return(&gocloak.APIError{Code: http.StatusNotFound, Message: "404 Not Found"})
+
+	service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+	_, err := service.Delete(context.Background(), "diego")
+	assert.NoError(t, err)
+	assert.Equal(t, offboardingDto.StatusCompleted, saved.Status)
+	m.keycloakClient.AssertExpectations(t)
+}
+
+func Test_offboard_anonymize(t *testing.T) {
+	m := &mockUserService{
+		keycloakClient:        &mocks.IKeycloakClient{},
+		teamClient:            &mocks.ITeamClient{},
+		offboardingRepository: &mocks.IOffboardingRepository{},
+	}
+	current := &gocloak.User{
+		ID:         gocloak.StringP("AXYZT"),
+		Username:   gocloak.StringP("diego"),
+		FirstName:  gocloak.StringP("diego"),
+		LastName:   gocloak.StringP("fernandez"),
+		Email:      gocloak.StringP("diego@gmail.com"),
+		Enabled:    gocloak.BoolP(true),
+		Attributes: &map[string][]string{"phone": {"+5491122334455"}},
+	}
+	var saved offboardingDto.Offboarding
+	m.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diego").Return(current, nil)
+	m.offboardingRepository.Mock.On("Get", "diego").Return(offboardingDto.Offboarding{}, false, nil)
+	m.offboardingRepository.Mock.On("Save", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(offboardingDto.Offboarding)
+	}).Return(nil)
+	m.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "AXYZT").Return(dto.TeamsByUserResponse{}, nil)
+	m.keycloakClient.Mock.On("LogoutUserSessions", mock.Anything, "AXYZT").Return(nil)
+	m.keycloakClient.Mock.On("GetUserRealmRoles", mock.Anything, "AXYZT").Return([]*gocloak.Role{}, nil)
+	m.keycloakClient.Mock.On("GetUserByID", mock.Anything, "AXYZT").Return(current, nil)
+	m.keycloakClient.Mock.On("UpdateUser", mock.Anything, gocloak.User{
+		ID:            gocloak.StringP("AXYZT"),
+		Username:      gocloak.StringP("deleted-AXYZT"),
+		FirstName:     gocloak.StringP("deleted"),
+		LastName:      gocloak.StringP("deleted"),
+		Email:         gocloak.StringP(""),
+		EmailVerified: gocloak.BoolP(false),
+		Enabled:       gocloak.BoolP(false),
+		Attributes:    &map[string][]string{},
+	}).Return(nil)
+
+	service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+	service.(*userService).offboardingMode = _offboardingAnonymize
+	_, err := service.Delete(context.Background(), "diego")
+	assert.NoError(t, err)
+	assert.Equal(t, offboardingDto.StatusCompleted, saved.Status)
+	var steps []string
+	for _, step := range saved.Steps {
+		steps = append(steps, step.Name)
+	}
+	assert.Equal(t, []string{_stepRevokeSessions, _stepRemoveRoles, _stepDropTeams, _stepAnonymizeUser}, steps)
+	m.keycloakClient.AssertExpectations(t)
+}
+
+func Test_loadOffboardingMode(t *testing.T) {
+	configured := config.Get().UString("offboarding.mode")
+	defer func() { _ = config.Get().Set("offboarding.mode", configured) }()
+
+	_ = config.Get().Set("offboarding.mode", "anonymise")
+	assert.PanicsWithValue(t, `offboarding.mode must be delete or anonymize, got "anonymise"`, func() { loadOffboardingMode() })
+
+	_ = config.Get().Set("offboarding.mode", "delete")
+	assert.Equal(t, _offboardingDelete, loadOffboardingMode())
+}
+
+func Test_GetOffboarding(t *testing.T) {
+	finishedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expErr error
+		mocks  userMocks
+		name   string
+		outPut response.OffboardingResponse
+	}{
+		{
+			name: "error reading offboarding",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.offboardingRepository.Mock.On("Get", "diego").Return(offboardingDto.Offboarding{}, false, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "never offboarded",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.offboardingRepository.Mock.On("Get", "diego").Return(offboardingDto.Offboarding{}, false, nil)
+				},
+			},
+			expErr: apperror.New(http.StatusNotFound, "user diego was never offboarded"),
+		},
+		{
+			name: "full flow",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.offboardingRepository.Mock.On("Get", "diego").Return(offboardingDto.Offboarding{
+						StartedAt: finishedAt,
+						UpdatedAt: finishedAt,
+						UserID:    "AXYZT",
+						NickName:  "diego",
+						Status:    offboardingDto.StatusFailed,
+						Steps: []offboardingDto.Step{
+							{Name: _stepRevokeSessions, Status: offboardingDto.StepFailed, Error: "some error", FinishedAt: &finishedAt},
+							{Name: _stepRemoveRoles, Status: offboardingDto.StepPending},
+						},
+					}, true, nil)
+				},
+			},
+			outPut: response.OffboardingResponse{
+				StartedAt: finishedAt,
+				UpdatedAt: finishedAt,
+				UserID:    "AXYZT",
+				NickName:  "diego",
+				Status:    "failed",
+				Steps: []response.OffboardingStepResponse{
+					{Name: "revoke_sessions", Status: "failed", Error: "some error", FinishedAt: &finishedAt},
+					{Name: "remove_roles", Status: "pending"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockUserService{
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
+			}
+			tt.mocks.userService(m)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			offboarding, err := service.GetOffboarding(context.Background(), "diego")
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, offboarding)
+		})
+	}
+}
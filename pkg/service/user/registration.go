@@ -0,0 +1,137 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"cow_sso/api/handlers/user/request"
+	"cow_sso/api/handlers/user/response"
+	"cow_sso/pkg/apperror"
+	"cow_sso/pkg/config"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+// Registration modes, set under registration.mode in properties.yml.
+const (
+	// _registrationVerifyEmail lets new users sign in once they verify their email.
+	_registrationVerifyEmail = "verify-email"
+	// _registrationApproval keeps new users disabled until an admin approves them.
+	_registrationApproval = "approval"
+)
+
+const (
+	// _registrationAttribute marks the users waiting in the approval queue.
+	_registrationAttribute = "registration"
+	_registrationPending   = "pending"
+)
+
+// _verifyEmailActions are the actions emailed to users who signed themselves up.
+var _verifyEmailActions = []string{"VERIFY_EMAIL"}
+
+// loadAllowedDomains reads registration.allowed-domains, lower cased.
+func loadAllowedDomains() []string {
+	var domains []string
+	for _, domain := range config.Get().UList("registration.allowed-domains") {
+		if name, ok := domain.(string); ok {
+			domains = append(domains, strings.ToLower(name))
+		}
+	}
+	return domains
+}
+
+// Register signs up a user with the default roles. Under verify-email the account can't sign in
+// until the user follows the link keycloak emails, under approval it's disabled and queued until
+// an admin approves it.
+func (us *userService) Register(ctx context.Context, registerRequest request.RegisterRequest) (response.RegistrationResponse, error) {
+	var registrationResponse response.RegistrationResponse
+	if err := us.checkDomain(registerRequest.Email); err != nil {
+		return registrationResponse, err
+	}
+
+	userRequest := request.UserRequest{
+		Name:     registerRequest.Name,
+		LastName: registerRequest.LastName,
+		Email:    registerRequest.Email,
+		NickName: registerRequest.NickName,
+		Password: registerRequest.Password,
+	}
+	status := response.RegistrationVerifyEmail
+	prepare := func(user *gocloak.User) {
+		user.RequiredActions = &[]string{"VERIFY_EMAIL"}
+	}
+	emailActions := _verifyEmailActions
+	if us.registrationMode == _registrationApproval {
+		status = response.RegistrationPendingApproval
+		prepare = func(user *gocloak.User) {
+			user.Enabled = gocloak.BoolP(false)
+			user.Attributes = &map[string][]string{_registrationAttribute: {_registrationPending}}
+		}
+		emailActions = nil
+	}
+
+	if err := us.create(ctx, userRequest, prepare, emailActions); err != nil {
+		return registrationResponse, err
+	}
+	registrationResponse.NickName = registerRequest.NickName
+	registrationResponse.Status = status
+	return registrationResponse, nil
+}
+
+// checkDomain refuses emails outside registration.allowed-domains, when there are any.
+func (us *userService) checkDomain(email string) error {
+	if len(us.allowedDomains) == 0 {
+		return nil
+	}
+	domain := strings.ToLower(email[strings.LastIndex(email, "@")+1:])
+	if !slices.Contains(us.allowedDomains, domain) {
+		return apperror.New(http.StatusForbidden, fmt.Sprintf("email domain %s can't register", domain))
+	}
+	return nil
+}
+
+// GetRegistrations returns one page of the users waiting for approval, see GetAll. The query filters are ignored.
+func (us *userService) GetRegistrations(ctx context.Context, query request.UserQuery) (response.UsersResponse, error) {
+	pending := fmt.Sprintf("%s:%s", _registrationAttribute, _registrationPending)
+	return us.list(ctx, query, gocloak.GetUsersParams{Q: &pending})
+}
+
+// ApproveRegistration enables the user and takes it out of the approval queue.
+func (us *userService) ApproveRegistration(ctx context.Context, nickName string) (response.UserResponse, error) {
+	var userResponse response.UserResponse
+	user, err := us.pendingUser(ctx, nickName)
+	if err != nil {
+		return userResponse, err
+	}
+
+	delete(*user.Attributes, _registrationAttribute)
+	user.Enabled = gocloak.BoolP(true)
+	if err := us.keycloakClient.UpdateUser(ctx, *user); err != nil {
+		return userResponse, err
+	}
+	return toUserResponse(user, us.attributes), nil
+}
+
+// RejectRegistration deletes the user. It never signed in, so there's nothing to offboard.
+func (us *userService) RejectRegistration(ctx context.Context, nickName string) error {
+	user, err := us.pendingUser(ctx, nickName)
+	if err != nil {
+		return err
+	}
+	return us.keycloakClient.DeleteUserByID(ctx, *user.ID)
+}
+
+// pendingUser returns the user, as long as it's waiting in the approval queue.
+func (us *userService) pendingUser(ctx context.Context, nickName string) (*gocloak.User, error) {
+	user, err := us.keycloakClient.GetUserByNickName(ctx, nickName)
+	if err != nil {
+		return nil, err
+	}
+	if firstAttribute(user, _registrationAttribute) != _registrationPending {
+		return nil, apperror.New(http.StatusNotFound, fmt.Sprintf("user %s isn't waiting for approval", nickName))
+	}
+	return user, nil
+}
@@ -0,0 +1,245 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"cow_sso/api/handlers/user/request"
+	"cow_sso/api/handlers/user/response"
+	"cow_sso/mocks"
+	"cow_sso/pkg/apperror"
+	"cow_sso/pkg/saga"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Register(t *testing.T) {
+	registerRequest := request.RegisterRequest{
+		Name:     "diego",
+		LastName: "fernandez",
+		Email:    "diego@Cow.com",
+		NickName: "diegof",
+		Password: "secret-password",
+	}
+	roles := []gocloak.Role{{Name: gocloak.StringP("user")}}
+
+	tests := []struct {
+		expErr         error
+		mocks          userMocks
+		name           string
+		mode           string
+		allowedDomains []string
+		outPut         response.RegistrationResponse
+	}{
+		{
+			name:           "email domain not allowed",
+			allowedDomains: []string{"gmail.com"},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {},
+			},
+			expErr: apperror.New(http.StatusForbidden, "email domain cow.com can't register"),
+		},
+		{
+			name:           "verify email",
+			allowedDomains: []string{"cow.com"},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"user"}).Return(roles, nil)
+					f.keycloakClient.Mock.On("CreateUser", mock.Anything, mock.MatchedBy(func(user gocloak.User) bool {
+						return gocloak.PBool(user.Enabled) && user.RequiredActions != nil && (*user.RequiredActions)[0] == "VERIFY_EMAIL" &&
+							user.Credentials != nil && gocloak.PString((*user.Credentials)[0].Value) == "secret-password"
+					})).Return("1", nil)
+					f.keycloakClient.Mock.On("AddRealmRolesToUser", mock.Anything, "1", roles).Return(nil)
+					f.keycloakClient.Mock.On("SendActionsEmail", mock.Anything, "1", []string{"VERIFY_EMAIL"}).Return(nil)
+				},
+			},
+			outPut: response.RegistrationResponse{NickName: "diegof", Status: response.RegistrationVerifyEmail},
+		},
+		{
+			name: "verify email fails, user deleted again",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"user"}).Return(roles, nil)
+					f.keycloakClient.Mock.On("CreateUser", mock.Anything, mock.Anything).Return("1", nil)
+					f.keycloakClient.Mock.On("AddRealmRolesToUser", mock.Anything, "1", roles).Return(nil)
+					f.keycloakClient.Mock.On("SendActionsEmail", mock.Anything, "1", []string{"VERIFY_EMAIL"}).Return(errors.New("some error"))
+					f.keycloakClient.Mock.On("DeleteUserByID", mock.Anything, "1").Return(nil)
+				},
+			},
+			expErr: &saga.Error{Step: "send_actions_email", Err: errors.New("some error")},
+		},
+		{
+			name: "approval",
+			mode: _registrationApproval,
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"user"}).Return(roles, nil)
+					f.keycloakClient.Mock.On("CreateUser", mock.Anything, mock.MatchedBy(func(user gocloak.User) bool {
+						return !gocloak.PBool(user.Enabled) && user.Attributes != nil && (*user.Attributes)["registration"][0] == "pending"
+					})).Return("1", nil)
+					f.keycloakClient.Mock.On("AddRealmRolesToUser", mock.Anything, "1", roles).Return(nil)
+				},
+			},
+			outPut: response.RegistrationResponse{NickName: "diegof", Status: response.RegistrationPendingApproval},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockUserService{
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
+			}
+			tt.mocks.userService(m)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			service.(*userService).allowedDomains = tt.allowedDomains
+			if tt.mode != "" {
+				service.(*userService).registrationMode = tt.mode
+			}
+			registration, err := service.Register(context.Background(), registerRequest)
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, registration)
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_GetRegistrations(t *testing.T) {
+	m := &mockUserService{
+		keycloakClient:        &mocks.IKeycloakClient{},
+		teamClient:            &mocks.ITeamClient{},
+		offboardingRepository: &mocks.IOffboardingRepository{},
+	}
+	pending := gocloak.GetUsersParams{Q: gocloak.StringP("registration:pending")}
+	page := pending
+	page.First = gocloak.IntP(0)
+	page.Max = gocloak.IntP(20)
+	m.keycloakClient.Mock.On("CountUsers", mock.Anything, pending).Return(1, nil)
+	m.keycloakClient.Mock.On("GetAllUsers", mock.Anything, page).Return([]*gocloak.User{{Username: gocloak.StringP("diegof")}}, nil)
+
+	service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+	users, err := service.GetRegistrations(context.Background(), request.UserQuery{UserFilter: request.UserFilter{Search: "ignored"}})
+	assert.NoError(t, err)
+	assert.Equal(t, response.UsersResponse{Users: []response.UserResponse{{NickName: "diegof"}}, Total: 1, Max: 20}, users)
+	m.keycloakClient.AssertExpectations(t)
+}
+
+func Test_ApproveRegistration(t *testing.T) {
+	pendingUser := func() *gocloak.User {
+		return &gocloak.User{
+			ID:         gocloak.StringP("1"),
+			Username:   gocloak.StringP("diegof"),
+			Enabled:    gocloak.BoolP(false),
+			Attributes: &map[string][]string{"registration": {"pending"}, "locale": {"es"}},
+		}
+	}
+
+	tests := []struct {
+		expErr error
+		mocks  userMocks
+		name   string
+		outPut response.UserResponse
+	}{
+		{
+			name: "user not waiting for approval",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(&gocloak.User{Username: gocloak.StringP("diegof")}, nil)
+				},
+			},
+			expErr: apperror.New(http.StatusNotFound, "user diegof isn't waiting for approval"),
+		},
+		{
+			name: "update fails",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(pendingUser(), nil)
+					f.keycloakClient.Mock.On("UpdateUser", mock.Anything, mock.Anything).Return(errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "full flow",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(pendingUser(), nil)
+					f.keycloakClient.Mock.On("UpdateUser", mock.Anything, gocloak.User{
+						ID:         gocloak.StringP("1"),
+						Username:   gocloak.StringP("diegof"),
+						Enabled:    gocloak.BoolP(true),
+						Attributes: &map[string][]string{"locale": {"es"}},
+					}).Return(nil)
+				},
+			},
+			outPut: response.UserResponse{ID: "1", NickName: "diegof", Enabled: true, Attributes: map[string]string{"locale": "es"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockUserService{
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
+			}
+			tt.mocks.userService(m)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			user, err := service.ApproveRegistration(context.Background(), "diegof")
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, user)
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_RejectRegistration(t *testing.T) {
+	tests := []struct {
+		expErr error
+		mocks  userMocks
+		name   string
+	}{
+		{
+			name: "user not waiting for approval",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(&gocloak.User{
+						Username:   gocloak.StringP("diegof"),
+						Attributes: &map[string][]string{"locale": {"es"}},
+					}, nil)
+				},
+			},
+			expErr: apperror.New(http.StatusNotFound, "user diegof isn't waiting for approval"),
+		},
+		{
+			name: "full flow",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(&gocloak.User{
+						ID:         gocloak.StringP("1"),
+						Username:   gocloak.StringP("diegof"),
+						Attributes: &map[string][]string{"registration": {"pending"}},
+					}, nil)
+					f.keycloakClient.Mock.On("DeleteUserByID", mock.Anything, "1").Return(nil)
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockUserService{
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
+			}
+			tt.mocks.userService(m)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			err := service.RejectRegistration(context.Background(), "diegof")
+			assert.Equal(t, tt.expErr, err)
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
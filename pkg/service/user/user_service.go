@@ -2,111 +2,686 @@ package user
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
 
 	"cow_sso/api/handlers/user/request"
 	"cow_sso/api/handlers/user/response"
+	"cow_sso/pkg/apperror"
+	"cow_sso/pkg/config"
 	"cow_sso/pkg/integration/keycloak"
+	"cow_sso/pkg/integration/offboarding"
 	"cow_sso/pkg/integration/team"
+	"cow_sso/pkg/saga"
 
 	"github.com/Nerzal/gocloak/v13"
 )
 
 const (
-	_roleName       = "user"
-	_getTeamsByUser = "/teams/user"
+	_getTeamsByUser  = "/teams/user"
+	_defaultPageSize = 20
+	_maxPageSize     = 100
+	_expandRoles     = "roles"
+	_expandGroups    = "groups"
+	_expandTeams     = "teams"
 )
 
+// _defaultSortMaxUsers is how many matching users GET /users sorts at most, see GetAll.
+const _defaultSortMaxUsers = 1000
+
+// _setPasswordActions are the actions emailed to new users who didn't get an initial password.
+var _setPasswordActions = []string{"UPDATE_PASSWORD", "VERIFY_EMAIL"}
+
+// _sortFields maps the sort keys accepted by GetAll to the field they order by.
+var _sortFields = map[string]func(user response.UserResponse) string{
+	"nick_name": func(user response.UserResponse) string { return user.NickName },
+	"name":      func(user response.UserResponse) string { return user.Name },
+	"last_name": func(user response.UserResponse) string { return user.LastName },
+	"email":     func(user response.UserResponse) string { return user.Email },
+}
+
 type IUserService interface {
-	GetAll(ctx context.Context, token string) ([]response.UserResponse, error)
-	GetByNickName(ctx context.Context, token string, nickName string) (response.UserResponse, error)
-	Create(ctx context.Context, token string, userRequest request.UserRequest) error
-	Delete(ctx context.Context, token string, userID string) (string, error)
+	GetAll(ctx context.Context, query request.UserQuery) (response.UsersResponse, error)
+	Export(ctx context.Context, query request.ExportQuery, w io.Writer) error
+	GetByNickName(ctx context.Context, nickName string, expand []string) (response.UserResponse, error)
+	GetByID(ctx context.Context, userID string, expand []string) (response.UserResponse, error)
+	GetByEmail(ctx context.Context, email string, expand []string) (response.UserResponse, error)
+	Availability(ctx context.Context, query request.AvailabilityQuery) (response.AvailabilityResponse, error)
+	Create(ctx context.Context, userRequest request.UserRequest) error
+	Register(ctx context.Context, registerRequest request.RegisterRequest) (response.RegistrationResponse, error)
+	GetRegistrations(ctx context.Context, query request.UserQuery) (response.UsersResponse, error)
+	ApproveRegistration(ctx context.Context, nickName string) (response.UserResponse, error)
+	RejectRegistration(ctx context.Context, nickName string) error
+	Import(ctx context.Context, rows []request.UserRequest, dryRun bool) (response.ImportResponse, error)
+	Update(ctx context.Context, nickName string, updateRequest request.UpdateUserRequest, partial bool, ifMatch string) (response.UserResponse, error)
+	SetEnabled(ctx context.Context, nickName string, enabled bool) (response.UserResponse, error)
+	ResetPassword(ctx context.Context, nickName string, passwordRequest request.PasswordRequest) error
+	GetAttribute(ctx context.Context, nickName string, key string) (response.AttributeResponse, error)
+	SetAttribute(ctx context.Context, nickName string, key string, value string) (response.AttributeResponse, error)
+	DeleteAttribute(ctx context.Context, nickName string, key string) error
+	Delete(ctx context.Context, userID string) (string, error)
+	GetOffboarding(ctx context.Context, nickName string) (response.OffboardingResponse, error)
 }
 
 type userService struct {
-	keycloakClient keycloak.IKeycloakClient
-	teamClient     team.ITeamClient
+	keycloakClient        keycloak.IKeycloakClient
+	teamClient            team.ITeamClient
+	offboardingRepository offboarding.IOffboardingRepository
+	attributes            attributeSchema
+	defaultRoles          []string
+	importWorkers         int
+	importMaxRows         int
+	exportPageSize        int
+	sortMaxUsers          int
+	deletionPolicy        string
+	offboardingMode       string
+	registrationMode      string
+	allowedDomains        []string
 }
 
 func NewUserService(keycloakClient keycloak.IKeycloakClient,
 	teamClient team.ITeamClient,
+	offboardingRepository offboarding.IOffboardingRepository,
 ) IUserService {
+	var defaultRoles []string
+	for _, role := range config.Get().UList("keycloak.default-roles") {
+		if name, ok := role.(string); ok {
+			defaultRoles = append(defaultRoles, name)
+		}
+	}
 	return &userService{
-		keycloakClient: keycloakClient,
-		teamClient:     teamClient,
+		keycloakClient:        keycloakClient,
+		teamClient:            teamClient,
+		offboardingRepository: offboardingRepository,
+		attributes:            loadAttributeSchema(),
+		defaultRoles:          defaultRoles,
+		importWorkers:         config.Get().UInt("import.workers", _defaultImportWorkers),
+		importMaxRows:         config.Get().UInt("import.max-rows", _defaultImportMaxRows),
+		exportPageSize:        config.Get().UInt("export.page-size", _maxPageSize),
+		sortMaxUsers:          config.Get().UInt("sort.max-users", _defaultSortMaxUsers),
+		deletionPolicy:        config.Get().UString("deletion.policy", _deletionBlockAny),
+		offboardingMode:       loadOffboardingMode(),
+		registrationMode:      config.Get().UString("registration.mode", _registrationVerifyEmail),
+		allowedDomains:        loadAllowedDomains(),
 	}
 }
 
-func (us *userService) GetAll(ctx context.Context, token string) ([]response.UserResponse, error) {
-	var userResponse []response.UserResponse
-	users, err := us.keycloakClient.GetAllUsers(ctx, token)
+// GetAll returns one page of users. Keycloak can't sort, so when query.Sort is set every matching
+// user is read and sorted before taking the page, ascending by default or descending when prefixed
+// with "-". Sorting more than sort.max-users users is refused, narrowing the filters gets under it.
+func (us *userService) GetAll(ctx context.Context, query request.UserQuery) (response.UsersResponse, error) {
+	filters, err := us.filters(query.UserFilter)
 	if err != nil {
-		return userResponse, err
+		return response.UsersResponse{}, err
+	}
+	return us.list(ctx, query, filters)
+}
+
+// list returns the page of the users matching filters that query asks for, see GetAll.
+func (us *userService) list(ctx context.Context, query request.UserQuery, filters gocloak.GetUsersParams) (response.UsersResponse, error) {
+	var usersResponse response.UsersResponse
+	sortBy, descending, err := parseSort(query.Sort)
+	if err != nil {
+		return usersResponse, err
+	}
+	if query.First < 0 {
+		return usersResponse, apperror.New(http.StatusBadRequest, "first must not be negative")
 	}
+	if query.Max <= 0 {
+		query.Max = _defaultPageSize
+	}
+	if query.Max > _maxPageSize {
+		query.Max = _maxPageSize
+	}
+
+	total, err := us.keycloakClient.CountUsers(ctx, filters)
+	if err != nil {
+		return usersResponse, err
+	}
+
+	var users []response.UserResponse
+	if sortBy != nil {
+		users, err = us.sortedPage(ctx, filters, total, query, sortBy, descending)
+	} else {
+		users, err = us.page(ctx, filters, query.First, query.Max)
+	}
+	if err != nil {
+		return usersResponse, err
+	}
+
+	usersResponse.Users = users
+	usersResponse.Total = total
+	usersResponse.First = query.First
+	usersResponse.Max = query.Max
+	return usersResponse, nil
+}
+
+// page reads the users matching filters from first on, pageSize at most, in keycloak's order.
+func (us *userService) page(ctx context.Context, filters gocloak.GetUsersParams, first int, pageSize int) ([]response.UserResponse, error) {
+	page := filters
+	page.First = &first
+	page.Max = &pageSize
+	users, err := us.keycloakClient.GetAllUsers(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+	userResponses := []response.UserResponse{}
 	for _, user := range users {
-		userResponse = append(userResponse, response.UserResponse{
-			ID:       *user.ID,
-			Name:     *user.FirstName,
-			LastName: *user.LastName,
-			Email:    *user.Email,
-			NickName: *user.Username,
-		})
+		userResponses = append(userResponses, toUserResponse(user, us.attributes))
 	}
-	return userResponse, nil
+	return userResponses, nil
+}
+
+// sortedPage reads every user matching filters, _maxPageSize at a time, sorts them and returns the page query asks for.
+func (us *userService) sortedPage(ctx context.Context, filters gocloak.GetUsersParams, total int, query request.UserQuery,
+	sortBy func(user response.UserResponse) string, descending bool,
+) ([]response.UserResponse, error) {
+	if total > us.sortMaxUsers {
+		return nil, apperror.New(http.StatusBadRequest, fmt.Sprintf("can't sort more than %d users, narrow the filters", us.sortMaxUsers))
+	}
+	var users []response.UserResponse
+	for first := 0; ; first += _maxPageSize {
+		chunk, err := us.page(ctx, filters, first, _maxPageSize)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, chunk...)
+		if len(chunk) < _maxPageSize {
+			break
+		}
+	}
+	// Case doesn't order users, and the id breaks ties so every page is cut from the same order.
+	sort.Slice(users, func(i, j int) bool {
+		left, right := strings.ToLower(sortBy(users[i])), strings.ToLower(sortBy(users[j]))
+		if left == right {
+			return users[i].ID < users[j].ID
+		}
+		if descending {
+			return right < left
+		}
+		return left < right
+	})
+	start := min(query.First, len(users))
+	return append([]response.UserResponse{}, users[start:min(start+query.Max, len(users))]...), nil
+}
+
+// filters turns filter into the keycloak search it stands for.
+func (us *userService) filters(filter request.UserFilter) (gocloak.GetUsersParams, error) {
+	filters := gocloak.GetUsersParams{
+		Enabled: filter.Enabled,
+	}
+	if filter.Search != "" {
+		filters.Search = &filter.Search
+	}
+	if filter.Email != "" {
+		filters.Email = &filter.Email
+	}
+	if filter.Attributes != "" {
+		q, err := us.attributes.searchQuery(filter.Attributes)
+		if err != nil {
+			return filters, err
+		}
+		filters.Q = &q
+	}
+	return filters, nil
+}
+
+// GetByNickName returns the user, along with the related data named in expand.
+func (us *userService) GetByNickName(ctx context.Context, nickName string, expand []string) (response.UserResponse, error) {
+	return us.getUser(ctx, expand, func() (*gocloak.User, error) {
+		return us.keycloakClient.GetUserByNickName(ctx, nickName)
+	})
 }
 
-func (us *userService) GetByNickName(ctx context.Context, token string, nickName string) (response.UserResponse, error) {
+func (us *userService) GetByID(ctx context.Context, userID string, expand []string) (response.UserResponse, error) {
+	return us.getUser(ctx, expand, func() (*gocloak.User, error) {
+		return us.keycloakClient.GetUserByID(ctx, userID)
+	})
+}
+
+func (us *userService) GetByEmail(ctx context.Context, email string, expand []string) (response.UserResponse, error) {
+	return us.getUser(ctx, expand, func() (*gocloak.User, error) {
+		return us.keycloakClient.GetUserByEmail(ctx, email)
+	})
+}
+
+// getUser checks expand before looking the user up with find, then fills in the expansions.
+func (us *userService) getUser(ctx context.Context, expand []string, find func() (*gocloak.User, error)) (response.UserResponse, error) {
 	var userResponse response.UserResponse
-	user, err := us.keycloakClient.GetUserByNickName(ctx, token, nickName)
+	expansions, err := parseExpand(expand)
 	if err != nil {
 		return userResponse, err
 	}
 
-	return response.UserResponse{
-		ID:       *user.ID,
-		Name:     *user.FirstName,
-		LastName: *user.LastName,
-		Email:    *user.Email,
-		NickName: *user.Username,
-	}, nil
+	user, err := find()
+	if err != nil {
+		return userResponse, err
+	}
+	userResponse = toUserResponse(user, us.attributes)
+	if err := us.expand(ctx, &userResponse, expansions); err != nil {
+		return userResponse, err
+	}
+	return userResponse, nil
 }
 
-func (us *userService) Create(ctx context.Context, token string, userRequest request.UserRequest) error {
-	role, err := us.keycloakClient.GetRoleByID(ctx, token, _roleName)
+// parseExpand checks that every name in expand is a known expansion.
+func parseExpand(expand []string) (map[string]bool, error) {
+	expansions := map[string]bool{}
+	for _, name := range expand {
+		if name != _expandRoles && name != _expandGroups && name != _expandTeams {
+			return nil, apperror.New(http.StatusBadRequest, fmt.Sprintf("can't expand %s", name))
+		}
+		expansions[name] = true
+	}
+	return expansions, nil
+}
+
+// expand fetches the related data named in expansions concurrently, each one into its own field.
+func (us *userService) expand(ctx context.Context, userResponse *response.UserResponse, expansions map[string]bool) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var expandErr error
+	run := func(fetch func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fetch(); err != nil {
+				mu.Lock()
+				if expandErr == nil {
+					expandErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	if expansions[_expandRoles] {
+		run(func() error {
+			roles, err := us.keycloakClient.GetUserRealmRoles(ctx, userResponse.ID)
+			if err != nil {
+				return err
+			}
+			userResponse.Roles = []string{}
+			for _, role := range roles {
+				userResponse.Roles = append(userResponse.Roles, gocloak.PString(role.Name))
+			}
+			return nil
+		})
+	}
+	if expansions[_expandGroups] {
+		run(func() error {
+			groups, err := us.keycloakClient.GetUserGroups(ctx, userResponse.ID)
+			if err != nil {
+				return err
+			}
+			userResponse.Groups = []string{}
+			for _, group := range groups {
+				userResponse.Groups = append(userResponse.Groups, gocloak.PString(group.Path))
+			}
+			return nil
+		})
+	}
+	if expansions[_expandTeams] {
+		run(func() error {
+			teams, err := us.teamClient.GetTeamsByUser(ctx, userResponse.ID)
+			if err != nil {
+				return err
+			}
+			userResponse.Teams = []string{}
+			for _, team := range teams.Teams {
+				userResponse.Teams = append(userResponse.Teams, team.Code)
+			}
+			return nil
+		})
+	}
+	wg.Wait()
+	return expandErr
+}
+
+// Availability reports whether no user has taken the nick name or email in query yet.
+func (us *userService) Availability(ctx context.Context, query request.AvailabilityQuery) (response.AvailabilityResponse, error) {
+	var availability response.AvailabilityResponse
+	if query.NickName == "" && query.Email == "" {
+		return availability, apperror.New(http.StatusBadRequest, "nick_name or email is required")
+	}
+
+	if query.NickName != "" {
+		available, err := us.nickNameAvailable(ctx, query.NickName)
+		if err != nil {
+			return availability, err
+		}
+		availability.NickName = &available
+	}
+	if query.Email != "" {
+		available, err := us.emailAvailable(ctx, query.Email)
+		if err != nil {
+			return availability, err
+		}
+		availability.Email = &available
+	}
+	return availability, nil
+}
+
+func (us *userService) nickNameAvailable(ctx context.Context, nickName string) (bool, error) {
+	_, err := us.keycloakClient.GetUserByNickName(ctx, nickName)
+	return availableAfter(err)
+}
+
+func (us *userService) emailAvailable(ctx context.Context, email string) (bool, error) {
+	_, err := us.keycloakClient.GetUserByEmail(ctx, email)
+	return availableAfter(err)
+}
+
+// availableAfter reads the outcome of looking a user up: only not finding anyone leaves the value
+// available, finding one user or several (a conflict) means it's taken.
+func availableAfter(err error) (bool, error) {
+	if err == nil {
+		return false, nil
+	}
+	switch apperror.Code(err, 0) {
+	case http.StatusNotFound:
+		return true, nil
+	case http.StatusConflict:
+		return false, nil
+	}
+	return false, err
+}
+
+// Create runs as a saga: when granting the roles or sending the set password email fails,
+// the user just created is deleted again instead of being left half set up.
+func (us *userService) Create(ctx context.Context, userRequest request.UserRequest) error {
+	var emailActions []string
+	if userRequest.SendSetPasswordEmail {
+		emailActions = _setPasswordActions
+	}
+	return us.create(ctx, userRequest, nil, emailActions)
+}
+
+// create builds the user in userRequest, lets prepare adjust it when not nil, and creates it along
+// with its roles, then emails it emailActions when there are any.
+func (us *userService) create(ctx context.Context, userRequest request.UserRequest, prepare func(user *gocloak.User), emailActions []string) error {
+	roles, user, err := us.newUser(ctx, userRequest)
 	if err != nil {
 		return err
 	}
-	_, err = us.keycloakClient.CreateUser(ctx, token, role, gocloak.User{
+	if prepare != nil {
+		prepare(&user)
+	}
+
+	var id string
+	create := saga.New(
+		saga.Step{
+			Name: "create_user",
+			Action: func(ctx context.Context) error {
+				id, err = us.keycloakClient.CreateUser(ctx, user)
+				return err
+			},
+			Compensate: func(ctx context.Context) error {
+				return us.keycloakClient.DeleteUserByID(ctx, id)
+			},
+		},
+		saga.Step{
+			Name: "assign_roles",
+			Action: func(ctx context.Context) error {
+				return us.keycloakClient.AddRealmRolesToUser(ctx, id, roles)
+			},
+		},
+	)
+	if len(emailActions) > 0 {
+		create.Add(saga.Step{
+			Name: "send_actions_email",
+			Action: func(ctx context.Context) error {
+				return us.keycloakClient.SendActionsEmail(ctx, id, emailActions)
+			},
+		})
+	}
+	return create.Run(ctx)
+}
+
+// newUser checks userRequest and builds the keycloak user it describes, along with the realm roles it starts with.
+func (us *userService) newUser(ctx context.Context, userRequest request.UserRequest) ([]gocloak.Role, gocloak.User, error) {
+	var user gocloak.User
+	if userRequest.Password != "" && userRequest.SendSetPasswordEmail {
+		return nil, user, apperror.New(http.StatusBadRequest, "send either an initial password or send_set_password_email, not both")
+	}
+	if err := us.attributes.checkAll(userRequest.Attributes); err != nil {
+		return nil, user, err
+	}
+
+	roleNames := userRequest.Roles
+	if len(roleNames) == 0 {
+		roleNames = us.defaultRoles
+	}
+	roles, err := us.keycloakClient.GetRealmRolesByName(ctx, roleNames)
+	if err != nil {
+		return nil, user, err
+	}
+	user = gocloak.User{
 		Username:  &userRequest.NickName,
 		FirstName: &userRequest.Name,
 		LastName:  &userRequest.LastName,
 		Email:     &userRequest.Email,
-	})
-	return err
+		Enabled:   gocloak.BoolP(true),
+	}
+	if len(userRequest.Attributes) > 0 {
+		attributes := map[string][]string{}
+		for key, value := range userRequest.Attributes {
+			attributes[key] = []string{value}
+		}
+		user.Attributes = &attributes
+	}
+	if userRequest.Password != "" {
+		user.Credentials = &[]gocloak.CredentialRepresentation{
+			{
+				Type:      gocloak.StringP("password"),
+				Value:     &userRequest.Password,
+				Temporary: &userRequest.TemporaryPassword,
+			},
+		}
+	}
+	return roles, user, nil
 }
 
-func (us *userService) Delete(ctx context.Context, token string, nickName string) (string, error) {
-	var userName string
-	user, err := us.keycloakClient.GetUserByNickName(ctx, token, nickName)
+// Update replaces (or, when partial, merges) the user's profile. ifMatch is required, and must be
+// the ETag of the user's current representation, otherwise someone else changed it meanwhile; *
+// skips the check. Replacing only replaces the allowed attributes, the ones keycloak or this
+// service keep on their own, like the registration marker, survive.
+func (us *userService) Update(ctx context.Context, nickName string, updateRequest request.UpdateUserRequest, partial bool, ifMatch string) (response.UserResponse, error) {
+	var userResponse response.UserResponse
+	if !partial && (updateRequest.Name == nil || updateRequest.LastName == nil || updateRequest.Email == nil) {
+		return userResponse, apperror.New(http.StatusBadRequest, "name, last_name and email are required to replace a user")
+	}
+	if ifMatch == "" {
+		return userResponse, apperror.New(http.StatusPreconditionRequired, fmt.Sprintf("If-Match is required to update user %s, send the ETag it was read with", nickName))
+	}
+	if updateRequest.Attributes != nil {
+		if err := us.attributes.checkAll(*updateRequest.Attributes); err != nil {
+			return userResponse, err
+		}
+	}
+
+	user, err := us.keycloakClient.GetUserByNickName(ctx, nickName)
 	if err != nil {
-		return userName, err
+		return userResponse, err
+	}
+	if ifMatch != "*" && ifMatch != toUserResponse(user, us.attributes).ETag() {
+		return userResponse, apperror.New(http.StatusPreconditionFailed, fmt.Sprintf("user %s was modified, fetch it again before updating", nickName))
+	}
+
+	if updateRequest.Name != nil {
+		user.FirstName = updateRequest.Name
+	}
+	if updateRequest.LastName != nil {
+		user.LastName = updateRequest.LastName
+	}
+	if updateRequest.Email != nil {
+		if !strings.EqualFold(gocloak.PString(user.Email), *updateRequest.Email) {
+			user.EmailVerified = gocloak.BoolP(false)
+		}
+		user.Email = updateRequest.Email
+	}
+	if updateRequest.Attributes != nil || !partial {
+		attributes := map[string][]string{}
+		if user.Attributes != nil {
+			for key, values := range *user.Attributes {
+				if _, allowed := us.attributes[key]; partial || !allowed {
+					attributes[key] = values
+				}
+			}
+		}
+		if updateRequest.Attributes != nil {
+			for key, value := range *updateRequest.Attributes {
+				attributes[key] = []string{value}
+			}
+		}
+		user.Attributes = &attributes
+	}
+
+	if err := us.keycloakClient.UpdateUser(ctx, *user); err != nil {
+		return userResponse, err
 	}
+	return toUserResponse(user, us.attributes), nil
+}
 
-	teams, err := us.teamClient.GetTeamsByUser(ctx, *user.ID)
+// SetEnabled turns the user's account on or off. Disabling also ends the user's sessions,
+// so tokens already handed out can't be refreshed anymore. Users waiting for approval are only
+// enabled by approving them, which takes them out of the queue too.
+func (us *userService) SetEnabled(ctx context.Context, nickName string, enabled bool) (response.UserResponse, error) {
+	var userResponse response.UserResponse
+	user, err := us.keycloakClient.GetUserByNickName(ctx, nickName)
 	if err != nil {
-		return userName, err
+		return userResponse, err
+	}
+	if enabled && firstAttribute(user, _registrationAttribute) == _registrationPending {
+		return userResponse, apperror.New(http.StatusConflict,
+			fmt.Sprintf("user %s is waiting for approval, approve it with POST /users/registrations/%s/approve", nickName, nickName))
 	}
 
-	if teams.Teams != nil {
-		return userName, errors.New(fmt.Sprintf("user %s has teams", nickName))
+	user.Enabled = &enabled
+	if err := us.keycloakClient.UpdateUser(ctx, *user); err != nil {
+		return userResponse, err
+	}
+	if !enabled {
+		if err := us.keycloakClient.LogoutUserSessions(ctx, *user.ID); err != nil {
+			return userResponse, err
+		}
 	}
+	return toUserResponse(user, us.attributes), nil
+}
 
-	err = us.keycloakClient.DeleteUserByID(ctx, token, *user.ID)
+// ResetPassword sets the password an admin chose for the user and ends the user's sessions,
+// so whoever knew the old password is signed out too.
+func (us *userService) ResetPassword(ctx context.Context, nickName string, passwordRequest request.PasswordRequest) error {
+	user, err := us.keycloakClient.GetUserByNickName(ctx, nickName)
 	if err != nil {
-		return userName, err
+		return err
+	}
+	if err := us.keycloakClient.SetPassword(ctx, *user.ID, passwordRequest.Password, passwordRequest.Temporary); err != nil {
+		return err
+	}
+	return us.keycloakClient.LogoutUserSessions(ctx, *user.ID)
+}
+
+func (us *userService) GetAttribute(ctx context.Context, nickName string, key string) (response.AttributeResponse, error) {
+	var attributeResponse response.AttributeResponse
+	if err := us.attributes.checkKey(key); err != nil {
+		return attributeResponse, err
+	}
+	user, err := us.keycloakClient.GetUserByNickName(ctx, nickName)
+	if err != nil {
+		return attributeResponse, err
+	}
+
+	value, ok := toUserResponse(user, us.attributes).Attributes[key]
+	if !ok {
+		return attributeResponse, apperror.New(http.StatusNotFound, fmt.Sprintf("user %s has no attribute %s", nickName, key))
+	}
+	attributeResponse.Key = key
+	attributeResponse.Value = value
+	return attributeResponse, nil
+}
+
+// SetAttribute adds the attribute to the user or replaces its value, leaving the other attributes alone.
+func (us *userService) SetAttribute(ctx context.Context, nickName string, key string, value string) (response.AttributeResponse, error) {
+	var attributeResponse response.AttributeResponse
+	if err := us.attributes.check(key, value); err != nil {
+		return attributeResponse, err
+	}
+	user, err := us.keycloakClient.GetUserByNickName(ctx, nickName)
+	if err != nil {
+		return attributeResponse, err
+	}
+
+	attributes := map[string][]string{}
+	if user.Attributes != nil {
+		attributes = *user.Attributes
+	}
+	attributes[key] = []string{value}
+	user.Attributes = &attributes
+	if err := us.keycloakClient.UpdateUser(ctx, *user); err != nil {
+		return attributeResponse, err
+	}
+	attributeResponse.Key = key
+	attributeResponse.Value = value
+	return attributeResponse, nil
+}
+
+func (us *userService) DeleteAttribute(ctx context.Context, nickName string, key string) error {
+	if err := us.attributes.checkKey(key); err != nil {
+		return err
+	}
+	user, err := us.keycloakClient.GetUserByNickName(ctx, nickName)
+	if err != nil {
+		return err
+	}
+	if user.Attributes == nil {
+		return apperror.New(http.StatusNotFound, fmt.Sprintf("user %s has no attribute %s", nickName, key))
+	}
+	if _, ok := (*user.Attributes)[key]; !ok {
+		return apperror.New(http.StatusNotFound, fmt.Sprintf("user %s has no attribute %s", nickName, key))
+	}
+
+	delete(*user.Attributes, key)
+	return us.keycloakClient.UpdateUser(ctx, *user)
+}
+
+// Delete offboards the user, see offboard. Deleting the user again after a failure resumes
+// the offboarding from the step that failed, even once the user is already deleted or anonymized.
+func (us *userService) Delete(ctx context.Context, nickName string) (string, error) {
+	offboarding, err := us.startOffboarding(ctx, nickName)
+	if err != nil {
+		return "", err
+	}
+	if err := us.offboard(ctx, offboarding); err != nil {
+		return "", err
+	}
+	return offboarding.NickName, nil
+}
+
+func parseSort(sortParam string) (func(user response.UserResponse) string, bool, error) {
+	if sortParam == "" {
+		return nil, false, nil
+	}
+	field, descending := strings.CutPrefix(sortParam, "-")
+	sortBy, ok := _sortFields[field]
+	if !ok {
+		return nil, false, apperror.New(http.StatusBadRequest, fmt.Sprintf("can't sort users by %s", field))
+	}
+	return sortBy, descending, nil
+}
+
+func toUserResponse(user *gocloak.User, attributes attributeSchema) response.UserResponse {
+	return response.NewUserResponse(user, attributes.allows)
+}
+
+// firstAttribute returns the first value of the user's key attribute, allowed or not.
+func firstAttribute(user *gocloak.User, key string) string {
+	if user.Attributes == nil || len((*user.Attributes)[key]) == 0 {
+		return ""
 	}
-	userName = *user.Username
-	return userName, nil
+	return (*user.Attributes)[key][0]
 }
@@ -5,9 +5,15 @@ import (
 	"cow_sso/api/handlers/user/request"
 	"cow_sso/api/handlers/user/response"
 	"cow_sso/mocks"
+	"cow_sso/pkg/apperror"
+	offboardingDto "cow_sso/pkg/integration/offboarding/dto"
 	"cow_sso/pkg/integration/team/dto"
+	"cow_sso/pkg/saga"
 	"errors"
+	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/Nerzal/gocloak/v13"
 	"github.com/stretchr/testify/assert"
@@ -15,8 +21,9 @@ import (
 )
 
 type mockUserService struct {
-	keycloakClient *mocks.IKeycloakClient
-	teamClient     *mocks.ITeamClient
+	keycloakClient        *mocks.IKeycloakClient
+	teamClient            *mocks.ITeamClient
+	offboardingRepository *mocks.IOffboardingRepository
 }
 
 type userMocks struct {
@@ -24,65 +31,246 @@ type userMocks struct {
 }
 
 func Test_GetAll(t *testing.T) {
+	// chunk is a full first read of a sorted list, so sorting has to read a second one.
+	chunk := make([]*gocloak.User, 0, 100)
+	for i := 1; i <= 100; i++ {
+		chunk = append(chunk, &gocloak.User{Username: gocloak.StringP(fmt.Sprintf("user%03d", i))})
+	}
+	readSorted := func(first int) gocloak.GetUsersParams {
+		return gocloak.GetUsersParams{First: gocloak.IntP(first), Max: gocloak.IntP(100)}
+	}
+
 	tests := []struct {
-		expErr error
-		mocks  userMocks
-		name   string
-		outPut []response.UserResponse
+		expErr       error
+		mocks        userMocks
+		name         string
+		query        request.UserQuery
+		outPut       response.UsersResponse
+		sortMaxUsers int
 	}{
+		{
+			name: "invalid sort",
+			query: request.UserQuery{
+				Sort: "password",
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "can't sort users by password"),
+		},
+		{
+			name: "negative first",
+			query: request.UserQuery{
+				First: -1,
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "first must not be negative"),
+		},
+		{
+			name: "attribute filter not allowed",
+			query: request.UserQuery{
+				UserFilter: request.UserFilter{Attributes: "password:secret"},
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "attribute password isn't allowed"),
+		},
+		{
+			name: "attribute filter",
+			query: request.UserQuery{
+				UserFilter: request.UserFilter{Attributes: "department:sales  locale:es"},
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("CountUsers", mock.Anything, gocloak.GetUsersParams{
+						Q: gocloak.StringP("department:sales locale:es"),
+					}).Return(0, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "error count users",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("CountUsers", mock.Anything, gocloak.GetUsersParams{}).Return(0, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
 		{
 			name: "error get users",
 			mocks: userMocks{
 				userService: func(f *mockUserService) {
-					f.keycloakClient.Mock.On("GetAllUsers", mock.Anything, "ABC").Return([]*gocloak.User{}, errors.New("some error"))
+					first := 0
+					pageSize := 20
+					f.keycloakClient.Mock.On("CountUsers", mock.Anything, gocloak.GetUsersParams{}).Return(1, nil)
+					f.keycloakClient.Mock.On("GetAllUsers", mock.Anything, gocloak.GetUsersParams{
+						First: &first,
+						Max:   &pageSize,
+					}).Return([]*gocloak.User{}, errors.New("some error"))
 				},
 			},
 			expErr: errors.New("some error"),
 		},
 		{
 			name: "full flow",
+			query: request.UserQuery{
+				UserFilter: request.UserFilter{
+					Search: "diego",
+					Email:  "gmail.com",
+				},
+				First: 10,
+				Max:   500,
+			},
 			mocks: userMocks{
 				userService: func(f *mockUserService) {
-					id := "abcde8"
-					firstName := "diego"
-					lastName := "fernandez"
-					email := "diego@gmail.com"
-					userName := "diegof"
-					f.keycloakClient.Mock.On("GetAllUsers", mock.Anything, "ABC").Return([]*gocloak.User{
+					search := "diego"
+					email := "gmail.com"
+					first := 10
+					pageSize := 100
+					f.keycloakClient.Mock.On("CountUsers", mock.Anything, gocloak.GetUsersParams{
+						Search: &search,
+						Email:  &email,
+					}).Return(12, nil)
+					f.keycloakClient.Mock.On("GetAllUsers", mock.Anything, gocloak.GetUsersParams{
+						Search: &search,
+						Email:  &email,
+						First:  &first,
+						Max:    &pageSize,
+					}).Return([]*gocloak.User{
+						{
+							ID:        gocloak.StringP("abcde7"),
+							FirstName: gocloak.StringP("diego"),
+							LastName:  gocloak.StringP("alvarez"),
+							Email:     gocloak.StringP("diegoa@gmail.com"),
+							Username:  gocloak.StringP("diegoa"),
+						},
 						{
-							ID:        &id,
-							FirstName: &firstName,
-							LastName:  &lastName,
-							Email:     &email,
-							Username:  &userName,
+							ID:        gocloak.StringP("abcde8"),
+							FirstName: gocloak.StringP("diego"),
+							LastName:  gocloak.StringP("fernandez"),
+							Email:     gocloak.StringP("diego@gmail.com"),
+							Username:  gocloak.StringP("diegof"),
 						},
 					}, nil)
 				},
 			},
-			outPut: []response.UserResponse{
-				{
-					ID:       "abcde8",
-					Name:     "diego",
-					LastName: "fernandez",
-					Email:    "diego@gmail.com",
-					NickName: "diegof",
+			outPut: response.UsersResponse{
+				Users: []response.UserResponse{
+					{
+						ID:       "abcde7",
+						Name:     "diego",
+						LastName: "alvarez",
+						Email:    "diegoa@gmail.com",
+						NickName: "diegoa",
+					},
+					{
+						ID:       "abcde8",
+						Name:     "diego",
+						LastName: "fernandez",
+						Email:    "diego@gmail.com",
+						NickName: "diegof",
+					},
+				},
+				Total: 12,
+				First: 10,
+				Max:   100,
+			},
+		},
+		{
+			name:         "too many users to sort",
+			query:        request.UserQuery{Sort: "nick_name"},
+			sortMaxUsers: 100,
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("CountUsers", mock.Anything, gocloak.GetUsersParams{}).Return(101, nil)
+				},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "can't sort more than 100 users, narrow the filters"),
+		},
+		{
+			name:  "sort across keycloak pages",
+			query: request.UserQuery{First: 1, Max: 2, Sort: "-nick_name"},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("CountUsers", mock.Anything, gocloak.GetUsersParams{}).Return(101, nil)
+					f.keycloakClient.Mock.On("GetAllUsers", mock.Anything, readSorted(0)).Return(chunk, nil)
+					f.keycloakClient.Mock.On("GetAllUsers", mock.Anything, readSorted(100)).Return([]*gocloak.User{
+						{Username: gocloak.StringP("zoe")},
+					}, nil)
+				},
+			},
+			outPut: response.UsersResponse{
+				Users: []response.UserResponse{{NickName: "user100"}, {NickName: "user099"}},
+				Total: 101,
+				First: 1,
+				Max:   2,
+			},
+		},
+		{
+			name:  "sort ignores case and breaks ties by id",
+			query: request.UserQuery{Sort: "name"},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("CountUsers", mock.Anything, gocloak.GetUsersParams{}).Return(4, nil)
+					f.keycloakClient.Mock.On("GetAllUsers", mock.Anything, readSorted(0)).Return([]*gocloak.User{
+						{ID: gocloak.StringP("4"), FirstName: gocloak.StringP("Zoe")},
+						{ID: gocloak.StringP("3"), FirstName: gocloak.StringP("diego")},
+						{ID: gocloak.StringP("2"), FirstName: gocloak.StringP("ana")},
+						{ID: gocloak.StringP("1"), FirstName: gocloak.StringP("Diego")},
+					}, nil)
+				},
+			},
+			outPut: response.UsersResponse{
+				Users: []response.UserResponse{
+					{ID: "2", Name: "ana"},
+					{ID: "1", Name: "Diego"},
+					{ID: "3", Name: "diego"},
+					{ID: "4", Name: "Zoe"},
+				},
+				Total: 4,
+				Max:   20,
+			},
+		},
+		{
+			name:  "sorted page past the end",
+			query: request.UserQuery{First: 5, Sort: "nick_name"},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("CountUsers", mock.Anything, gocloak.GetUsersParams{}).Return(1, nil)
+					f.keycloakClient.Mock.On("GetAllUsers", mock.Anything, readSorted(0)).Return([]*gocloak.User{
+						{Username: gocloak.StringP("diegof")},
+					}, nil)
 				},
 			},
+			outPut: response.UsersResponse{
+				Users: []response.UserResponse{},
+				Total: 1,
+				First: 5,
+				Max:   20,
+			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := &mockUserService{
-				keycloakClient: &mocks.IKeycloakClient{},
-				teamClient:     &mocks.ITeamClient{},
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
 			}
 			tt.mocks.userService(m)
-			service := NewUserService(m.keycloakClient, m.teamClient)
-			users, err := service.GetAll(context.Background(), mock.Anything)
-			if err != nil {
-				assert.Equal(t, tt.expErr, err)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			if tt.sortMaxUsers != 0 {
+				service.(*userService).sortMaxUsers = tt.sortMaxUsers
 			}
+			users, err := service.GetAll(context.Background(), tt.query)
+			assert.Equal(t, tt.expErr, err)
 			assert.Equal(t, tt.outPut, users)
+			m.keycloakClient.AssertExpectations(t)
 		})
 	}
 }
@@ -94,14 +282,24 @@ func Test_GetByNickName(t *testing.T) {
 		outPut   response.UserResponse
 		name     string
 		nickName string
+		expand   []string
 	}{
+		{
+			name:     "unknown expansion",
+			nickName: "diegof",
+			expand:   []string{"password"},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "can't expand password"),
+		},
 		{
 			name:     "error GetUserByNickName",
 			nickName: "diegof",
 			mocks: userMocks{
 				userService: func(f *mockUserService) {
 					user := gocloak.User{}
-					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "ABC", "diegof").Return(&user, errors.New("some error"))
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(&user, errors.New("some error"))
 				},
 			},
 			expErr: errors.New("some error"),
@@ -123,7 +321,7 @@ func Test_GetByNickName(t *testing.T) {
 						Email:     &email,
 						Username:  &userName,
 					}
-					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "ABC", "diegof").Return(&user, nil)
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(&user, nil)
 				},
 			},
 			outPut: response.UserResponse{
@@ -134,236 +332,1331 @@ func Test_GetByNickName(t *testing.T) {
 				NickName: "diegof",
 			},
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			m := &mockUserService{
-				keycloakClient: &mocks.IKeycloakClient{},
-				teamClient:     &mocks.ITeamClient{},
-			}
-			tt.mocks.userService(m)
-			service := NewUserService(m.keycloakClient, m.teamClient)
-			users, err := service.GetByNickName(context.Background(), mock.Anything, tt.nickName)
-			if err != nil {
-				assert.Equal(t, tt.expErr, err)
-			}
-			assert.Equal(t, tt.outPut, users)
-		})
-	}
-}
-
-func Test_Create(t *testing.T) {
-	tests := []struct {
-		expErr      error
-		mocks       userMocks
-		userRequest request.UserRequest
-		name        string
-	}{
 		{
-			name: "error GetRoleByID",
-			userRequest: request.UserRequest{
-				Name:     "diego",
-				LastName: "fernandez",
-				Email:    "diegof@gmail.com",
+			name:     "user with missing fields",
+			nickName: "diegof",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(&gocloak.User{
+						ID:       gocloak.StringP("abcde8"),
+						Username: gocloak.StringP("diegof"),
+					}, nil)
+				},
+			},
+			outPut: response.UserResponse{
+				ID:       "abcde8",
 				NickName: "diegof",
 			},
+		},
+		{
+			name:     "account metadata",
+			nickName: "diegof",
 			mocks: userMocks{
 				userService: func(f *mockUserService) {
-					role := gocloak.Role{}
-					f.keycloakClient.Mock.On("GetRoleByID", mock.Anything, "ABC", "user").Return(&role, errors.New("some error"))
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(&gocloak.User{
+						ID:               gocloak.StringP("abcde8"),
+						Username:         gocloak.StringP("diegof"),
+						Enabled:          gocloak.BoolP(true),
+						EmailVerified:    gocloak.BoolP(true),
+						CreatedTimestamp: gocloak.Int64P(1700000000000),
+						Attributes: &map[string][]string{
+							"locale": {"es", "en"},
+							"empty":  {},
+						},
+					}, nil)
+				},
+			},
+			outPut: response.UserResponse{
+				ID:            "abcde8",
+				NickName:      "diegof",
+				Enabled:       true,
+				EmailVerified: true,
+				CreatedAt:     func() *time.Time { t := time.UnixMilli(1700000000000).UTC(); return &t }(),
+				Attributes: map[string]string{
+					"locale": "es",
 				},
 			},
-			expErr: errors.New("some error"),
 		},
 		{
-			name: "error CreateUser",
-			userRequest: request.UserRequest{
-				Name:     "diego",
-				LastName: "fernandez",
-				Email:    "diegof@gmail.com",
+			name:     "expand roles, groups and teams",
+			nickName: "diegof",
+			expand:   []string{"roles", "groups", "teams"},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(&gocloak.User{
+						ID:       gocloak.StringP("abcde8"),
+						Username: gocloak.StringP("diegof"),
+					}, nil)
+					f.keycloakClient.Mock.On("GetUserRealmRoles", mock.Anything, "abcde8").Return([]*gocloak.Role{
+						{Name: gocloak.StringP("user")},
+						{Name: gocloak.StringP("admin")},
+					}, nil)
+					f.keycloakClient.Mock.On("GetUserGroups", mock.Anything, "abcde8").Return([]*gocloak.Group{}, nil)
+					f.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "abcde8").Return(dto.TeamsByUserResponse{
+						Teams: []dto.TeamResponse{{Code: "t1"}, {Code: "t2", Debt: 10}},
+					}, nil)
+				},
+			},
+			outPut: response.UserResponse{
+				ID:       "abcde8",
 				NickName: "diegof",
+				Roles:    []string{"user", "admin"},
+				Groups:   []string{},
+				Teams:    []string{"t1", "t2"},
 			},
+		},
+		{
+			name:     "error expanding teams",
+			nickName: "diegof",
+			expand:   []string{"roles", "teams"},
 			mocks: userMocks{
 				userService: func(f *mockUserService) {
-					role := gocloak.Role{}
-					f.keycloakClient.Mock.On("GetRoleByID", mock.Anything, "ABC", "user").Return(&role, nil)
-					firstName := "diego"
-					lastName := "fernandez"
-					email := "diegof@gmail.com"
-					userName := "diegof"
-					f.keycloakClient.Mock.On("CreateUser", mock.Anything, "ABC", &role, gocloak.User{
-						FirstName: &firstName,
-						LastName:  &lastName,
-						Email:     &email,
-						Username:  &userName,
-					}).Return("", errors.New("some error"))
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(&gocloak.User{
+						ID:       gocloak.StringP("abcde8"),
+						Username: gocloak.StringP("diegof"),
+					}, nil)
+					f.keycloakClient.Mock.On("GetUserRealmRoles", mock.Anything, "abcde8").Return([]*gocloak.Role{}, nil)
+					f.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "abcde8").Return(dto.TeamsByUserResponse{}, errors.New("some error"))
 				},
 			},
 			expErr: errors.New("some error"),
+			outPut: response.UserResponse{
+				ID:       "abcde8",
+				NickName: "diegof",
+				Roles:    []string{},
+			},
 		},
 		{
-			name: "full flow",
-			userRequest: request.UserRequest{
+			name:     "error GetUserGroups",
+			nickName: "diegof",
+			expand:   []string{"groups"},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					user := gocloak.User{
+						ID:        gocloak.StringP("abcde8"),
+						FirstName: gocloak.StringP("diego"),
+						LastName:  gocloak.StringP("fernandez"),
+						Email:     gocloak.StringP("diego@gmail.com"),
+						Username:  gocloak.StringP("diegof"),
+					}
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(&user, nil)
+					f.keycloakClient.Mock.On("GetUserGroups", mock.Anything, "abcde8").Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+			outPut: response.UserResponse{
+				ID:       "abcde8",
 				Name:     "diego",
 				LastName: "fernandez",
-				Email:    "diegof@gmail.com",
+				Email:    "diego@gmail.com",
 				NickName: "diegof",
 			},
+		},
+		{
+			name:     "expand groups",
+			nickName: "diegof",
+			expand:   []string{"groups"},
 			mocks: userMocks{
 				userService: func(f *mockUserService) {
-					role := gocloak.Role{}
-					f.keycloakClient.Mock.On("GetRoleByID", mock.Anything, "ABC", "user").Return(&role, nil)
-					firstName := "diego"
-					lastName := "fernandez"
-					email := "diegof@gmail.com"
-					userName := "diegof"
-					f.keycloakClient.Mock.On("CreateUser", mock.Anything, "ABC", &role, gocloak.User{
-						FirstName: &firstName,
-						LastName:  &lastName,
-						Email:     &email,
-						Username:  &userName,
-					}).Return("123", nil)
+					user := gocloak.User{
+						ID:        gocloak.StringP("abcde8"),
+						FirstName: gocloak.StringP("diego"),
+						LastName:  gocloak.StringP("fernandez"),
+						Email:     gocloak.StringP("diego@gmail.com"),
+						Username:  gocloak.StringP("diegof"),
+					}
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(&user, nil)
+					f.keycloakClient.Mock.On("GetUserGroups", mock.Anything, "abcde8").Return([]*gocloak.Group{
+						{ID: gocloak.StringP("g1"), Name: gocloak.StringP("sales"), Path: gocloak.StringP("/sales")},
+						{ID: gocloak.StringP("g2"), Name: gocloak.StringP("north"), Path: gocloak.StringP("/sales/north")},
+					}, nil)
 				},
 			},
+			outPut: response.UserResponse{
+				ID:       "abcde8",
+				Name:     "diego",
+				LastName: "fernandez",
+				Email:    "diego@gmail.com",
+				NickName: "diegof",
+				Groups:   []string{"/sales", "/sales/north"},
+			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := &mockUserService{
-				keycloakClient: &mocks.IKeycloakClient{},
-				teamClient:     &mocks.ITeamClient{},
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
 			}
 			tt.mocks.userService(m)
-			service := NewUserService(m.keycloakClient, m.teamClient)
-			err := service.Create(context.Background(), mock.Anything, tt.userRequest)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			users, err := service.GetByNickName(context.Background(), tt.nickName, tt.expand)
 			if err != nil {
 				assert.Equal(t, tt.expErr, err)
 			}
+			assert.Equal(t, tt.outPut, users)
 		})
 	}
 }
 
-func Test_Delete(t *testing.T) {
+func Test_GetByIDAndEmail(t *testing.T) {
+	user := func() *gocloak.User {
+		return &gocloak.User{
+			ID:        gocloak.StringP("abcde8"),
+			FirstName: gocloak.StringP("diego"),
+			LastName:  gocloak.StringP("fernandez"),
+			Email:     gocloak.StringP("diego@gmail.com"),
+			Username:  gocloak.StringP("diegof"),
+		}
+	}
+	diegof := response.UserResponse{
+		ID:       "abcde8",
+		Name:     "diego",
+		LastName: "fernandez",
+		Email:    "diego@gmail.com",
+		NickName: "diegof",
+	}
 	tests := []struct {
-		expErr   error
-		mocks    userMocks
-		name     string
-		token    string
-		nickName string
-		outPut   string
+		expErr  error
+		mocks   userMocks
+		outPut  response.UserResponse
+		name    string
+		value   string
+		byEmail bool
 	}{
 		{
-			name:     "error GetUserByID",
-			nickName: "1234",
-			token:    "ABC",
-			mocks: userMocks{
-				func(f *mockUserService) {
-					x := "diego"
-					id := "AXYZT"
-					user := gocloak.User{
-						ID:       &id,
-						Username: &x,
-					}
-					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "ABC", "1234").Return(&user, errors.New("some error"))
-				},
-			},
-			expErr: errors.New("some error"),
-		},
-		{
-			name:     "error user with teams unmarshal",
-			nickName: "1234",
-			token:    "ABC",
+			name:  "id not found",
+			value: "abcde8",
 			mocks: userMocks{
-				func(f *mockUserService) {
-					x := "diego"
-					id := "AXYZT"
-					user := gocloak.User{
-						ID:       &id,
-						Username: &x,
-					}
-					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "ABC", "1234").Return(&user, nil)
-					f.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "AXYZT").Return(dto.TeamsByUserResponse{}, errors.New("some error"))
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByID", mock.Anything, "abcde8").Return(nil, apperror.New(http.StatusNotFound, "user abcde8 doesn't exist"))
 				},
 			},
-			expErr: errors.New("some error"),
+			expErr: apperror.New(http.StatusNotFound, "user abcde8 doesn't exist"),
 		},
 		{
-			name:     "error user with teams",
-			token:    "ABC",
-			nickName: "1234",
+			name:  "by id",
+			value: "abcde8",
 			mocks: userMocks{
-				func(f *mockUserService) {
-					x := "diego"
-					id := "AXYZT"
-					user := gocloak.User{
-						ID:       &id,
-						Username: &x,
-					}
-					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "ABC", "1234").Return(&user, nil)
-					f.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "AXYZT").Return(dto.TeamsByUserResponse{
-						Teams: []dto.TeamResponse{
-							{
-								Code: "xs",
-								Debt: 0,
-							},
-						},
-					}, nil)
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByID", mock.Anything, "abcde8").Return(user(), nil)
 				},
 			},
-			expErr: errors.New("user 1234 has teams"),
+			outPut: diegof,
 		},
 		{
-			name:     "error DeleteUserByID",
-			token:    "ABC",
-			nickName: "1234",
+			name:    "ambiguous email",
+			value:   "diego@gmail.com",
+			byEmail: true,
 			mocks: userMocks{
-				func(f *mockUserService) {
-					x := "diego"
-					id := "AXYZT"
-					user := gocloak.User{
-						ID:       &id,
-						Username: &x,
-					}
-					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "ABC", "1234").Return(&user, nil)
-					f.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "AXYZT").Return(dto.TeamsByUserResponse{}, nil)
-					f.keycloakClient.Mock.On("DeleteUserByID", mock.Anything, "ABC", "AXYZT").Return(errors.New("some error"))
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByEmail", mock.Anything, "diego@gmail.com").Return(nil, apperror.New(http.StatusConflict, "2 users match email diego@gmail.com"))
 				},
 			},
-			expErr: errors.New("some error"),
+			expErr: apperror.New(http.StatusConflict, "2 users match email diego@gmail.com"),
 		},
 		{
-			name:     "full flow",
-			token:    "ABC",
-			nickName: "1234",
+			name:    "by email",
+			value:   "diego@gmail.com",
+			byEmail: true,
 			mocks: userMocks{
-				func(f *mockUserService) {
-					x := "diego"
-					id := "AXYZT"
-					user := gocloak.User{
-						ID:       &id,
-						Username: &x,
-					}
-					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "ABC", "1234").Return(&user, nil)
-					f.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "AXYZT").Return(dto.TeamsByUserResponse{}, nil)
-					f.keycloakClient.Mock.On("DeleteUserByID", mock.Anything, "ABC", "AXYZT").Return(nil)
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByEmail", mock.Anything, "diego@gmail.com").Return(user(), nil)
 				},
 			},
-			outPut: "diego",
+			outPut: diegof,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := &mockUserService{
-				keycloakClient: &mocks.IKeycloakClient{},
-				teamClient:     &mocks.ITeamClient{},
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
 			}
 			tt.mocks.userService(m)
-			service := NewUserService(m.keycloakClient, m.teamClient)
-			users, err := service.Delete(context.Background(), tt.token, tt.nickName)
-			if err != nil {
-				assert.Equal(t, tt.expErr, err)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			var userResponse response.UserResponse
+			var err error
+			if tt.byEmail {
+				userResponse, err = service.GetByEmail(context.Background(), tt.value, nil)
+			} else {
+				userResponse, err = service.GetByID(context.Background(), tt.value, nil)
 			}
-			assert.Equal(t, tt.outPut, users)
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, userResponse)
+		})
+	}
+}
+
+func Test_Availability(t *testing.T) {
+	tests := []struct {
+		expErr error
+		mocks  userMocks
+		query  request.AvailabilityQuery
+		name   string
+		outPut response.AvailabilityResponse
+	}{
+		{
+			name: "nothing to check",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "nick_name or email is required"),
+		},
+		{
+			name:  "error checking nick name",
+			query: request.AvailabilityQuery{NickName: "diegof"},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name:  "nick name taken, email free",
+			query: request.AvailabilityQuery{NickName: "diegof", Email: "diego@gmail.com"},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(&gocloak.User{Username: gocloak.StringP("diegof")}, nil)
+					f.keycloakClient.Mock.On("GetUserByEmail", mock.Anything, "diego@gmail.com").Return(nil, apperror.New(http.StatusNotFound, "user with email diego@gmail.com doesn't exist"))
+				},
+			},
+			outPut: response.AvailabilityResponse{
+				NickName: gocloak.BoolP(false),
+				Email:    gocloak.BoolP(true),
+			},
+		},
+		{
+			name:  "email matching several users",
+			query: request.AvailabilityQuery{Email: "diego@gmail.com"},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByEmail", mock.Anything, "diego@gmail.com").Return(nil, apperror.New(http.StatusConflict, "2 users match email diego@gmail.com"))
+				},
+			},
+			outPut: response.AvailabilityResponse{
+				Email: gocloak.BoolP(false),
+			},
+		},
+		{
+			name:  "only email",
+			query: request.AvailabilityQuery{Email: "diego@gmail.com"},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByEmail", mock.Anything, "diego@gmail.com").Return(&gocloak.User{Email: gocloak.StringP("diego@gmail.com")}, nil)
+				},
+			},
+			outPut: response.AvailabilityResponse{
+				Email: gocloak.BoolP(false),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockUserService{
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
+			}
+			tt.mocks.userService(m)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			availability, err := service.Availability(context.Background(), tt.query)
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, availability)
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Create(t *testing.T) {
+	tests := []struct {
+		expErr      error
+		mocks       userMocks
+		userRequest request.UserRequest
+		name        string
+	}{
+		{
+			name: "invalid attribute",
+			userRequest: request.UserRequest{
+				Name:       "diego",
+				LastName:   "fernandez",
+				Email:      "diegof@gmail.com",
+				NickName:   "diegof",
+				Attributes: map[string]string{"employee-id": "abc"},
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {},
+			},
+			expErr: apperror.New(http.StatusUnprocessableEntity, "attribute employee-id must be a number"),
+		},
+		{
+			name: "attributes",
+			userRequest: request.UserRequest{
+				Name:       "diego",
+				LastName:   "fernandez",
+				Email:      "diegof@gmail.com",
+				NickName:   "diegof",
+				Attributes: map[string]string{"phone": "+5491122334455"},
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					roles := []gocloak.Role{{Name: gocloak.StringP("user")}}
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"user"}).Return(roles, nil)
+					f.keycloakClient.Mock.On("CreateUser", mock.Anything, mock.MatchedBy(func(user gocloak.User) bool {
+						return user.Attributes != nil && (*user.Attributes)["phone"][0] == "+5491122334455"
+					})).Return("123", nil)
+					f.keycloakClient.Mock.On("AddRealmRolesToUser", mock.Anything, "123", roles).Return(nil)
+				},
+			},
+		},
+		{
+			name: "error GetRealmRolesByName",
+			userRequest: request.UserRequest{
+				Name:     "diego",
+				LastName: "fernandez",
+				Email:    "diegof@gmail.com",
+				NickName: "diegof",
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"user"}).Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "error CreateUser",
+			userRequest: request.UserRequest{
+				Name:     "diego",
+				LastName: "fernandez",
+				Email:    "diegof@gmail.com",
+				NickName: "diegof",
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					roles := []gocloak.Role{{Name: gocloak.StringP("user")}}
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"user"}).Return(roles, nil)
+					firstName := "diego"
+					lastName := "fernandez"
+					email := "diegof@gmail.com"
+					userName := "diegof"
+					f.keycloakClient.Mock.On("CreateUser", mock.Anything, gocloak.User{
+						FirstName: &firstName,
+						LastName:  &lastName,
+						Email:     &email,
+						Username:  &userName,
+						Enabled:   gocloak.BoolP(true),
+					}).Return("", errors.New("some error"))
+				},
+			},
+			expErr: &saga.Error{Step: "create_user", Err: errors.New("some error")},
+		},
+		{
+			name: "error AddRealmRolesToUser rolls the user back",
+			userRequest: request.UserRequest{
+				Name:     "diego",
+				LastName: "fernandez",
+				Email:    "diegof@gmail.com",
+				NickName: "diegof",
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					roles := []gocloak.Role{{Name: gocloak.StringP("user")}}
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"user"}).Return(roles, nil)
+					f.keycloakClient.Mock.On("CreateUser", mock.Anything, mock.Anything).Return("123", nil)
+					f.keycloakClient.Mock.On("AddRealmRolesToUser", mock.Anything, "123", roles).Return(errors.New("some error"))
+					f.keycloakClient.Mock.On("DeleteUserByID", mock.Anything, "123").Return(nil)
+				},
+			},
+			expErr: &saga.Error{Step: "assign_roles", Err: errors.New("some error")},
+		},
+		{
+			name: "error rolling the user back",
+			userRequest: request.UserRequest{
+				Name:     "diego",
+				LastName: "fernandez",
+				Email:    "diegof@gmail.com",
+				NickName: "diegof",
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					roles := []gocloak.Role{{Name: gocloak.StringP("user")}}
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"user"}).Return(roles, nil)
+					f.keycloakClient.Mock.On("CreateUser", mock.Anything, mock.Anything).Return("123", nil)
+					f.keycloakClient.Mock.On("AddRealmRolesToUser", mock.Anything, "123", roles).Return(errors.New("some error"))
+					f.keycloakClient.Mock.On("DeleteUserByID", mock.Anything, "123").Return(errors.New("timeout"))
+				},
+			},
+			expErr: &saga.Error{
+				Step:          "assign_roles",
+				Err:           errors.New("some error"),
+				Compensations: []error{fmt.Errorf("undoing create_user: %w", errors.New("timeout"))},
+			},
+		},
+		{
+			name: "full flow",
+			userRequest: request.UserRequest{
+				Name:     "diego",
+				LastName: "fernandez",
+				Email:    "diegof@gmail.com",
+				NickName: "diegof",
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					roles := []gocloak.Role{{Name: gocloak.StringP("user")}}
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"user"}).Return(roles, nil)
+					firstName := "diego"
+					lastName := "fernandez"
+					email := "diegof@gmail.com"
+					userName := "diegof"
+					f.keycloakClient.Mock.On("CreateUser", mock.Anything, gocloak.User{
+						FirstName: &firstName,
+						LastName:  &lastName,
+						Email:     &email,
+						Username:  &userName,
+						Enabled:   gocloak.BoolP(true),
+					}).Return("123", nil)
+					f.keycloakClient.Mock.On("AddRealmRolesToUser", mock.Anything, "123", roles).Return(nil)
+				},
+			},
+		},
+
+		{
+			name: "password and set password email",
+			userRequest: request.UserRequest{
+				NickName:             "diegof",
+				Password:             "secret",
+				SendSetPasswordEmail: true,
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "send either an initial password or send_set_password_email, not both"),
+		},
+		{
+			name: "initial temporary password",
+			userRequest: request.UserRequest{
+				Name:              "diego",
+				LastName:          "fernandez",
+				Email:             "diegof@gmail.com",
+				NickName:          "diegof",
+				Password:          "secret",
+				TemporaryPassword: true,
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					roles := []gocloak.Role{{Name: gocloak.StringP("user")}}
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"user"}).Return(roles, nil)
+					f.keycloakClient.Mock.On("CreateUser", mock.Anything, gocloak.User{
+						FirstName: gocloak.StringP("diego"),
+						LastName:  gocloak.StringP("fernandez"),
+						Email:     gocloak.StringP("diegof@gmail.com"),
+						Username:  gocloak.StringP("diegof"),
+						Enabled:   gocloak.BoolP(true),
+						Credentials: &[]gocloak.CredentialRepresentation{
+							{
+								Type:      gocloak.StringP("password"),
+								Value:     gocloak.StringP("secret"),
+								Temporary: gocloak.BoolP(true),
+							},
+						},
+					}).Return("123", nil)
+					f.keycloakClient.Mock.On("AddRealmRolesToUser", mock.Anything, "123", roles).Return(nil)
+				},
+			},
+		},
+		{
+			name: "requested roles",
+			userRequest: request.UserRequest{
+				Name:     "diego",
+				LastName: "fernandez",
+				Email:    "diegof@gmail.com",
+				NickName: "diegof",
+				Roles:    []string{"admin", "auditor"},
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					roles := []gocloak.Role{{Name: gocloak.StringP("admin")}, {Name: gocloak.StringP("auditor")}}
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"admin", "auditor"}).Return(roles, nil)
+					f.keycloakClient.Mock.On("CreateUser", mock.Anything, mock.Anything).Return("123", nil)
+					f.keycloakClient.Mock.On("AddRealmRolesToUser", mock.Anything, "123", roles).Return(nil)
+				},
+			},
+		},
+		{
+			name: "error sending set password email",
+			userRequest: request.UserRequest{
+				Name:                 "diego",
+				LastName:             "fernandez",
+				Email:                "diegof@gmail.com",
+				NickName:             "diegof",
+				SendSetPasswordEmail: true,
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					roles := []gocloak.Role{{Name: gocloak.StringP("user")}}
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"user"}).Return(roles, nil)
+					f.keycloakClient.Mock.On("CreateUser", mock.Anything, mock.Anything).Return("123", nil)
+					f.keycloakClient.Mock.On("AddRealmRolesToUser", mock.Anything, "123", roles).Return(nil)
+					f.keycloakClient.Mock.On("SendActionsEmail", mock.Anything, "123", []string{"UPDATE_PASSWORD", "VERIFY_EMAIL"}).Return(errors.New("some error"))
+					f.keycloakClient.Mock.On("DeleteUserByID", mock.Anything, "123").Return(nil)
+				},
+			},
+			expErr: &saga.Error{Step: "send_actions_email", Err: errors.New("some error")},
+		},
+		{
+			name: "set password email",
+			userRequest: request.UserRequest{
+				Name:                 "diego",
+				LastName:             "fernandez",
+				Email:                "diegof@gmail.com",
+				NickName:             "diegof",
+				SendSetPasswordEmail: true,
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					roles := []gocloak.Role{{Name: gocloak.StringP("user")}}
+					f.keycloakClient.Mock.On("GetRealmRolesByName", mock.Anything, []string{"user"}).Return(roles, nil)
+					f.keycloakClient.Mock.On("CreateUser", mock.Anything, mock.Anything).Return("123", nil)
+					f.keycloakClient.Mock.On("AddRealmRolesToUser", mock.Anything, "123", roles).Return(nil)
+					f.keycloakClient.Mock.On("SendActionsEmail", mock.Anything, "123", []string{"UPDATE_PASSWORD", "VERIFY_EMAIL"}).Return(nil)
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockUserService{
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
+			}
+			tt.mocks.userService(m)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			err := service.Create(context.Background(), tt.userRequest)
+			assert.Equal(t, tt.expErr, err)
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Update(t *testing.T) {
+	current := func() *gocloak.User {
+		return &gocloak.User{
+			ID:        gocloak.StringP("abcde8"),
+			FirstName: gocloak.StringP("diego"),
+			LastName:  gocloak.StringP("fernandez"),
+			Email:     gocloak.StringP("diego@gmail.com"),
+			Username:  gocloak.StringP("diegof"),
+			Attributes: &map[string][]string{
+				"locale": {"es"},
+			},
+		}
+	}
+	currentETag := toUserResponse(current(), loadAttributeSchema()).ETag()
+
+	tests := []struct {
+		expErr        error
+		mocks         userMocks
+		updateRequest request.UpdateUserRequest
+		name          string
+		ifMatch       string
+		outPut        response.UserResponse
+		partial       bool
+	}{
+		{
+			name: "replace without required fields",
+			updateRequest: request.UpdateUserRequest{
+				Name: gocloak.StringP("diego"),
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "name, last_name and email are required to replace a user"),
+		},
+		{
+			name:    "missing If-Match",
+			partial: true,
+			mocks: userMocks{
+				userService: func(f *mockUserService) {},
+			},
+			expErr: apperror.New(http.StatusPreconditionRequired, "If-Match is required to update user diegof, send the ETag it was read with"),
+		},
+		{
+			name:    "error GetUserByNickName",
+			partial: true,
+			ifMatch: "*",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name:    "stale etag",
+			partial: true,
+			ifMatch: `"stale"`,
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(current(), nil)
+				},
+			},
+			expErr: apperror.New(http.StatusPreconditionFailed, "user diegof was modified, fetch it again before updating"),
+		},
+		{
+			name:    "error UpdateUser",
+			partial: true,
+			ifMatch: "*",
+			updateRequest: request.UpdateUserRequest{
+				LastName: gocloak.StringP("gomez"),
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(current(), nil)
+					f.keycloakClient.Mock.On("UpdateUser", mock.Anything, mock.Anything).Return(errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name:    "patch",
+			partial: true,
+			ifMatch: currentETag,
+			updateRequest: request.UpdateUserRequest{
+				LastName: gocloak.StringP("gomez"),
+				Attributes: &map[string]string{
+					"department": "sales",
+				},
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					updated := current()
+					updated.LastName = gocloak.StringP("gomez")
+					updated.Attributes = &map[string][]string{
+						"locale":     {"es"},
+						"department": {"sales"},
+					}
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(current(), nil)
+					f.keycloakClient.Mock.On("UpdateUser", mock.Anything, *updated).Return(nil)
+				},
+			},
+			outPut: response.UserResponse{
+				ID:       "abcde8",
+				Name:     "diego",
+				LastName: "gomez",
+				Email:    "diego@gmail.com",
+				NickName: "diegof",
+				Attributes: map[string]string{
+					"locale":     "es",
+					"department": "sales",
+				},
+			},
+		},
+		{
+			name:    "replace keeps attributes outside the allow-list",
+			ifMatch: "*",
+			updateRequest: request.UpdateUserRequest{
+				Name:     gocloak.StringP("diego"),
+				LastName: gocloak.StringP("fernandez"),
+				Email:    gocloak.StringP("diego.f@gmail.com"),
+			},
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					pending := current()
+					(*pending.Attributes)["registration"] = []string{"pending"}
+					updated := current()
+					updated.Email = gocloak.StringP("diego.f@gmail.com")
+					updated.EmailVerified = gocloak.BoolP(false)
+					updated.Attributes = &map[string][]string{"registration": {"pending"}}
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(pending, nil)
+					f.keycloakClient.Mock.On("UpdateUser", mock.Anything, *updated).Return(nil)
+				},
+			},
+			outPut: response.UserResponse{
+				ID:       "abcde8",
+				Name:     "diego",
+				LastName: "fernandez",
+				Email:    "diego.f@gmail.com",
+				NickName: "diegof",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockUserService{
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
+			}
+			tt.mocks.userService(m)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			user, err := service.Update(context.Background(), "diegof", tt.updateRequest, tt.partial, tt.ifMatch)
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, user)
+		})
+	}
+}
+
+func Test_SetEnabled(t *testing.T) {
+	current := func() *gocloak.User {
+		return &gocloak.User{
+			ID:        gocloak.StringP("abcde8"),
+			FirstName: gocloak.StringP("diego"),
+			LastName:  gocloak.StringP("fernandez"),
+			Email:     gocloak.StringP("diego@gmail.com"),
+			Username:  gocloak.StringP("diegof"),
+			Enabled:   gocloak.BoolP(true),
+		}
+	}
+
+	tests := []struct {
+		expErr  error
+		mocks   userMocks
+		name    string
+		outPut  response.UserResponse
+		enabled bool
+	}{
+		{
+			name: "error GetUserByNickName",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name:    "enable a user waiting for approval",
+			enabled: true,
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					pending := current()
+					pending.Enabled = gocloak.BoolP(false)
+					pending.Attributes = &map[string][]string{"registration": {"pending"}}
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(pending, nil)
+				},
+			},
+			expErr: apperror.New(http.StatusConflict, "user diegof is waiting for approval, approve it with POST /users/registrations/diegof/approve"),
+		},
+		{
+			name: "error UpdateUser",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(current(), nil)
+					f.keycloakClient.Mock.On("UpdateUser", mock.Anything, mock.Anything).Return(errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "error LogoutUserSessions",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(current(), nil)
+					f.keycloakClient.Mock.On("UpdateUser", mock.Anything, mock.Anything).Return(nil)
+					f.keycloakClient.Mock.On("LogoutUserSessions", mock.Anything, "abcde8").Return(errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "disable",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					disabled := current()
+					disabled.Enabled = gocloak.BoolP(false)
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(current(), nil)
+					f.keycloakClient.Mock.On("UpdateUser", mock.Anything, *disabled).Return(nil)
+					f.keycloakClient.Mock.On("LogoutUserSessions", mock.Anything, "abcde8").Return(nil)
+				},
+			},
+			outPut: response.UserResponse{
+				ID:       "abcde8",
+				Name:     "diego",
+				LastName: "fernandez",
+				Email:    "diego@gmail.com",
+				NickName: "diegof",
+			},
+		},
+		{
+			name:    "enable",
+			enabled: true,
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(current(), nil)
+					f.keycloakClient.Mock.On("UpdateUser", mock.Anything, *current()).Return(nil)
+				},
+			},
+			outPut: response.UserResponse{
+				ID:       "abcde8",
+				Name:     "diego",
+				LastName: "fernandez",
+				Email:    "diego@gmail.com",
+				NickName: "diegof",
+				Enabled:  true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockUserService{
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
+			}
+			tt.mocks.userService(m)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			user, err := service.SetEnabled(context.Background(), "diegof", tt.enabled)
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, user)
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_ResetPassword(t *testing.T) {
+	passwordRequest := request.PasswordRequest{Password: "new-password", Temporary: true}
+	tests := []struct {
+		expErr error
+		mocks  userMocks
+		name   string
+	}{
+		{
+			name: "user not found",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(nil, apperror.New(http.StatusNotFound, "user with nick name diegof doesn't exist"))
+				},
+			},
+			expErr: apperror.New(http.StatusNotFound, "user with nick name diegof doesn't exist"),
+		},
+		{
+			name: "password rejected",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(&gocloak.User{ID: gocloak.StringP("1")}, nil)
+					f.keycloakClient.Mock.On("SetPassword", mock.Anything, "1", "new-password", true).Return(apperror.New(http.StatusBadRequest, "password rejected: invalidPasswordMinDigitsMessage"))
+				},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "password rejected: invalidPasswordMinDigitsMessage"),
+		},
+		{
+			name: "full flow",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(&gocloak.User{ID: gocloak.StringP("1")}, nil)
+					f.keycloakClient.Mock.On("SetPassword", mock.Anything, "1", "new-password", true).Return(nil)
+					f.keycloakClient.Mock.On("LogoutUserSessions", mock.Anything, "1").Return(nil)
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockUserService{
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
+			}
+			tt.mocks.userService(m)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			err := service.ResetPassword(context.Background(), "diegof", passwordRequest)
+			assert.Equal(t, tt.expErr, err)
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_GetAttribute(t *testing.T) {
+	current := &gocloak.User{
+		ID:         gocloak.StringP("abcde8"),
+		Username:   gocloak.StringP("diegof"),
+		Attributes: &map[string][]string{"locale": {"es"}},
+	}
+
+	tests := []struct {
+		expErr error
+		mocks  userMocks
+		name   string
+		key    string
+		outPut response.AttributeResponse
+	}{
+		{
+			name: "key not allowed",
+			key:  "password",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {},
+			},
+			expErr: apperror.New(http.StatusBadRequest, "attribute password isn't allowed"),
+		},
+		{
+			name: "error GetUserByNickName",
+			key:  "locale",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name: "attribute not set",
+			key:  "department",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(current, nil)
+				},
+			},
+			expErr: apperror.New(http.StatusNotFound, "user diegof has no attribute department"),
+		},
+		{
+			name: "full flow",
+			key:  "locale",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(current, nil)
+				},
+			},
+			outPut: response.AttributeResponse{Key: "locale", Value: "es"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockUserService{
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
+			}
+			tt.mocks.userService(m)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			attribute, err := service.GetAttribute(context.Background(), "diegof", tt.key)
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, attribute)
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_SetAttribute(t *testing.T) {
+	current := func() *gocloak.User {
+		return &gocloak.User{
+			ID:         gocloak.StringP("abcde8"),
+			Username:   gocloak.StringP("diegof"),
+			Attributes: &map[string][]string{"locale": {"es"}},
+		}
+	}
+
+	tests := []struct {
+		expErr error
+		mocks  userMocks
+		name   string
+		key    string
+		value  string
+		outPut response.AttributeResponse
+	}{
+		{
+			name:  "invalid value",
+			key:   "phone",
+			value: "12345",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {},
+			},
+			expErr: apperror.New(http.StatusUnprocessableEntity, "attribute phone must be a phone"),
+		},
+		{
+			name:  "error UpdateUser",
+			key:   "department",
+			value: "sales",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(current(), nil)
+					f.keycloakClient.Mock.On("UpdateUser", mock.Anything, mock.Anything).Return(errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name:  "full flow",
+			key:   "department",
+			value: "sales",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					updated := current()
+					updated.Attributes = &map[string][]string{"locale": {"es"}, "department": {"sales"}}
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(current(), nil)
+					f.keycloakClient.Mock.On("UpdateUser", mock.Anything, *updated).Return(nil)
+				},
+			},
+			outPut: response.AttributeResponse{Key: "department", Value: "sales"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockUserService{
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
+			}
+			tt.mocks.userService(m)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			attribute, err := service.SetAttribute(context.Background(), "diegof", tt.key, tt.value)
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, attribute)
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_DeleteAttribute(t *testing.T) {
+	current := func() *gocloak.User {
+		return &gocloak.User{
+			ID:         gocloak.StringP("abcde8"),
+			Username:   gocloak.StringP("diegof"),
+			Attributes: &map[string][]string{"locale": {"es"}, "department": {"sales"}},
+		}
+	}
+
+	tests := []struct {
+		expErr error
+		mocks  userMocks
+		name   string
+		key    string
+	}{
+		{
+			name: "attribute not set",
+			key:  "phone",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(current(), nil)
+				},
+			},
+			expErr: apperror.New(http.StatusNotFound, "user diegof has no attribute phone"),
+		},
+		{
+			name: "full flow",
+			key:  "department",
+			mocks: userMocks{
+				userService: func(f *mockUserService) {
+					updated := current()
+					updated.Attributes = &map[string][]string{"locale": {"es"}}
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diegof").Return(current(), nil)
+					f.keycloakClient.Mock.On("UpdateUser", mock.Anything, *updated).Return(nil)
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockUserService{
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
+			}
+			tt.mocks.userService(m)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			err := service.DeleteAttribute(context.Background(), "diegof", tt.key)
+			assert.Equal(t, tt.expErr, err)
+			m.keycloakClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Delete(t *testing.T) {
+	user := func() *gocloak.User {
+		return &gocloak.User{ID: gocloak.StringP("AXYZT"), Username: gocloak.StringP("diego")}
+	}
+	teams := dto.TeamsByUserResponse{
+		Teams: []dto.TeamResponse{
+			{Code: "xs", Debt: 0},
+			{Code: "xl", Debt: 100},
+		},
+	}
+	roles := []*gocloak.Role{{Name: gocloak.StringP("user")}}
+
+	tests := []struct {
+		expErr   error
+		mocks    userMocks
+		name     string
+		nickName string
+		policy   string
+		outPut   string
+	}{
+		{
+			name:     "error GetUserByNickName",
+			nickName: "diego",
+			mocks: userMocks{
+				func(f *mockUserService) {
+					f.offboardingRepository.Mock.On("Get", "diego").Return(offboardingDto.Offboarding{}, false, nil)
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diego").Return(nil, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name:     "error reading offboarding",
+			nickName: "diego",
+			mocks: userMocks{
+				func(f *mockUserService) {
+					f.offboardingRepository.Mock.On("Get", "diego").Return(offboardingDto.Offboarding{}, false, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name:     "error GetTeamsByUser",
+			nickName: "diego",
+			mocks: userMocks{
+				func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diego").Return(user(), nil)
+					f.offboardingRepository.Mock.On("Get", "diego").Return(offboardingDto.Offboarding{}, false, nil)
+					f.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "AXYZT").Return(dto.TeamsByUserResponse{}, errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name:     "blocked by teams",
+			nickName: "diego",
+			mocks: userMocks{
+				func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diego").Return(user(), nil)
+					f.offboardingRepository.Mock.On("Get", "diego").Return(offboardingDto.Offboarding{}, false, nil)
+					f.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "AXYZT").Return(dto.TeamsByUserResponse{Teams: teams.Teams[:1]}, nil)
+				},
+			},
+			expErr: &apperror.AppError{
+				Code:    http.StatusConflict,
+				Message: "user diego can't be deleted while belonging to the teams xs",
+				Details: response.DeletionBlocked{Teams: []response.BlockingTeam{{Code: "xs", Debt: 0}}},
+			},
+		},
+		{
+			name:     "blocked by debt",
+			nickName: "diego",
+			policy:   "block-debt",
+			mocks: userMocks{
+				func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diego").Return(user(), nil)
+					f.offboardingRepository.Mock.On("Get", "diego").Return(offboardingDto.Offboarding{}, false, nil)
+					f.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "AXYZT").Return(teams, nil)
+				},
+			},
+			expErr: &apperror.AppError{
+				Code:    http.StatusConflict,
+				Message: "user diego can't be deleted while owing debt to the teams xl",
+				Details: response.DeletionBlocked{Teams: []response.BlockingTeam{{Code: "xl", Debt: 100}}},
+			},
+		},
+		{
+			name:     "error saving offboarding",
+			nickName: "diego",
+			mocks: userMocks{
+				func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diego").Return(user(), nil)
+					f.offboardingRepository.Mock.On("Get", "diego").Return(offboardingDto.Offboarding{}, false, nil)
+					f.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "AXYZT").Return(dto.TeamsByUserResponse{}, nil)
+					f.offboardingRepository.Mock.On("Save", mock.Anything).Return(errors.New("some error"))
+				},
+			},
+			expErr: errors.New("some error"),
+		},
+		{
+			name:     "error DeleteUserByID",
+			nickName: "diego",
+			mocks: userMocks{
+				func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diego").Return(user(), nil)
+					f.offboardingRepository.Mock.On("Get", "diego").Return(offboardingDto.Offboarding{}, false, nil)
+					f.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "AXYZT").Return(dto.TeamsByUserResponse{}, nil)
+					f.offboardingRepository.Mock.On("Save", mock.Anything).Return(nil)
+					f.keycloakClient.Mock.On("LogoutUserSessions", mock.Anything, "AXYZT").Return(nil)
+					f.keycloakClient.Mock.On("GetUserRealmRoles", mock.Anything, "AXYZT").Return([]*gocloak.Role{}, nil)
+					f.keycloakClient.Mock.On("DeleteUserByID", mock.Anything, "AXYZT").Return(errors.New("some error"))
+				},
+			},
+			expErr: fmt.Errorf("offboarding of user diego stopped at delete_user: %w", errors.New("some error")),
+		},
+		{
+			name:     "full flow",
+			nickName: "diego",
+			mocks: userMocks{
+				func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diego").Return(user(), nil)
+					f.offboardingRepository.Mock.On("Get", "diego").Return(offboardingDto.Offboarding{}, false, nil)
+					f.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "AXYZT").Return(dto.TeamsByUserResponse{}, nil)
+					f.offboardingRepository.Mock.On("Save", mock.Anything).Return(nil)
+					f.keycloakClient.Mock.On("LogoutUserSessions", mock.Anything, "AXYZT").Return(nil)
+					f.keycloakClient.Mock.On("GetUserRealmRoles", mock.Anything, "AXYZT").Return(roles, nil)
+					f.keycloakClient.Mock.On("DeleteRealmRolesFromUser", mock.Anything, "AXYZT", []gocloak.Role{*roles[0]}).Return(nil)
+					f.keycloakClient.Mock.On("DeleteUserByID", mock.Anything, "AXYZT").Return(nil)
+				},
+			},
+			outPut: "diego",
+		},
+		{
+			name:     "error NotifyUserDeleted",
+			nickName: "diego",
+			policy:   "allow-notify",
+			mocks: userMocks{
+				func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diego").Return(user(), nil)
+					f.offboardingRepository.Mock.On("Get", "diego").Return(offboardingDto.Offboarding{}, false, nil)
+					f.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "AXYZT").Return(teams, nil)
+					f.offboardingRepository.Mock.On("Save", mock.Anything).Return(nil)
+					f.keycloakClient.Mock.On("LogoutUserSessions", mock.Anything, "AXYZT").Return(nil)
+					f.keycloakClient.Mock.On("GetUserRealmRoles", mock.Anything, "AXYZT").Return([]*gocloak.Role{}, nil)
+					f.teamClient.Mock.On("RemoveUserFromTeams", mock.Anything, "AXYZT").Return(nil)
+					f.keycloakClient.Mock.On("DeleteUserByID", mock.Anything, "AXYZT").Return(nil)
+					f.teamClient.Mock.On("NotifyUserDeleted", mock.Anything, mock.Anything).Return(errors.New("some error"))
+				},
+			},
+			expErr: fmt.Errorf("offboarding of user diego stopped at notify_teams: %w", errors.New("some error")),
+		},
+		{
+			name:     "allow and notify",
+			nickName: "diego",
+			policy:   "allow-notify",
+			mocks: userMocks{
+				func(f *mockUserService) {
+					f.keycloakClient.Mock.On("GetUserByNickName", mock.Anything, "diego").Return(user(), nil)
+					f.offboardingRepository.Mock.On("Get", "diego").Return(offboardingDto.Offboarding{}, false, nil)
+					f.teamClient.Mock.On("GetTeamsByUser", mock.Anything, "AXYZT").Return(teams, nil)
+					f.offboardingRepository.Mock.On("Save", mock.Anything).Return(nil)
+					f.keycloakClient.Mock.On("LogoutUserSessions", mock.Anything, "AXYZT").Return(nil)
+					f.keycloakClient.Mock.On("GetUserRealmRoles", mock.Anything, "AXYZT").Return([]*gocloak.Role{}, nil)
+					f.teamClient.Mock.On("RemoveUserFromTeams", mock.Anything, "AXYZT").Return(nil)
+					f.keycloakClient.Mock.On("DeleteUserByID", mock.Anything, "AXYZT").Return(nil)
+					f.teamClient.Mock.On("NotifyUserDeleted", mock.Anything, dto.UserDeletedNotification{
+						UserID:   "AXYZT",
+						NickName: "diego",
+						Teams:    []string{"xs", "xl"},
+					}).Return(nil)
+				},
+			},
+			outPut: "diego",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockUserService{
+				keycloakClient:        &mocks.IKeycloakClient{},
+				teamClient:            &mocks.ITeamClient{},
+				offboardingRepository: &mocks.IOffboardingRepository{},
+			}
+			tt.mocks.userService(m)
+			service := NewUserService(m.keycloakClient, m.teamClient, m.offboardingRepository)
+			if tt.policy != "" {
+				service.(*userService).deletionPolicy = tt.policy
+			}
+			userName, err := service.Delete(context.Background(), tt.nickName)
+			assert.Equal(t, tt.expErr, err)
+			assert.Equal(t, tt.outPut, userName)
+			m.keycloakClient.AssertExpectations(t)
+			m.teamClient.AssertExpectations(t)
 		})
 	}
 }